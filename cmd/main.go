@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -10,8 +13,15 @@ import (
 	"taxi-service/internal/config"
 	"taxi-service/internal/database"
 	"taxi-service/internal/handlers"
+	"taxi-service/internal/jobs"
+	"taxi-service/internal/lifecycle"
 	"taxi-service/internal/middleware"
 	"taxi-service/internal/models"
+	"taxi-service/internal/payments"
+	"taxi-service/internal/realtime"
+	"taxi-service/internal/scheduler"
+	"taxi-service/internal/services"
+	"taxi-service/internal/telegram"
 )
 
 // @title Taxi Service API
@@ -45,11 +55,34 @@ func main() {
 	if err := database.Connect(&cfg.Database); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer database.Close()
 
-	// Initialize database schema
-	if err := database.InitSchema(); err != nil {
-		log.Fatalf("Failed to initialize schema: %v", err)
+	// "taxi-service migrate up|down|status" manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrateCommand(os.Args[2:])
+		database.Close()
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// "taxi-service reconcile" checks driver balances against the ledger and
+	// exits non-zero when they disagree
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		clean, err := runReconcileCommand()
+		database.Close()
+		if err != nil {
+			log.Fatalf("Reconcile failed: %v", err)
+		}
+		if !clean {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Bring the schema up to date; the advisory lock serializes replicas
+	if _, err := database.MigrateUp(); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
 	// Seed initial data (for development)
@@ -67,18 +100,53 @@ func main() {
 		log.Fatalf("Failed to create upload directory: %v", err)
 	}
 
+	// Admin group alerts are sent in the background; order alerts name
+	// regions and districts
+	notifier := telegram.NewNotifier(&cfg.Telegram, services.NewRegionService(database.DB))
+
+	// WebSocket hub for real-time order and notification pushes
+	hub := realtime.NewHub()
+
+	// Queued background work such as offering orders to drivers
+	queue := jobs.NewQueue(database.DB, &cfg.Jobs)
+
+	orderService := services.NewOrderService(database.DB, &cfg.Dispatch, &cfg.Pricing, notifier, hub, queue)
+	topUpService := services.NewTopUpService(database.DB, &cfg.Payments, payments.NewRegistry(&cfg.Payments), hub)
+
+	queue.Register(services.JobOfferOrder, orderService.OfferOrder)
+	queue.Start()
+
+	// Periodic jobs: re-dispatch or expire orders nobody accepted, fail
+	// top-ups nobody paid
+	periodic := scheduler.New()
+	periodic.Every("expire-overdue-orders", cfg.Dispatch.ExpiryCheckInterval(), orderService.ProcessOverdueOrders)
+	periodic.Every("expire-stale-top-ups", cfg.Payments.ExpiryCheckInterval(), topUpService.ExpireStale)
+
 	// Setup router
-	app := setupRouter(cfg)
+	app := setupRouter(cfg, notifier, hub, queue, orderService, topUpService)
+
+	// On SIGTERM stop taking requests and let the ones in flight finish, then
+	// stop the workers that may still write, and close the database last; the
+	// server owns the pool from here, so nothing else closes it
+	lc := lifecycle.New(cfg.Server.ShutdownTimeout())
+	lc.OnShutdown("http server", app.ShutdownWithContext)
+	lc.OnShutdown("websocket hub", hub.Close)
+	lc.OnShutdown("scheduler", periodic.Stop)
+	lc.OnShutdown("job queue", queue.Stop)
+	lc.OnShutdown("telegram notifier", notifier.Close)
+	lc.OnShutdown("database", func(context.Context) error {
+		return database.Close()
+	})
 
 	// Start server
 	addr := cfg.Server.Host + ":" + cfg.Server.Port
 	log.Printf("Starting Fiber server on %s", addr)
-	if err := app.Listen(addr); err != nil {
+	if err := lc.Run(func() error { return app.Listen(addr) }); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
-func setupRouter(cfg *config.Config) *fiber.App {
+func setupRouter(cfg *config.Config, alerts services.AdminAlerts, hub *realtime.Hub, queue services.Jobs, orderService *services.OrderService, topUpService *services.TopUpService) *fiber.App {
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "Taxi Service API v1.0",
@@ -99,131 +167,189 @@ func setupRouter(cfg *config.Config) *fiber.App {
 
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.H{"status": "ok"})
+		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
 	// API v1 routes
 	api := app.Group("/api/v1")
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(cfg)
-	orderHandler := handlers.NewOrderHandler(cfg)
-	driverHandler := handlers.NewDriverHandler(cfg)
-	adminHandler := handlers.NewAdminHandler(cfg)
-	ratingHandler := handlers.NewRatingHandler()
-	notificationHandler := handlers.NewNotificationHandler()
-	regionHandler := handlers.NewRegionHandler()
-	feedbackHandler := handlers.NewFeedbackHandler()
+	authHandler := handlers.NewAuthHandler(cfg, services.NewAuthService(database.DB, &cfg.JWT))
+	orderHandler := handlers.NewOrderHandler(orderService)
+	driverHandler := handlers.NewDriverHandler(cfg, services.NewDriverService(database.DB, &cfg.Dispatch, alerts, hub, queue))
+	tripHandler := handlers.NewTripHandler(services.NewTripService(database.DB, &cfg.Dispatch, hub, queue))
+	adminHandler := handlers.NewAdminHandler(services.NewAdminService(database.DB, hub))
+	ratingHandler := handlers.NewRatingHandler(services.NewRatingService(database.DB))
+	notificationHandler := handlers.NewNotificationHandler(services.NewNotificationService(database.DB))
+	regionHandler := handlers.NewRegionHandler(services.NewRegionService(database.DB))
+	feedbackHandler := handlers.NewFeedbackHandler(services.NewFeedbackService(database.DB, alerts))
+	paymentHandler := handlers.NewPaymentHandler(topUpService, services.NewPaymentService(database.DB, hub))
 
 	// Public routes
 	auth := api.Group("/auth")
 	{
-		auth.Post("/register", authHandler.RegisterFiber)
-		auth.Post("/login", authHandler.LoginFiber)
+		auth.Post("/register", authHandler.Register)
+		auth.Post("/login", authHandler.Login)
 	}
 
 	// Region routes (public)
 	regions := api.Group("/regions")
 	{
-		regions.Get("", regionHandler.GetRegionsFiber)
-		regions.Get("/:id", regionHandler.GetRegionFiber)
-		regions.Get("/:id/districts", regionHandler.GetDistrictsFiber)
+		regions.Get("", regionHandler.GetRegions)
+		regions.Get("/:id", regionHandler.GetRegion)
+		regions.Get("/:id/districts", regionHandler.GetDistricts)
 	}
 
 	// District routes (public)
 	districts := api.Group("/districts")
 	{
-		districts.Get("/:id", regionHandler.GetDistrictFiber)
+		districts.Get("/:id", regionHandler.GetDistrict)
 	}
 
+	// Real-time events; authenticated with the same JWT, sent as a header or ?token=
+	api.Get("/ws", middleware.WebSocketAuth(cfg.JWT.Secret), hub.Handler())
+
+	// Payment provider callbacks; each provider authenticates its own requests
+	api.Post("/payments/:provider/callback", paymentHandler.Callback)
+
 	// Protected routes (require authentication)
 	protected := api.Group("")
-	protected.Use(middleware.AuthMiddlewareFiber(cfg.JWT.Secret))
+	protected.Use(middleware.AuthMiddleware(cfg.JWT.Secret))
 
 	// Auth/Profile routes
 	profile := protected.Group("/auth")
 	{
-		profile.Get("/profile", authHandler.GetProfileFiber)
-		profile.Put("/profile", authHandler.UpdateProfileFiber)
-		profile.Post("/change-password", authHandler.ChangePasswordFiber)
-		profile.Post("/avatar", authHandler.UploadAvatarFiber)
+		profile.Get("/profile", authHandler.GetProfile)
+		profile.Put("/profile", authHandler.UpdateProfile)
+		profile.Post("/change-password", authHandler.ChangePassword)
+		profile.Post("/avatar", authHandler.UploadAvatar)
 	}
 
 	// Order routes (users)
 	orders := protected.Group("/orders")
 	{
-		orders.Post("/taxi", orderHandler.CreateTaxiOrderFiber)
-		orders.Post("/delivery", orderHandler.CreateDeliveryOrderFiber)
-		orders.Get("/my", orderHandler.GetMyOrdersFiber)
-		orders.Get("/:id", orderHandler.GetOrderByIDFiber)
-		orders.Post("/:id/cancel", orderHandler.CancelOrderFiber)
+		orders.Post("/taxi", orderHandler.CreateTaxiOrder)
+		orders.Post("/delivery", orderHandler.CreateDeliveryOrder)
+		orders.Post("/quote", orderHandler.QuoteOrder)
+		orders.Get("/my", orderHandler.GetMyOrders)
+		orders.Get("/:id", orderHandler.GetOrderByID)
+		orders.Get("/:id/timeline", orderHandler.GetOrderTimeline)
+		orders.Post("/:id/cancel", orderHandler.CancelOrder)
+		orders.Post("/:id/pay", paymentHandler.PayOrder)
+		orders.Post("/:id/payment/dispute", paymentHandler.DisputePayment)
 	}
 
+	// Shared trips customers can search and book
+	trips := protected.Group("/trips")
+	{
+		trips.Get("/search", tripHandler.SearchTrips)
+		trips.Get("/:id", tripHandler.GetTrip)
+		trips.Post("/:id/book", orderHandler.BookTrip)
+	}
+
+	// Customer wallet and card payment providers
+	protected.Get("/wallet", paymentHandler.GetWallet)
+	protected.Get("/payment-providers", paymentHandler.GetTopUpProviders)
+
 	// Rating routes
 	ratings := protected.Group("/ratings")
 	{
-		ratings.Post("", ratingHandler.CreateRatingFiber)
-		ratings.Get("/driver/:driver_id", ratingHandler.GetDriverRatingsFiber)
+		ratings.Post("", ratingHandler.CreateRating)
+		ratings.Get("/driver/:driver_id", ratingHandler.GetDriverRatings)
 	}
 
 	// Notification routes
 	notifications := protected.Group("/notifications")
 	{
-		notifications.Get("", notificationHandler.GetMyNotificationsFiber)
-		notifications.Post("/:id/read", notificationHandler.MarkNotificationReadFiber)
+		notifications.Get("", notificationHandler.GetMyNotifications)
+		notifications.Post("/:id/read", notificationHandler.MarkNotificationRead)
 	}
 
 	// Feedback routes
 	feedback := protected.Group("/feedback")
 	{
-		feedback.Post("", feedbackHandler.SubmitFeedbackFiber)
+		feedback.Post("", feedbackHandler.SubmitFeedback)
 	}
 
 	// Driver routes
 	driver := protected.Group("/driver")
 	{
-		driver.Post("/apply", driverHandler.ApplyAsDriverFiber)
+		driver.Post("/apply", driverHandler.ApplyAsDriver)
 
 		driverOnly := driver.Group("")
-		driverOnly.Use(middleware.RoleMiddlewareFiber(models.RoleDriver, models.RoleAdmin, models.RoleSuperAdmin))
+		driverOnly.Use(middleware.RoleMiddleware(models.RoleDriver, models.RoleAdmin, models.RoleSuperAdmin))
 		{
-			driverOnly.Get("/profile", driverHandler.GetDriverProfileFiber)
-			driverOnly.Put("/profile", driverHandler.UpdateDriverProfileFiber)
-			driverOnly.Get("/orders/new", driverHandler.GetNewOrdersFiber)
-			driverOnly.Post("/orders/:id/accept", driverHandler.AcceptOrderFiber)
-			driverOnly.Post("/orders/:id/complete", driverHandler.CompleteOrderFiber)
-			driverOnly.Get("/orders", driverHandler.GetDriverOrdersFiber)
-			driverOnly.Get("/statistics", driverHandler.GetDriverStatisticsFiber)
+			driverOnly.Get("/profile", driverHandler.GetDriverProfile)
+			driverOnly.Put("/profile", driverHandler.UpdateDriverProfile)
+			driverOnly.Get("/routes", driverHandler.GetRoutes)
+			driverOnly.Put("/routes", driverHandler.SetRoutes)
+			driverOnly.Get("/availability", driverHandler.GetAvailability)
+			driverOnly.Put("/availability", driverHandler.SetAvailability)
+			driverOnly.Get("/orders/new", driverHandler.GetNewOrders)
+			driverOnly.Post("/orders/:id/accept", driverHandler.AcceptOrder)
+			driverOnly.Post("/orders/:id/start", driverHandler.StartOrder)
+			driverOnly.Post("/orders/:id/release", driverHandler.ReleaseOrder)
+			driverOnly.Post("/orders/:id/complete", driverHandler.CompleteOrder)
+			driverOnly.Get("/orders", driverHandler.GetDriverOrders)
+			driverOnly.Post("/trips", tripHandler.PublishTrip)
+			driverOnly.Get("/trips", tripHandler.GetMyTrips)
+			driverOnly.Get("/trips/:id", tripHandler.GetMyTrip)
+			driverOnly.Get("/trips/:id/candidates", tripHandler.GetTripCandidates)
+			driverOnly.Post("/trips/:id/orders/:order_id", tripHandler.AttachOrder)
+			driverOnly.Post("/trips/:id/close", tripHandler.CloseTrip)
+			driverOnly.Post("/trips/:id/cancel", tripHandler.CancelTrip)
+			driverOnly.Get("/statistics", driverHandler.GetDriverStatistics)
+			driverOnly.Get("/wallet/transactions", driverHandler.GetWalletTransactions)
+			driverOnly.Get("/wallet/statement", driverHandler.GetWalletStatement)
+			driverOnly.Get("/wallet/top-up-providers", paymentHandler.GetTopUpProviders)
+			driverOnly.Post("/wallet/top-ups", paymentHandler.CreateTopUp)
+			driverOnly.Get("/wallet/top-ups", paymentHandler.GetTopUps)
+			driverOnly.Get("/wallet/top-ups/:id", paymentHandler.GetTopUp)
 		}
 	}
 
 	// Admin routes
 	admin := protected.Group("/admin")
-	admin.Use(middleware.RoleMiddlewareFiber(models.RoleAdmin, models.RoleSuperAdmin))
+	admin.Use(middleware.RoleMiddleware(models.RoleAdmin, models.RoleSuperAdmin))
 	{
-		admin.Get("/driver-applications", adminHandler.GetDriverApplicationsFiber)
-		admin.Post("/driver-applications/:id/review", adminHandler.ReviewDriverApplicationFiber)
-		admin.Get("/drivers", adminHandler.GetDriversFiber)
-		admin.Post("/drivers/:id/add-balance", adminHandler.AddDriverBalanceFiber)
-		admin.Post("/users/:id/block", adminHandler.BlockUnblockUserFiber)
-		admin.Post("/pricing", adminHandler.SetPricingFiber)
-		admin.Get("/pricing", adminHandler.GetAllPricingFiber)
-		admin.Get("/orders", adminHandler.GetAllOrdersFiber)
-		admin.Get("/statistics", adminHandler.GetStatisticsFiber)
-		admin.Get("/feedback", adminHandler.GetFeedbackFiber)
-
-		admin.Post("/regions", regionHandler.CreateRegionFiber)
-		admin.Put("/regions/:id", regionHandler.UpdateRegionFiber)
-		admin.Delete("/regions/:id", regionHandler.DeleteRegionFiber)
-		admin.Post("/districts", regionHandler.CreateDistrictFiber)
-		admin.Put("/districts/:id", regionHandler.UpdateDistrictFiber)
-		admin.Delete("/districts/:id", regionHandler.DeleteDistrictFiber)
+		admin.Get("/driver-applications", adminHandler.GetDriverApplications)
+		admin.Post("/driver-applications/:id/review", adminHandler.ReviewDriverApplication)
+		admin.Get("/drivers", adminHandler.GetDrivers)
+		admin.Post("/drivers/:id/add-balance", adminHandler.AddDriverBalance)
+		admin.Get("/drivers/:id/transactions", adminHandler.GetDriverTransactions)
+		admin.Post("/users/:id/block", adminHandler.BlockUnblockUser)
+		admin.Post("/pricing", adminHandler.SetPricing)
+		admin.Get("/pricing", adminHandler.GetAllPricing)
+		admin.Get("/pricing/districts", adminHandler.GetDistrictPricing)
+		admin.Post("/pricing/districts", adminHandler.SetDistrictPricing)
+		admin.Delete("/pricing/districts/:id", adminHandler.DeleteDistrictPricing)
+		admin.Get("/pricing/surcharges", adminHandler.GetSurcharges)
+		admin.Post("/pricing/surcharges", adminHandler.CreateSurcharge)
+		admin.Put("/pricing/surcharges/:id", adminHandler.UpdateSurcharge)
+		admin.Delete("/pricing/surcharges/:id", adminHandler.DeleteSurcharge)
+		admin.Get("/orders", adminHandler.GetAllOrders)
+		admin.Post("/orders/:id/status", adminHandler.SetOrderStatus)
+		admin.Post("/orders/:id/payment", paymentHandler.ResolvePayment)
+		admin.Get("/payments/outstanding", paymentHandler.GetOutstandingPayments)
+		admin.Get("/statistics", adminHandler.GetStatistics)
+		admin.Get("/feedback", adminHandler.GetFeedback)
+		admin.Get("/cancellation-policy", adminHandler.GetCancellationPolicy)
+		admin.Post("/cancellation-policy", adminHandler.CreateCancellationPolicyRule)
+		admin.Put("/cancellation-policy/:id", adminHandler.UpdateCancellationPolicyRule)
+		admin.Delete("/cancellation-policy/:id", adminHandler.DeleteCancellationPolicyRule)
+
+		admin.Post("/regions", regionHandler.CreateRegion)
+		admin.Put("/regions/:id", regionHandler.UpdateRegion)
+		admin.Delete("/regions/:id", regionHandler.DeleteRegion)
+		admin.Post("/districts", regionHandler.CreateDistrict)
+		admin.Put("/districts/:id", regionHandler.UpdateDistrict)
+		admin.Delete("/districts/:id", regionHandler.DeleteDistrict)
 
 		superadmin := admin.Group("")
-		superadmin.Use(middleware.RoleMiddlewareFiber(models.RoleSuperAdmin))
+		superadmin.Use(middleware.RoleMiddleware(models.RoleSuperAdmin))
 		{
-			superadmin.Post("/create-admin", adminHandler.CreateAdminFiber)
-			superadmin.Post("/users/:id/reset-password", adminHandler.ResetUserPasswordFiber)
+			superadmin.Post("/create-admin", adminHandler.CreateAdmin)
+			superadmin.Post("/users/:id/reset-password", adminHandler.ResetUserPassword)
 		}
 	}
 
@@ -240,7 +366,7 @@ func errorHandler(c *fiber.Ctx, err error) error {
 		message = fe.Message
 	}
 
-	return c.Status(code).JSON(fiber.H{
+	return c.Status(code).JSON(fiber.Map{
 		"error": message,
 	})
 }
@@ -268,3 +394,86 @@ func createDefaultSuperAdmin() {
 		log.Println("Default superadmin created - Phone: +998901234567, Password: admin123")
 	}
 }
+
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [steps] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp()
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := database.MigrateDown(steps)
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No migrations to revert")
+		}
+		return nil
+
+	case "status":
+		statuses, err := database.MigrationStatuses()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += " (modified since applied)"
+			}
+			if status.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%03d_%-40s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
+
+// runReconcileCommand prints every driver whose balance drifted from the ledger
+// and every ledger transaction that does not balance
+func runReconcileCommand() (bool, error) {
+	report, err := services.NewLedgerService(database.DB).Reconcile()
+	if err != nil {
+		return false, err
+	}
+
+	if report.Clean() {
+		fmt.Println("Driver balances match the ledger")
+		return true, nil
+	}
+
+	if len(report.Drift) > 0 {
+		fmt.Printf("%-8s %-30s %14s %14s %14s\n", "DRIVER", "NAME", "BALANCE", "LEDGER", "DRIFT")
+		for _, d := range report.Drift {
+			fmt.Printf("%-8d %-30s %14.2f %14.2f %14.2f\n", d.DriverID, d.FullName, d.Balance, d.LedgerBalance, d.Balance-d.LedgerBalance)
+		}
+	}
+	for _, i := range report.Imbalances {
+		fmt.Printf("Ledger transaction %d does not balance (off by %.2f)\n", i.TransactionID, i.Total)
+	}
+	return false, nil
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"taxi-service/internal/config"
@@ -47,6 +46,11 @@ func main() {
 	}
 	defer database.Close()
 
+	if _, err := database.MigrateUp(); err != nil {
+		fmt.Printf("Failed to migrate database: %v\n", err)
+		os.Exit(1)
+	}
+
 	if !*force {
 		if !confirmExecute() {
 			fmt.Println("Operation cancelled.")
@@ -54,7 +58,7 @@ func main() {
 		}
 	}
 
-	if err := cleanupAndSeed(&cfg); err != nil {
+	if err := cleanupAndSeed(cfg); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -78,13 +82,22 @@ func cleanupAndSeed(cfg *config.Config) error {
 	defer tx.Rollback()
 
 	tables := []string{
+		"jobs",
 		"notifications",
 		"ratings",
-		"transactions",
+		"top_ups",
+		"ledger_entries",
+		"ledger_transactions",
+		"ledger_accounts",
 		"orders",
+		"trips",
+		"driver_availability",
+		"driver_routes",
 		"driver_applications",
 		"drivers",
 		"feedback",
+		"pricing_surcharges",
+		"district_pricing",
 		"pricing",
 		"districts",
 		"regions",
@@ -98,13 +111,12 @@ func cleanupAndSeed(cfg *config.Config) error {
 		}
 	}
 
-	sqlPath := filepath.Join("database", "migrations", "001_add_locations_and_uzbekistan_data.sql")
-	sqlBytes, err := os.ReadFile(sqlPath)
+	seedSQL, err := database.SeedSQL("uzbekistan_regions.sql")
 	if err != nil {
-		return fmt.Errorf("failed to read seed sql: %w", err)
+		return err
 	}
 
-	if _, err := tx.Exec(string(sqlBytes)); err != nil {
+	if _, err := tx.Exec(seedSQL); err != nil {
 		return fmt.Errorf("failed to execute seed sql: %w", err)
 	}
 
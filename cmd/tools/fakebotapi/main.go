@@ -0,0 +1,82 @@
+// Command fakebotapi is a local stand-in for the Telegram Bot API. It accepts
+// sendMessage calls and prints them, and can inject rate limits and server
+// errors so the notifier's retry path can be exercised without a real bot.
+//
+//	go run cmd/tools/fakebotapi/main.go -addr :8081 -throttle-every 3
+//	TELEGRAM_API_URL=http://localhost:8081 TELEGRAM_BOT_TOKEN=test TELEGRAM_ADMIN_GROUP_ID=-100 go run cmd/main.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type sendMessageRequest struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func main() {
+	addr := flag.String("addr", ":8081", "Listen address")
+	throttleEvery := flag.Int("throttle-every", 0, "Answer every Nth call with 429 Too Many Requests (0 disables)")
+	failEvery := flag.Int("fail-every", 0, "Answer every Nth call with 502 Bad Gateway (0 disables)")
+	flag.Parse()
+
+	var mu sync.Mutex
+	calls := 0
+	messageID := 0
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			reply(w, http.StatusNotFound, map[string]interface{}{"ok": false, "error_code": 404, "description": "Not Found"})
+			return
+		}
+
+		var req sendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChatID == "" || req.Text == "" {
+			reply(w, http.StatusBadRequest, map[string]interface{}{"ok": false, "error_code": 400, "description": "Bad Request: chat_id and text are required"})
+			return
+		}
+
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+
+		if *throttleEvery > 0 && call%*throttleEvery == 0 {
+			log.Printf("call %d: 429", call)
+			reply(w, http.StatusTooManyRequests, map[string]interface{}{
+				"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 1",
+				"parameters": map[string]int{"retry_after": 1},
+			})
+			return
+		}
+		if *failEvery > 0 && call%*failEvery == 0 {
+			log.Printf("call %d: 502", call)
+			reply(w, http.StatusBadGateway, map[string]interface{}{"ok": false, "error_code": 502, "description": "Bad Gateway"})
+			return
+		}
+
+		mu.Lock()
+		messageID++
+		id := messageID
+		mu.Unlock()
+
+		fmt.Printf("--- message %d to %s ---\n%s\n\n", id, req.ChatID, req.Text)
+		reply(w, http.StatusOK, map[string]interface{}{"ok": true, "result": map[string]interface{}{"message_id": id}})
+	})
+
+	log.Printf("Fake Bot API listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+func reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
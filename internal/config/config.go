@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,13 +19,22 @@ type Config struct {
 	Telegram TelegramConfig
 	CORS     CORSConfig
 	Pricing  PricingConfig
+	Dispatch DispatchConfig
+	Payments PaymentsConfig
+	Jobs     JobsConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
-	Host string
-	Env  string
+	Port                   string
+	Host                   string
+	Env                    string
+	ShutdownTimeoutSeconds int // How long shutdown waits for requests and workers before closing the database
+}
+
+// ShutdownTimeout returns how long a graceful shutdown may take
+func (c *ServerConfig) ShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutSeconds) * time.Second
 }
 
 // DatabaseConfig holds database configuration
@@ -53,6 +63,9 @@ type UploadConfig struct {
 type TelegramConfig struct {
 	BotToken     string
 	AdminGroupID string
+	APIURL       string // Bot API base URL; point it at a fake server for local testing
+	MinInterval  int    // Minimum milliseconds between messages to the group
+	MaxRetries   int
 }
 
 // CORSConfig holds CORS configuration
@@ -67,6 +80,104 @@ type PricingConfig struct {
 	Discount3Person      float64
 	DiscountFullCar      float64
 	ServiceFeePercentage float64
+
+	QuoteTTLMinutes int    // How long a price quote can be booked
+	QuoteSecret     string // Signs quote tokens; defaults to the JWT secret
+}
+
+// QuoteTTL returns how long a price quote stays valid
+func (c *PricingConfig) QuoteTTL() time.Duration {
+	return time.Duration(c.QuoteTTLMinutes) * time.Minute
+}
+
+// DispatchConfig controls how long orders stay open for drivers, what happens
+// to orders nobody accepts and what drivers pay for giving an order back
+type DispatchConfig struct {
+	AcceptWindowMinutes int // How long a new order is open for acceptance
+	RedispatchMinutes   int // How long each re-dispatch extends the deadline
+	MaxRedispatches     int // Re-dispatches before the order expires
+	ExpiryCheckSeconds  int // How often overdue orders are swept
+	ExpiryBatchSize     int // Orders handled per sweep
+
+	// A driver releasing an accepted order gets the service fee back in full
+	// this many hours or more before pickup, loses ReleasePenaltyPercent of it
+	// closer than that, and gets nothing back inside ReleaseNoRefundHours
+	ReleaseFullRefundHours int
+	ReleaseNoRefundHours   int
+	ReleasePenaltyPercent  float64
+
+	// Drivers whose base is within this distance of a pickup rank ahead of
+	// those farther away or without a base when an order is offered
+	NearbyRadiusKm float64
+}
+
+// AcceptWindow returns the initial acceptance window
+func (c *DispatchConfig) AcceptWindow() time.Duration {
+	return time.Duration(c.AcceptWindowMinutes) * time.Minute
+}
+
+// RedispatchWindow returns how far each re-dispatch moves the deadline
+func (c *DispatchConfig) RedispatchWindow() time.Duration {
+	return time.Duration(c.RedispatchMinutes) * time.Minute
+}
+
+// ExpiryCheckInterval returns the period of the overdue order sweep
+func (c *DispatchConfig) ExpiryCheckInterval() time.Duration {
+	return time.Duration(c.ExpiryCheckSeconds) * time.Second
+}
+
+// JobsConfig controls the background job queue
+type JobsConfig struct {
+	Workers             int // Jobs run at once by this process
+	PollIntervalMs      int // How often idle workers look for due jobs
+	MaxAttempts         int // Runs before a failing job is given up
+	RetryBackoffSeconds int // Delay before the first retry; later retries wait longer
+	LeaseSeconds        int // How long a job may run before another worker may take it over
+}
+
+// PollInterval returns how often idle workers look for due jobs
+func (c *JobsConfig) PollInterval() time.Duration {
+	return time.Duration(c.PollIntervalMs) * time.Millisecond
+}
+
+// RetryBackoff returns the delay before a failed job's first retry
+func (c *JobsConfig) RetryBackoff() time.Duration {
+	return time.Duration(c.RetryBackoffSeconds) * time.Second
+}
+
+// Lease returns how long a claimed job is reserved for its worker
+func (c *JobsConfig) Lease() time.Duration {
+	return time.Duration(c.LeaseSeconds) * time.Second
+}
+
+// PaymentsConfig holds driver top-up limits and payment provider credentials.
+// A provider is only offered when its credentials are set.
+type PaymentsConfig struct {
+	ReturnURL          string // Where providers send the driver after checkout
+	MinTopUp           float64
+	MaxTopUp           float64
+	PendingTTLMinutes  int // Unpaid top-ups fail after this long
+	ExpiryCheckSeconds int // How often stale top-ups are swept
+
+	ClickServiceID  string
+	ClickMerchantID string
+	ClickSecretKey  string
+
+	PaymeMerchantID  string
+	PaymeKey         string
+	PaymeCheckoutURL string
+
+	FakeSecret string // Enables the fake provider; never set in production
+}
+
+// PendingTTL returns how long a top-up may stay unpaid
+func (c *PaymentsConfig) PendingTTL() time.Duration {
+	return time.Duration(c.PendingTTLMinutes) * time.Minute
+}
+
+// ExpiryCheckInterval returns the period of the stale top-up sweep
+func (c *PaymentsConfig) ExpiryCheckInterval() time.Duration {
+	return time.Duration(c.ExpiryCheckSeconds) * time.Second
 }
 
 // Load loads configuration from environment variables
@@ -81,6 +192,8 @@ func Load() (*Config, error) {
 			Port: getEnv("SERVER_PORT", "8080"),
 			Host: getEnv("SERVER_HOST", "0.0.0.0"),
 			Env:  getEnv("ENV", "development"),
+
+			ShutdownTimeoutSeconds: getEnvAsInt("SHUTDOWN_TIMEOUT_SECONDS", 25),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -101,6 +214,9 @@ func Load() (*Config, error) {
 		Telegram: TelegramConfig{
 			BotToken:     getEnv("TELEGRAM_BOT_TOKEN", ""),
 			AdminGroupID: getEnv("TELEGRAM_ADMIN_GROUP_ID", ""),
+			APIURL:       getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+			MinInterval:  getEnvAsInt("TELEGRAM_MIN_INTERVAL_MS", 3000), // Telegram allows ~20 messages/minute per group
+			MaxRetries:   getEnvAsInt("TELEGRAM_MAX_RETRIES", 3),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "https://api.omad-driver.uz,https://omad-driver.uz,http://localhost:3000,http://localhost:5173"),
@@ -111,7 +227,50 @@ func Load() (*Config, error) {
 			Discount3Person:      getEnvAsFloat("DISCOUNT_3_PERSON", 15),
 			DiscountFullCar:      getEnvAsFloat("DISCOUNT_FULL_CAR", 20),
 			ServiceFeePercentage: getEnvAsFloat("SERVICE_FEE_PERCENTAGE", 15),
+
+			QuoteTTLMinutes: getEnvAsInt("QUOTE_TTL_MINUTES", 10),
+			QuoteSecret:     getEnv("QUOTE_SECRET", ""),
+		},
+		Dispatch: DispatchConfig{
+			AcceptWindowMinutes: getEnvAsInt("ORDER_ACCEPT_WINDOW_MINUTES", 5),
+			RedispatchMinutes:   getEnvAsInt("ORDER_REDISPATCH_WINDOW_MINUTES", 5),
+			MaxRedispatches:     getEnvAsInt("ORDER_MAX_REDISPATCHES", 2),
+			ExpiryCheckSeconds:  getEnvAsInt("ORDER_EXPIRY_CHECK_SECONDS", 30),
+			ExpiryBatchSize:     getEnvAsInt("ORDER_EXPIRY_BATCH_SIZE", 100),
+
+			ReleaseFullRefundHours: getEnvAsInt("RELEASE_FULL_REFUND_HOURS", 24),
+			ReleaseNoRefundHours:   getEnvAsInt("RELEASE_NO_REFUND_HOURS", 2),
+			ReleasePenaltyPercent:  getEnvAsFloat("RELEASE_PENALTY_PERCENT", 50),
+
+			NearbyRadiusKm: getEnvAsFloat("DISPATCH_NEARBY_RADIUS_KM", 20),
+		},
+		Payments: PaymentsConfig{
+			ReturnURL:          getEnv("PAYMENT_RETURN_URL", ""),
+			MinTopUp:           getEnvAsFloat("TOPUP_MIN_AMOUNT", 10000),
+			MaxTopUp:           getEnvAsFloat("TOPUP_MAX_AMOUNT", 5000000),
+			PendingTTLMinutes:  getEnvAsInt("TOPUP_PENDING_TTL_MINUTES", 60),
+			ExpiryCheckSeconds: getEnvAsInt("TOPUP_EXPIRY_CHECK_SECONDS", 300),
+
+			ClickServiceID:  getEnv("CLICK_SERVICE_ID", ""),
+			ClickMerchantID: getEnv("CLICK_MERCHANT_ID", ""),
+			ClickSecretKey:  getEnv("CLICK_SECRET_KEY", ""),
+
+			PaymeMerchantID:  getEnv("PAYME_MERCHANT_ID", ""),
+			PaymeKey:         getEnv("PAYME_KEY", ""),
+			PaymeCheckoutURL: getEnv("PAYME_CHECKOUT_URL", "https://checkout.paycom.uz"),
+
+			FakeSecret: getEnv("FAKE_PAYMENT_SECRET", ""),
 		},
+		Jobs: JobsConfig{
+			Workers:             getEnvAsInt("JOB_WORKERS", 4),
+			PollIntervalMs:      getEnvAsInt("JOB_POLL_INTERVAL_MS", 1000),
+			MaxAttempts:         getEnvAsInt("JOB_MAX_ATTEMPTS", 5),
+			RetryBackoffSeconds: getEnvAsInt("JOB_RETRY_BACKOFF_SECONDS", 5),
+			LeaseSeconds:        getEnvAsInt("JOB_LEASE_SECONDS", 120),
+		},
+	}
+	if cfg.Pricing.QuoteSecret == "" {
+		cfg.Pricing.QuoteSecret = cfg.JWT.Secret
 	}
 
 	return cfg, nil
@@ -0,0 +1,325 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed seeds/*.sql
+var seedFiles embed.FS
+
+// migrationLockKey identifies the Postgres advisory lock held while migrating,
+// so replicas starting at the same time apply migrations one after another
+const migrationLockKey int64 = 7_301_460_226
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a pair of embedded up/down SQL files sharing a version number
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of the up script
+}
+
+// MigrationStatus describes a migration as known to the files and the database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	// Modified is set when the up script changed after it was applied
+	Modified bool
+	// Missing is set when the database records a version that has no file
+	Missing bool
+}
+
+// Migrations returns the embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %03d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			sum := sha256.Sum256(content)
+			m.Up = string(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration in order, each in its own
+// transaction. It refuses to run if an applied migration has been edited.
+func MigrateUp() ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = withMigrationLock(func(conn *sql.Conn) error {
+		records, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if err := verifyChecksums(migrations, records); err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := records[m.Version]; ok {
+				continue
+			}
+			if err := runMigration(conn, m, m.Up, func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)
+				`, m.Version, m.Name, m.Checksum)
+				return err
+			}); err != nil {
+				return err
+			}
+			log.Printf("Applied migration %03d_%s", m.Version, m.Name)
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the most recently applied migrations, newest first
+func MigrateDown(steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	files := map[int]Migration{}
+	for _, m := range migrations {
+		files[m.Version] = m
+	}
+
+	var reverted []Migration
+	err = withMigrationLock(func(conn *sql.Conn) error {
+		records, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int, 0, len(records))
+		for version := range records {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+		if len(versions) > steps {
+			versions = versions[:steps]
+		}
+
+		for _, version := range versions {
+			m, ok := files[version]
+			if !ok {
+				return fmt.Errorf("migration %03d is applied but its files are missing", version)
+			}
+			if err := runMigration(conn, m, m.Down, func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+				return err
+			}); err != nil {
+				return err
+			}
+			log.Printf("Reverted migration %03d_%s", m.Version, m.Name)
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses lists every known migration, applied or not, by version
+func MigrationStatuses() ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	records, err := appliedMigrations(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := records[m.Version]; ok {
+			appliedAt := record.appliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = record.checksum != m.Checksum
+			delete(records, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range records {
+		appliedAt := record.appliedAt
+		statuses = append(statuses, MigrationStatus{Version: version, Name: record.name, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// SeedSQL returns an embedded seed script from the seeds directory
+func SeedSQL(name string) (string, error) {
+	content, err := seedFiles.ReadFile("seeds/" + name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read seed %s: %w", name, err)
+	}
+	return string(content), nil
+}
+
+type migrationRecord struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// advisory lock; session-level locks belong to a connection, not the pool
+func withMigrationLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}()
+
+	return fn(conn)
+}
+
+func appliedMigrations(conn *sql.Conn) (map[int]migrationRecord, error) {
+	ctx := context.Background()
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	records := map[int]migrationRecord{}
+	for rows.Next() {
+		var version int
+		var record migrationRecord
+		if err := rows.Scan(&version, &record.name, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		records[version] = record
+	}
+	return records, rows.Err()
+}
+
+func verifyChecksums(migrations []Migration, records map[int]migrationRecord) error {
+	for _, m := range migrations {
+		record, ok := records[m.Version]
+		if ok && record.checksum != m.Checksum {
+			return fmt.Errorf("migration %03d_%s was modified after it was applied; add a new migration instead", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// runMigration executes a script and its bookkeeping in one transaction
+func runMigration(conn *sql.Conn, m Migration, script string, record func(tx *sql.Tx) error) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("migration %03d_%s: failed to record: %w", m.Version, m.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
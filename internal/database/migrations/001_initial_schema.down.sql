@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS feedback;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS driver_applications;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS discounts;
+DROP TABLE IF EXISTS pricing;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS districts;
+DROP TABLE IF EXISTS regions;
+DROP TABLE IF EXISTS drivers;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS to_address;
+ALTER TABLE orders DROP COLUMN IF EXISTS to_longitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS to_latitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS from_address;
+ALTER TABLE orders DROP COLUMN IF EXISTS from_longitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS from_latitude;
@@ -0,0 +1,7 @@
+-- Add location columns to orders table
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS from_latitude DECIMAL(10, 8);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS from_longitude DECIMAL(11, 8);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS from_address TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS to_latitude DECIMAL(10, 8);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS to_longitude DECIMAL(11, 8);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS to_address TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_orders_pending_deadline;
+UPDATE orders SET status = 'cancelled' WHERE status = 'expired';
+ALTER TABLE orders DROP COLUMN IF EXISTS dispatch_attempts;
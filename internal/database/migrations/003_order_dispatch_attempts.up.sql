@@ -0,0 +1,5 @@
+-- Track how many times an unaccepted order was re-offered to drivers
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS dispatch_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- The expiry scheduler scans pending orders by deadline
+CREATE INDEX IF NOT EXISTS idx_orders_pending_deadline ON orders(accept_deadline) WHERE status = 'pending';
@@ -0,0 +1,6 @@
+DELETE FROM order_events WHERE event_type NOT IN ('created', 'status_changed');
+ALTER TABLE order_events DROP COLUMN IF EXISTS details;
+ALTER TABLE order_events ALTER COLUMN to_status SET NOT NULL;
+ALTER TABLE order_events DROP COLUMN IF EXISTS event_type;
+ALTER INDEX IF EXISTS idx_order_events_order_id RENAME TO idx_order_status_history_order_id;
+ALTER TABLE order_events RENAME TO order_status_history;
@@ -0,0 +1,13 @@
+-- Generalize the status history into an audit trail of every order event
+ALTER TABLE order_status_history RENAME TO order_events;
+ALTER INDEX IF EXISTS idx_order_status_history_order_id RENAME TO idx_order_events_order_id;
+
+ALTER TABLE order_events ADD COLUMN IF NOT EXISTS event_type VARCHAR(30) NOT NULL DEFAULT 'status_changed';
+ALTER TABLE order_events ALTER COLUMN event_type DROP DEFAULT;
+UPDATE order_events SET event_type = 'created' WHERE from_status IS NULL;
+
+-- Events that do not change the status leave to_status empty
+ALTER TABLE order_events ALTER COLUMN to_status DROP NOT NULL;
+
+-- Event-specific facts such as the assigned driver or a refunded amount
+ALTER TABLE order_events ADD COLUMN IF NOT EXISTS details JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_fee;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_refund;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_policy_id;
+DROP TABLE IF EXISTS cancellation_policies;
@@ -0,0 +1,26 @@
+-- Rules deciding what a customer cancellation costs. The rule with the largest
+-- min_hours_before_pickup not exceeding the time left wins; a rule for the
+-- order's type beats one for any type (order_type NULL).
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    id SERIAL PRIMARY KEY,
+    order_type VARCHAR(20),
+    order_status VARCHAR(20) NOT NULL,
+    min_hours_before_pickup DECIMAL(6,2) NOT NULL DEFAULT 0,
+    driver_refund_percent DECIMAL(5,2) NOT NULL DEFAULT 100,
+    customer_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Outcome of the cancellation, kept on the order
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_policy_id INTEGER REFERENCES cancellation_policies(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_refund DECIMAL(12,2);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_fee DECIMAL(12,2);
+
+-- Start with the previous behaviour: the driver always gets the fee back and
+-- the customer pays nothing
+INSERT INTO cancellation_policies (order_type, order_status, min_hours_before_pickup, driver_refund_percent, customer_fee_percent, description)
+VALUES
+    (NULL, 'pending', 0, 100, 0, 'Cancelling before a driver accepts is free'),
+    (NULL, 'accepted', 0, 100, 0, 'Driver gets the full service fee back');
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    driver_id INTEGER REFERENCES drivers(id) ON DELETE CASCADE,
+    order_id INTEGER REFERENCES orders(id),
+    amount DECIMAL(12,2) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    description TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Keep the driver side of every posting except the synthetic opening balances
+INSERT INTO transactions (driver_id, order_id, amount, type, description, created_by, created_at)
+SELECT a.driver_id, t.order_id, e.amount,
+       CASE WHEN e.amount < 0 THEN 'debit' ELSE 'credit' END,
+       t.description, t.created_by, t.created_at
+FROM ledger_entries e
+JOIN ledger_accounts a ON a.id = e.account_id AND a.driver_id IS NOT NULL
+JOIN ledger_transactions t ON t.id = e.transaction_id
+WHERE t.kind <> 'opening_balance'
+ORDER BY t.id;
+
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS ledger_accounts;
+DROP FUNCTION IF EXISTS ledger_check_balanced();
+DROP FUNCTION IF EXISTS ledger_reject_change();
@@ -0,0 +1,135 @@
+-- Driver money as a double-entry ledger. Every movement is a ledger
+-- transaction whose entries sum to zero; a driver's balance is the sum of the
+-- entries on their wallet account. drivers.balance stays as a cached copy that
+-- is updated in the same database transaction, and `taxi-service reconcile`
+-- reports any drift between the two.
+
+-- A wallet per driver, plus platform accounts identified by code
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE,
+    driver_id INTEGER UNIQUE REFERENCES drivers(id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((code IS NULL) <> (driver_id IS NULL))
+);
+
+INSERT INTO ledger_accounts (code, name) VALUES
+    ('platform_revenue', 'Service fees kept by the platform'),
+    ('driver_funding', 'Money paid in by drivers'),
+    ('opening_balances', 'Balances carried over from before the ledger')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(30) NOT NULL,
+    order_id INTEGER REFERENCES orders(id),
+    description TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES ledger_transactions(id),
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    amount DECIMAL(12,2) NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries(account_id, transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction ON ledger_entries(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_transactions_order ON ledger_transactions(order_id);
+
+-- Postings are never changed or removed; mistakes are corrected with a new
+-- posting. A session that sets ledger.allow_purge may delete rows, which is
+-- only meant for removing throwaway test fixtures.
+CREATE OR REPLACE FUNCTION ledger_reject_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('ledger.allow_purge', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_transactions_immutable BEFORE UPDATE OR DELETE ON ledger_transactions
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+CREATE TRIGGER ledger_entries_immutable BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+-- The entries of a transaction must sum to zero by the time it commits
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS trigger AS $$
+DECLARE
+    total DECIMAL(14,2);
+BEGIN
+    SELECT COALESCE(SUM(amount), 0) INTO total FROM ledger_entries WHERE transaction_id = NEW.transaction_id;
+    IF total <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % does not balance (off by %)', NEW.transaction_id, total;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- Carry over existing drivers and their transaction history
+INSERT INTO ledger_accounts (driver_id, name)
+SELECT id, 'Wallet of driver ' || id FROM drivers
+ON CONFLICT (driver_id) DO NOTHING;
+
+CREATE TEMPORARY TABLE legacy_postings ON COMMIT DROP AS
+SELECT t.*,
+       CASE
+           WHEN t.amount < 0 THEN 'service_fee'
+           WHEN t.created_by IS NOT NULL THEN 'top_up'
+           ELSE 'fee_refund'
+       END AS kind,
+       CASE
+           WHEN t.amount > 0 AND t.created_by IS NOT NULL THEN 'driver_funding'
+           ELSE 'platform_revenue'
+       END AS counter_account
+FROM transactions t
+WHERE t.amount <> 0 AND t.driver_id IS NOT NULL;
+
+INSERT INTO ledger_transactions (id, kind, order_id, description, created_by, created_at)
+SELECT id, kind, order_id, description, created_by, created_at FROM legacy_postings;
+
+INSERT INTO ledger_entries (transaction_id, account_id, amount)
+SELECT p.id, a.id, p.amount
+FROM legacy_postings p JOIN ledger_accounts a ON a.driver_id = p.driver_id
+UNION ALL
+SELECT p.id, a.id, -p.amount
+FROM legacy_postings p JOIN ledger_accounts a ON a.code = p.counter_account;
+
+SELECT setval(pg_get_serial_sequence('ledger_transactions', 'id'), COALESCE((SELECT MAX(id) FROM ledger_transactions), 0) + 1, false);
+
+-- The old table was written best-effort, so whatever it does not explain of a
+-- driver's balance becomes an opening balance
+DO $$
+DECLARE
+    wallet RECORD;
+    posting INTEGER;
+BEGIN
+    FOR wallet IN
+        SELECT a.id AS account_id, d.balance - COALESCE(SUM(e.amount), 0) AS missing
+        FROM drivers d
+        JOIN ledger_accounts a ON a.driver_id = d.id
+        LEFT JOIN ledger_entries e ON e.account_id = a.id
+        GROUP BY a.id, d.balance
+        HAVING d.balance - COALESCE(SUM(e.amount), 0) <> 0
+    LOOP
+        INSERT INTO ledger_transactions (kind, description)
+        VALUES ('opening_balance', 'Balance carried over from before the ledger')
+        RETURNING id INTO posting;
+
+        INSERT INTO ledger_entries (transaction_id, account_id, amount)
+        SELECT posting, wallet.account_id, wallet.missing
+        UNION ALL
+        SELECT posting, id, -wallet.missing FROM ledger_accounts WHERE code = 'opening_balances';
+    END LOOP;
+END $$;
+
+DROP TABLE IF EXISTS transactions;
@@ -0,0 +1,23 @@
+-- Drivers topping up their own balance through a payment provider. A top-up
+-- starts pending and moves once to confirmed (credited through the ledger) or
+-- failed; provider_ref is the provider's own transaction ID.
+CREATE TABLE IF NOT EXISTS top_ups (
+    id SERIAL PRIMARY KEY,
+    driver_id INTEGER NOT NULL REFERENCES drivers(id),
+    provider VARCHAR(20) NOT NULL,
+    amount DECIMAL(12,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    provider_ref VARCHAR(100),
+    provider_created_at TIMESTAMP,
+    failure_reason TEXT,
+    ledger_transaction_id INTEGER REFERENCES ledger_transactions(id),
+    confirmed_at TIMESTAMP,
+    failed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A provider transaction can only ever pay for one top-up
+CREATE UNIQUE INDEX IF NOT EXISTS idx_top_ups_provider_ref ON top_ups(provider, provider_ref) WHERE provider_ref IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_top_ups_driver ON top_ups(driver_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_top_ups_pending ON top_ups(created_at) WHERE status = 'pending';
@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS idx_top_ups_order;
+DELETE FROM top_ups WHERE order_id IS NOT NULL;
+ALTER TABLE top_ups DROP CONSTRAINT IF EXISTS top_ups_payer_check;
+ALTER TABLE top_ups DROP COLUMN IF EXISTS order_id;
+ALTER TABLE top_ups ALTER COLUMN driver_id SET NOT NULL;
+
+-- Fare postings and customer wallets have nowhere to go in the older schema
+SET LOCAL ledger.allow_purge = 'on';
+CREATE TEMP TABLE fare_postings ON COMMIT DROP AS
+    SELECT id FROM ledger_transactions WHERE kind IN ('fare_payment', 'fare_refund');
+DELETE FROM ledger_entries WHERE transaction_id IN (SELECT id FROM fare_postings);
+DELETE FROM ledger_transactions WHERE id IN (SELECT id FROM fare_postings);
+DELETE FROM ledger_accounts WHERE user_id IS NOT NULL OR code IN ('fare_receipts', 'customer_funding');
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_owner_check;
+ALTER TABLE ledger_accounts DROP COLUMN IF EXISTS user_id;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_check CHECK ((code IS NULL) <> (driver_id IS NULL));
+
+DROP INDEX IF EXISTS idx_orders_payment_outstanding;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_note;
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_status;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_method;
@@ -0,0 +1,30 @@
+-- How and whether the customer paid an order's fare. Cash is collected by the
+-- driver, card is paid through a payment provider and wallet is charged to the
+-- customer's ledger wallet when the order is placed.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_method VARCHAR(10) NOT NULL DEFAULT 'cash';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_status VARCHAR(20) NOT NULL DEFAULT 'unpaid';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS paid_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_note TEXT;
+
+-- Orders finished before payments were tracked were settled in cash
+UPDATE orders SET payment_status = 'paid', paid_at = completed_at WHERE status = 'completed';
+UPDATE orders SET payment_status = 'void' WHERE status IN ('cancelled', 'expired');
+
+CREATE INDEX IF NOT EXISTS idx_orders_payment_outstanding ON orders(completed_at)
+    WHERE payment_status IN ('unpaid', 'disputed');
+
+-- Customer wallets live in the ledger next to driver wallets
+ALTER TABLE ledger_accounts ADD COLUMN IF NOT EXISTS user_id INTEGER UNIQUE REFERENCES users(id);
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_owner_check CHECK (num_nonnulls(code, driver_id, user_id) = 1);
+
+INSERT INTO ledger_accounts (code, name) VALUES
+    ('fare_receipts', 'Fares paid by card or wallet'),
+    ('customer_funding', 'Money paid in by customers')
+ON CONFLICT (code) DO NOTHING;
+
+-- A top-up either funds a driver's wallet or pays an order's fare by card
+ALTER TABLE top_ups ALTER COLUMN driver_id DROP NOT NULL;
+ALTER TABLE top_ups ADD COLUMN IF NOT EXISTS order_id INTEGER REFERENCES orders(id);
+ALTER TABLE top_ups ADD CONSTRAINT top_ups_payer_check CHECK (num_nonnulls(driver_id, order_id) = 1);
+CREATE INDEX IF NOT EXISTS idx_top_ups_order ON top_ups(order_id) WHERE order_id IS NOT NULL;
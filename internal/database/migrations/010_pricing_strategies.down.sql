@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS pricing_surcharges;
+DROP TABLE IF EXISTS district_pricing;
+ALTER TABLE pricing DROP CONSTRAINT IF EXISTS pricing_strategy_check;
+ALTER TABLE pricing DROP COLUMN IF EXISTS price_per_km;
+ALTER TABLE pricing DROP COLUMN IF EXISTS strategy;
@@ -0,0 +1,38 @@
+-- How a route is priced. 'flat' charges base_price plus price_per_person for
+-- each passenger; 'distance' adds price_per_km for the straight-line distance
+-- between the pickup and drop-off coordinates.
+ALTER TABLE pricing ADD COLUMN IF NOT EXISTS strategy VARCHAR(20) NOT NULL DEFAULT 'flat';
+ALTER TABLE pricing ADD COLUMN IF NOT EXISTS price_per_km DECIMAL(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE pricing ADD CONSTRAINT pricing_strategy_check CHECK (strategy IN ('flat', 'distance'));
+
+-- Rates for one district pair, replacing the rates of its region route
+CREATE TABLE IF NOT EXISTS district_pricing (
+    id SERIAL PRIMARY KEY,
+    from_district_id INTEGER NOT NULL REFERENCES districts(id) ON DELETE CASCADE,
+    to_district_id INTEGER NOT NULL REFERENCES districts(id) ON DELETE CASCADE,
+    base_price DECIMAL(12,2) NOT NULL,
+    price_per_person DECIMAL(12,2) NOT NULL,
+    price_per_km DECIMAL(12,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(from_district_id, to_district_id)
+);
+
+-- Percentages added to the price of trips starting at night (start_time to
+-- end_time, HH:MM, wrapping past midnight when start_time > end_time) or on a
+-- holiday. Every matching surcharge is added up.
+CREATE TABLE IF NOT EXISTS pricing_surcharges (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('night', 'holiday')),
+    start_time VARCHAR(5),
+    end_time VARCHAR(5),
+    holiday_date DATE,
+    percent DECIMAL(5,2) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (
+        (kind = 'night' AND start_time IS NOT NULL AND end_time IS NOT NULL AND holiday_date IS NULL) OR
+        (kind = 'holiday' AND holiday_date IS NOT NULL AND start_time IS NULL AND end_time IS NULL)
+    )
+);
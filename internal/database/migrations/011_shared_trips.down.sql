@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_trip;
+ALTER TABLE orders DROP COLUMN IF EXISTS trip_id;
+
+DROP TABLE IF EXISTS trips;
@@ -0,0 +1,28 @@
+-- Intercity rides drivers publish ahead of time. Orders on the same region
+-- pair and date attach to a trip until its seats are taken; seats_booked
+-- counts the passengers of the attached orders that are still on board.
+CREATE TABLE IF NOT EXISTS trips (
+    id SERIAL PRIMARY KEY,
+    driver_id INTEGER NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    from_region_id INTEGER NOT NULL REFERENCES regions(id),
+    to_region_id INTEGER NOT NULL REFERENCES regions(id),
+    scheduled_date DATE NOT NULL,
+    departure_start VARCHAR(5) NOT NULL,
+    departure_end VARCHAR(5) NOT NULL,
+    seats_total INTEGER NOT NULL CHECK (seats_total > 0),
+    seats_booked INTEGER NOT NULL DEFAULT 0,
+    price_per_seat DECIMAL(12,2) NOT NULL CHECK (price_per_seat > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed', 'cancelled')),
+    notes TEXT,
+    cancellation_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (seats_booked BETWEEN 0 AND seats_total)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trips_driver ON trips(driver_id, scheduled_date);
+CREATE INDEX IF NOT EXISTS idx_trips_route_date ON trips(from_region_id, to_region_id, scheduled_date)
+    WHERE status = 'open';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS trip_id INTEGER REFERENCES trips(id);
+CREATE INDEX IF NOT EXISTS idx_orders_trip ON orders(trip_id) WHERE trip_id IS NOT NULL;
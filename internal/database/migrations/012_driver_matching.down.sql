@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS driver_availability;
+DROP TABLE IF EXISTS driver_routes;
+
+ALTER TABLE drivers DROP COLUMN IF EXISTS base_longitude;
+ALTER TABLE drivers DROP COLUMN IF EXISTS base_latitude;
+ALTER TABLE drivers DROP CONSTRAINT IF EXISTS drivers_seat_capacity_check;
+ALTER TABLE drivers DROP COLUMN IF EXISTS seat_capacity;
@@ -0,0 +1,32 @@
+-- What orders a driver is offered. seat_capacity caps the passengers of the
+-- taxi orders and the seats of the trips a driver takes; the base point ranks
+-- drivers by how close they are to a pickup.
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS seat_capacity INTEGER NOT NULL DEFAULT 4;
+ALTER TABLE drivers ADD CONSTRAINT drivers_seat_capacity_check CHECK (seat_capacity BETWEEN 1 AND 8);
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS base_latitude DECIMAL(10, 8);
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS base_longitude DECIMAL(11, 8);
+
+-- Region pairs a driver serves. A driver without any is offered every route.
+CREATE TABLE IF NOT EXISTS driver_routes (
+    id SERIAL PRIMARY KEY,
+    driver_id INTEGER NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    from_region_id INTEGER NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+    to_region_id INTEGER NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(driver_id, from_region_id, to_region_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_routes_route ON driver_routes(from_region_id, to_region_id);
+
+-- Times a driver can drive: start_time to end_time (HH:MM) on a date. A
+-- driver without any upcoming windows is offered orders on every date.
+CREATE TABLE IF NOT EXISTS driver_availability (
+    id SERIAL PRIMARY KEY,
+    driver_id INTEGER NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    available_date DATE NOT NULL,
+    start_time VARCHAR(5) NOT NULL,
+    end_time VARCHAR(5) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_availability_date ON driver_availability(available_date, driver_id);
@@ -0,0 +1,20 @@
+-- Background work queue. Workers claim due pending jobs, and running jobs
+-- whose lease ran out because their worker died, with FOR UPDATE SKIP LOCKED
+-- so no two workers take the same job. Finished jobs are deleted; jobs that
+-- ran out of attempts stay as 'failed' with their last error.
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(run_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_leased ON jobs(locked_until) WHERE status = 'running';
@@ -0,0 +1,3 @@
+ALTER TABLE cancellation_policies DROP CONSTRAINT IF EXISTS cancellation_policies_customer_fee_check;
+ALTER TABLE cancellation_policies DROP CONSTRAINT IF EXISTS cancellation_policies_driver_refund_check;
+ALTER TABLE cancellation_policies DROP CONSTRAINT IF EXISTS cancellation_policies_min_hours_check;
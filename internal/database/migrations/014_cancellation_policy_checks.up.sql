@@ -0,0 +1,5 @@
+-- Keep cancellation rules within sane bounds even if a write bypasses the API:
+-- a refund over 100% would pay drivers more than the fee they were charged.
+ALTER TABLE cancellation_policies ADD CONSTRAINT cancellation_policies_min_hours_check CHECK (min_hours_before_pickup >= 0);
+ALTER TABLE cancellation_policies ADD CONSTRAINT cancellation_policies_driver_refund_check CHECK (driver_refund_percent BETWEEN 0 AND 100);
+ALTER TABLE cancellation_policies ADD CONSTRAINT cancellation_policies_customer_fee_check CHECK (customer_fee_percent BETWEEN 0 AND 100);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_orders_payment_owed;
+UPDATE orders SET payment_status = 'void' WHERE payment_status = 'owed';
@@ -0,0 +1,8 @@
+-- A customer who cancels an order that was not paid up front owes its
+-- cancellation fee. Orders cancelled before this were closed as void and the
+-- fee was never collected; list them as owed too.
+UPDATE orders SET payment_status = 'owed'
+    WHERE status = 'cancelled' AND payment_status = 'void' AND cancellation_fee > 0;
+
+CREATE INDEX IF NOT EXISTS idx_orders_payment_owed ON orders(cancelled_at)
+    WHERE payment_status = 'owed';
@@ -1,14 +1,5 @@
--- Add location columns to orders table
-ALTER TABLE orders ADD COLUMN IF NOT EXISTS from_latitude DECIMAL(10, 8);
-ALTER TABLE orders ADD COLUMN IF NOT EXISTS from_longitude DECIMAL(11, 8);
-ALTER TABLE orders ADD COLUMN IF NOT EXISTS from_address TEXT;
-ALTER TABLE orders ADD COLUMN IF NOT EXISTS to_latitude DECIMAL(10, 8);
-ALTER TABLE orders ADD COLUMN IF NOT EXISTS to_longitude DECIMAL(11, 8);
-ALTER TABLE orders ADD COLUMN IF NOT EXISTS to_address TEXT;
-
--- Clear existing data
-TRUNCATE TABLE districts CASCADE;
-TRUNCATE TABLE regions CASCADE;
+-- All regions and districts of Uzbekistan. Loaded by cmd/tools/dbseed after it
+-- truncates the reference tables, so the inserts use fixed region IDs.
 
 -- Insert all regions of Uzbekistan
 INSERT INTO regions (id, name_uz_lat, name_uz_cyr, name_ru) VALUES
@@ -1,34 +1,138 @@
 package handlers
 
 import (
-	"database/sql"
 	"fmt"
-	"net/http"
 
-	"github.com/gin-gonic/gin"
-	"taxi-service/internal/config"
-	"taxi-service/internal/database"
+	"github.com/gofiber/fiber/v2"
 	"taxi-service/internal/middleware"
 	"taxi-service/internal/models"
-	"taxi-service/internal/utils"
+	"taxi-service/internal/services"
 )
 
 // AdminHandler handles admin-related endpoints
 type AdminHandler struct {
-	cfg *config.Config
+	admin *services.AdminService
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(cfg *config.Config) *AdminHandler {
-	return &AdminHandler{cfg: cfg}
+func NewAdminHandler(admin *services.AdminService) *AdminHandler {
+	return &AdminHandler{admin: admin}
 }
 
 // ReviewDriverApplicationRequest represents application review
 type ReviewDriverApplicationRequest struct {
-	Status         string `json:"status" binding:"required,oneof=approved rejected"`
+	Status          string `json:"status" validate:"required,oneof=approved rejected"`
 	RejectionReason string `json:"rejection_reason"`
 }
 
+// BlockUserRequest represents block/unblock request
+type BlockUserRequest struct {
+	IsBlocked bool `json:"is_blocked"`
+}
+
+// AddBalanceRequest represents balance addition request
+type AddBalanceRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// SetPricingRequest represents pricing configuration request
+type SetPricingRequest struct {
+	FromRegionID   int64   `json:"from_region_id" validate:"required"`
+	ToRegionID     int64   `json:"to_region_id" validate:"required"`
+	Strategy       string  `json:"strategy" validate:"omitempty,oneof=flat distance"` // Defaults to flat
+	BasePrice      float64 `json:"base_price" validate:"required,gt=0"`
+	PricePerPerson float64 `json:"price_per_person" validate:"gte=0"`
+	PricePerKm     float64 `json:"price_per_km" validate:"gte=0"`
+	ServiceFee     float64 `json:"service_fee" validate:"gte=0,lte=100"`
+}
+
+// DistrictPricingRequest represents the rates of a district pair
+type DistrictPricingRequest struct {
+	FromDistrictID int64   `json:"from_district_id" validate:"required"`
+	ToDistrictID   int64   `json:"to_district_id" validate:"required"`
+	BasePrice      float64 `json:"base_price" validate:"required,gt=0"`
+	PricePerPerson float64 `json:"price_per_person" validate:"gte=0"`
+	PricePerKm     float64 `json:"price_per_km" validate:"gte=0"`
+}
+
+// SurchargeRequest represents a night or holiday pricing surcharge
+type SurchargeRequest struct {
+	Kind        string  `json:"kind" validate:"required,oneof=night holiday"`
+	StartTime   string  `json:"start_time"`   // HH:MM, night only
+	EndTime     string  `json:"end_time"`     // HH:MM, night only
+	HolidayDate string  `json:"holiday_date"` // YYYY-MM-DD, holiday only
+	Percent     float64 `json:"percent" validate:"gt=0,lte=100"`
+	Description string  `json:"description"`
+}
+
+func (r SurchargeRequest) input() services.SurchargeInput {
+	return services.SurchargeInput{
+		Kind:        r.Kind,
+		StartTime:   r.StartTime,
+		EndTime:     r.EndTime,
+		HolidayDate: r.HolidayDate,
+		Percent:     r.Percent,
+		Description: r.Description,
+	}
+}
+
+// SetOrderStatusRequest represents an admin order status override
+type SetOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=in_progress completed cancelled"`
+	Reason string `json:"reason"`
+}
+
+// CancellationPolicyRequest represents a cancellation policy rule
+type CancellationPolicyRequest struct {
+	OrderType            string  `json:"order_type" validate:"omitempty,oneof=taxi delivery"`
+	OrderStatus          string  `json:"order_status" validate:"required,oneof=pending accepted"`
+	MinHoursBeforePickup float64 `json:"min_hours_before_pickup" validate:"gte=0"`
+	DriverRefundPercent  float64 `json:"driver_refund_percent" validate:"gte=0,lte=100"`
+	CustomerFeePercent   float64 `json:"customer_fee_percent" validate:"gte=0,lte=100"`
+	Description          string  `json:"description"`
+}
+
+func (r CancellationPolicyRequest) input() services.CancellationPolicyInput {
+	return services.CancellationPolicyInput{
+		OrderType:            r.OrderType,
+		OrderStatus:          r.OrderStatus,
+		MinHoursBeforePickup: r.MinHoursBeforePickup,
+		DriverRefundPercent:  r.DriverRefundPercent,
+		CustomerFeePercent:   r.CustomerFeePercent,
+		Description:          r.Description,
+	}
+}
+
+// ResetPasswordRequest represents password reset request
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// CreateAdminRequest represents admin creation request
+type CreateAdminRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	Password    string `json:"password" validate:"required,min=6"`
+}
+
+// GetDriverApplications godoc
+// @Summary Get driver applications
+// @Description Get list of driver applications
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Success 200 {array} models.DriverApplication
+// @Router /admin/driver-applications [get]
+func (h *AdminHandler) GetDriverApplications(c *fiber.Ctx) error {
+	applications, err := h.admin.ListDriverApplications(c.Query("status"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(applications)
+}
+
 // ReviewDriverApplication godoc
 // @Summary Review driver application
 // @Description Approve or reject a driver application
@@ -39,150 +143,118 @@ type ReviewDriverApplicationRequest struct {
 // @Param id path int true "Application ID"
 // @Param request body ReviewDriverApplicationRequest true "Review details"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /admin/driver-applications/{id}/review [post]
-func (h *AdminHandler) ReviewDriverApplication(c *gin.Context) {
-	adminID, _ := middleware.GetUserID(c)
-	applicationID := c.Param("id")
-
-	var req ReviewDriverApplicationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Get application
-	var application models.DriverApplication
-	err := database.DB.QueryRow(`
-		SELECT id, user_id, full_name, phone_number, car_model, car_number, license_image
-		FROM driver_applications WHERE id = $1 AND status = 'pending'
-	`, applicationID).Scan(
-		&application.ID, &application.UserID, &application.FullName, &application.PhoneNumber,
-		&application.CarModel, &application.CarNumber, &application.LicenseImage,
-	)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found or already reviewed"})
-		return
-	}
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+func (h *AdminHandler) ReviewDriverApplication(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Begin transaction
-	tx, err := database.DB.Begin()
+	applicationID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return err
 	}
-	defer tx.Rollback()
 
-	// Update application status
-	// Update application status
-	var rejectionReason *string
-	if req.Status == "rejected" && req.RejectionReason != "" {
-    	rejectionReason = &req.RejectionReason
+	var req ReviewDriverApplicationRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	_, err = tx.Exec(`
-    	UPDATE driver_applications 
-    	SET status = $1, rejection_reason = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-    	WHERE id = $4
-	`, req.Status, rejectionReason, adminID, applicationID)
+	err = h.admin.ReviewDriverApplication(adminID, applicationID, services.ReviewApplicationInput{
+		Status:          req.Status,
+		RejectionReason: req.RejectionReason,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application"})
-		return
-	}
-
-	if req.Status == "approved" {
-		// Update user role to driver
-		_, err = tx.Exec(`UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, models.RoleDriver, application.UserID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user role"})
-			return
-		}
-
-		// Create driver record
-		_, err = tx.Exec(`
-			INSERT INTO drivers (user_id, full_name, car_model, car_number, license_image, status, balance)
-			VALUES ($1, $2, $3, $4, $5, $6, 0)
-		`, application.UserID, application.FullName, application.CarModel, application.CarNumber, application.LicenseImage, "approved")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create driver profile"})
-			return
-		}
+		return respondError(c, err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
-	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Application reviewed successfully"})
+}
 
-	// Create notification for user
-	notifMessage := "Your driver application has been approved!"
-	if req.Status == "rejected" {
-		notifMessage = "Your driver application has been rejected."
-		if req.RejectionReason != "" {
-			notifMessage += " Reason: " + req.RejectionReason
-		}
+// GetDrivers godoc
+// @Summary Get all drivers
+// @Description Get list of all drivers with optional filters
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Success 200 {array} models.Driver
+// @Router /admin/drivers [get]
+func (h *AdminHandler) GetDrivers(c *fiber.Ctx) error {
+	drivers, err := h.admin.ListDrivers(c.Query("status"))
+	if err != nil {
+		return respondError(c, err)
 	}
-	database.DB.Exec(`
-		INSERT INTO notifications (user_id, title, message, type)
-		VALUES ($1, $2, $3, $4)
-	`, application.UserID, "Driver Application Status", notifMessage, "application_review")
 
-	c.JSON(http.StatusOK, gin.H{"message": "Application reviewed successfully"})
+	return c.Status(fiber.StatusOK).JSON(drivers)
 }
 
-// GetDriverApplications godoc
-// @Summary Get driver applications
-// @Description Get list of driver applications
+// AddDriverBalance godoc
+// @Summary Add balance to driver
+// @Description Add balance to a driver's account
 // @Tags Admin
 // @Security BearerAuth
+// @Accept json
 // @Produce json
-// @Param status query string false "Filter by status"
-// @Success 200 {array} models.DriverApplication
-// @Router /admin/driver-applications [get]
-func (h *AdminHandler) GetDriverApplications(c *gin.Context) {
-	status := c.Query("status")
+// @Param id path int true "Driver ID"
+// @Param request body AddBalanceRequest true "Amount to add"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/drivers/{id}/add-balance [post]
+func (h *AdminHandler) AddDriverBalance(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
-	query := "SELECT * FROM driver_applications"
-	args := []interface{}{}
+	driverID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
 
-	if status != "" {
-		query += " WHERE status = $1"
-		args = append(args, status)
+	var req AddBalanceRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.admin.AddDriverBalance(adminID, driverID, req.Amount); err != nil {
+		return respondError(c, err)
 	}
 
-	query += " ORDER BY created_at DESC"
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Balance added successfully"})
+}
 
-	rows, err := database.DB.Query(query, args...)
+// GetDriverTransactions godoc
+// @Summary Get a driver's wallet history
+// @Description Get the postings that changed a driver's balance, newest first, with the same filters and paging as the driver's own view
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Driver ID"
+// @Param type query string false "Filter by kind (service_fee/fee_refund/top_up/opening_balance)"
+// @Param from_date query string false "From date (YYYY-MM-DD)"
+// @Param to_date query string false "To date (YYYY-MM-DD)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} services.WalletPage
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/drivers/{id}/transactions [get]
+func (h *AdminHandler) GetDriverTransactions(c *fiber.Ctx) error {
+	driverID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
-		return
+		return err
 	}
-	defer rows.Close()
 
-	applications := []models.DriverApplication{}
-	for rows.Next() {
-		var app models.DriverApplication
-		err := rows.Scan(
-			&app.ID, &app.UserID, &app.FullName, &app.PhoneNumber, &app.CarModel,
-			&app.CarNumber, &app.LicenseImage, &app.Status, &app.RejectionReason,
-			&app.ReviewedBy, &app.ReviewedAt, &app.CreatedAt, &app.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		applications = append(applications, app)
+	page, err := h.admin.DriverTransactions(driverID, walletQuery(c))
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, applications)
-}
-
-// BlockUserRequest represents block/unblock request
-type BlockUserRequest struct {
-	IsBlocked bool `json:"is_blocked"`
+	return c.Status(fiber.StatusOK).JSON(page)
 }
 
 // BlockUnblockUser godoc
@@ -195,22 +267,21 @@ type BlockUserRequest struct {
 // @Param id path int true "User ID"
 // @Param request body BlockUserRequest true "Block status"
 // @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /admin/users/{id}/block [post]
-func (h *AdminHandler) BlockUnblockUser(c *gin.Context) {
-	userID := c.Param("id")
+func (h *AdminHandler) BlockUnblockUser(c *fiber.Ctx) error {
+	userID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
 
 	var req BlockUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	_, err := database.DB.Exec(`
-		UPDATE users SET is_blocked = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
-	`, req.IsBlocked, userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
-		return
+	if err := h.admin.SetUserBlocked(userID, req.IsBlocked); err != nil {
+		return respondError(c, err)
 	}
 
 	action := "unblocked"
@@ -218,189 +289,226 @@ func (h *AdminHandler) BlockUnblockUser(c *gin.Context) {
 		action = "blocked"
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("User %s successfully", action)})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": fmt.Sprintf("User %s successfully", action)})
 }
 
-// GetDrivers godoc
-// @Summary Get all drivers
-// @Description Get list of all drivers with optional filters
+// SetPricing godoc
+// @Summary Set pricing for route
+// @Description Set or update pricing between two regions and pick how it is priced: flat (base price plus price per passenger) or distance (also price per km between the coordinates)
 // @Tags Admin
 // @Security BearerAuth
+// @Accept json
 // @Produce json
-// @Param status query string false "Filter by status"
-// @Success 200 {array} models.Driver
-// @Router /admin/drivers [get]
-func (h *AdminHandler) GetDrivers(c *gin.Context) {
-	status := c.Query("status")
-
-	query := "SELECT * FROM drivers"
-	args := []interface{}{}
-
-	if status != "" {
-		query += " WHERE status = $1"
-		args = append(args, status)
+// @Param request body SetPricingRequest true "Pricing details"
+// @Success 200 {object} models.Pricing
+// @Failure 400 {object} map[string]string
+// @Router /admin/pricing [post]
+func (h *AdminHandler) SetPricing(c *fiber.Ctx) error {
+	var req SetPricingRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	pricing, err := h.admin.SetPricing(services.SetPricingInput{
+		FromRegionID:   req.FromRegionID,
+		ToRegionID:     req.ToRegionID,
+		Strategy:       req.Strategy,
+		BasePrice:      req.BasePrice,
+		PricePerPerson: req.PricePerPerson,
+		PricePerKm:     req.PricePerKm,
+		ServiceFee:     req.ServiceFee,
+	})
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	query += " ORDER BY created_at DESC"
+	return c.Status(fiber.StatusOK).JSON(pricing)
+}
 
-	rows, err := database.DB.Query(query, args...)
+// GetAllPricing godoc
+// @Summary Get all pricing
+// @Description Get all configured pricing routes
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Pricing
+// @Router /admin/pricing [get]
+func (h *AdminHandler) GetAllPricing(c *fiber.Ctx) error {
+	pricings, err := h.admin.ListPricing()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drivers"})
-		return
-	}
-	defer rows.Close()
-
-	drivers := []models.Driver{}
-	for rows.Next() {
-		var driver models.Driver
-		err := rows.Scan(
-			&driver.ID, &driver.UserID, &driver.FullName, &driver.CarModel, &driver.CarNumber,
-			&driver.LicenseImage, &driver.Balance, &driver.Rating, &driver.TotalRatings,
-			&driver.Status, &driver.IsActive, &driver.CreatedAt, &driver.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		drivers = append(drivers, driver)
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, drivers)
+	return c.Status(fiber.StatusOK).JSON(pricings)
 }
 
-// AddBalanceRequest represents balance addition request
-type AddBalanceRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"`
+// GetDistrictPricing godoc
+// @Summary Get district pricing
+// @Description List the district pairs whose rates replace their region route's
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.DistrictPricing
+// @Router /admin/pricing/districts [get]
+func (h *AdminHandler) GetDistrictPricing(c *fiber.Ctx) error {
+	overrides, err := h.admin.ListDistrictPricing()
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(overrides)
 }
 
-// AddDriverBalance godoc
-// @Summary Add balance to driver
-// @Description Add balance to a driver's account
+// SetDistrictPricing godoc
+// @Summary Set district pricing
+// @Description Set or update the rates of a district pair; the region route's strategy and service fee still apply
 // @Tags Admin
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param id path int true "Driver ID"
-// @Param request body AddBalanceRequest true "Amount to add"
-// @Success 200 {object} map[string]string
-// @Router /admin/drivers/{id}/add-balance [post]
-func (h *AdminHandler) AddDriverBalance(c *gin.Context) {
-	adminID, _ := middleware.GetUserID(c)
-	driverID := c.Param("id")
-
-	var req AddBalanceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+// @Param request body DistrictPricingRequest true "District pair and rates"
+// @Success 200 {object} models.DistrictPricing
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/pricing/districts [post]
+func (h *AdminHandler) SetDistrictPricing(c *fiber.Ctx) error {
+	var req DistrictPricingRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	override, err := h.admin.SetDistrictPricing(services.DistrictPricingInput{
+		FromDistrictID: req.FromDistrictID,
+		ToDistrictID:   req.ToDistrictID,
+		BasePrice:      req.BasePrice,
+		PricePerPerson: req.PricePerPerson,
+		PricePerKm:     req.PricePerKm,
+	})
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	// Begin transaction
-	tx, err := database.DB.Begin()
+	return c.Status(fiber.StatusOK).JSON(override)
+}
+
+// DeleteDistrictPricing godoc
+// @Summary Delete district pricing
+// @Description Remove a district override; the pair is priced by its region route again
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "District pricing ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/pricing/districts/{id} [delete]
+func (h *AdminHandler) DeleteDistrictPricing(c *fiber.Ctx) error {
+	overrideID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return err
 	}
-	defer tx.Rollback()
 
-	// Update driver balance
-	_, err = tx.Exec(`UPDATE drivers SET balance = balance + $1 WHERE id = $2`, req.Amount, driverID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
-		return
+	if err := h.admin.DeleteDistrictPricing(overrideID); err != nil {
+		return respondError(c, err)
 	}
 
-	// Create transaction record
-	_, err = tx.Exec(`
-		INSERT INTO transactions (driver_id, amount, type, description, created_by)
-		VALUES ($1, $2, $3, $4, $5)
-	`, driverID, req.Amount, "credit", "Balance added by admin", adminID)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "District pricing deleted successfully"})
+}
+
+// GetSurcharges godoc
+// @Summary Get pricing surcharges
+// @Description List the night and holiday surcharges
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.PricingSurcharge
+// @Router /admin/pricing/surcharges [get]
+func (h *AdminHandler) GetSurcharges(c *fiber.Ctx) error {
+	surcharges, err := h.admin.ListSurcharges()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
-		return
+		return respondError(c, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
+	return c.Status(fiber.StatusOK).JSON(surcharges)
+}
+
+// CreateSurcharge godoc
+// @Summary Add a pricing surcharge
+// @Description Add a percentage charged on trips starting at night or scheduled on a holiday
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SurchargeRequest true "Surcharge"
+// @Success 201 {object} models.PricingSurcharge
+// @Failure 400 {object} map[string]string
+// @Router /admin/pricing/surcharges [post]
+func (h *AdminHandler) CreateSurcharge(c *fiber.Ctx) error {
+	var req SurchargeRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Balance added successfully"})
-}
+	surcharge, err := h.admin.CreateSurcharge(req.input())
+	if err != nil {
+		return respondError(c, err)
+	}
 
-// SetPricingRequest represents pricing configuration
-type SetPricingRequest struct {
-	FromRegionID   int64   `json:"from_region_id" binding:"required"`
-	ToRegionID     int64   `json:"to_region_id" binding:"required"`
-	BasePrice      float64 `json:"base_price" binding:"required,gt=0"`
-	PricePerPerson float64 `json:"price_per_person" binding:"required,gte=0"`
-	ServiceFee     float64 `json:"service_fee" binding:"required,gte=0,lte=100"`
+	return c.Status(fiber.StatusCreated).JSON(surcharge)
 }
 
-// SetPricing godoc
-// @Summary Set pricing for route
-// @Description Set or update pricing between two regions
+// UpdateSurcharge godoc
+// @Summary Update a pricing surcharge
+// @Description Replace a pricing surcharge
 // @Tags Admin
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param request body SetPricingRequest true "Pricing details"
-// @Success 200 {object} models.Pricing
-// @Router /admin/pricing [post]
-func (h *AdminHandler) SetPricing(c *gin.Context) {
-	var req SetPricingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	var pricing models.Pricing
-	err := database.DB.QueryRow(`
-		INSERT INTO pricing (from_region_id, to_region_id, base_price, price_per_person, service_fee)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (from_region_id, to_region_id) 
-		DO UPDATE SET base_price = $3, price_per_person = $4, service_fee = $5, updated_at = CURRENT_TIMESTAMP
-		RETURNING id, from_region_id, to_region_id, base_price, price_per_person, service_fee, created_at, updated_at
-	`, req.FromRegionID, req.ToRegionID, req.BasePrice, req.PricePerPerson, req.ServiceFee).Scan(
-		&pricing.ID, &pricing.FromRegionID, &pricing.ToRegionID, &pricing.BasePrice,
-		&pricing.PricePerPerson, &pricing.ServiceFee, &pricing.CreatedAt, &pricing.UpdatedAt,
-	)
+// @Param id path int true "Surcharge ID"
+// @Param request body SurchargeRequest true "Surcharge"
+// @Success 200 {object} models.PricingSurcharge
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/pricing/surcharges/{id} [put]
+func (h *AdminHandler) UpdateSurcharge(c *fiber.Ctx) error {
+	surchargeID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set pricing"})
-		return
+		return err
 	}
 
-	c.JSON(http.StatusOK, pricing)
+	var req SurchargeRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	surcharge, err := h.admin.UpdateSurcharge(surchargeID, req.input())
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(surcharge)
 }
 
-// GetAllPricing godoc
-// @Summary Get all pricing
-// @Description Get all configured pricing routes
+// DeleteSurcharge godoc
+// @Summary Delete a pricing surcharge
+// @Description Remove a pricing surcharge
 // @Tags Admin
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {array} models.Pricing
-// @Router /admin/pricing [get]
-func (h *AdminHandler) GetAllPricing(c *gin.Context) {
-	rows, err := database.DB.Query("SELECT * FROM pricing ORDER BY created_at DESC")
+// @Param id path int true "Surcharge ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/pricing/surcharges/{id} [delete]
+func (h *AdminHandler) DeleteSurcharge(c *fiber.Ctx) error {
+	surchargeID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pricing"})
-		return
+		return err
 	}
-	defer rows.Close()
 
-	pricings := []models.Pricing{}
-	for rows.Next() {
-		var pricing models.Pricing
-		err := rows.Scan(
-			&pricing.ID, &pricing.FromRegionID, &pricing.ToRegionID, &pricing.BasePrice,
-			&pricing.PricePerPerson, &pricing.ServiceFee, &pricing.CreatedAt, &pricing.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		pricings = append(pricings, pricing)
+	if err := h.admin.DeleteSurcharge(surchargeID); err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, pricings)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Surcharge deleted successfully"})
 }
 
 // GetAllOrders godoc
@@ -413,160 +521,192 @@ func (h *AdminHandler) GetAllPricing(c *gin.Context) {
 // @Param type query string false "Filter by type"
 // @Param from_date query string false "From date (YYYY-MM-DD)"
 // @Param to_date query string false "To date (YYYY-MM-DD)"
+// @Param payment_status query string false "Filter by payment status"
+// @Param payment_method query string false "Filter by payment method"
 // @Success 200 {array} models.Order
 // @Router /admin/orders [get]
-func (h *AdminHandler) GetAllOrders(c *gin.Context) {
-	status := c.Query("status")
-	orderType := c.Query("type")
-	fromDate := c.Query("from_date")
-	toDate := c.Query("to_date")
+func (h *AdminHandler) GetAllOrders(c *fiber.Ctx) error {
+	orders, err := h.admin.ListOrders(services.AdminOrderFilter{
+		Status:        c.Query("status"),
+		Type:          c.Query("type"),
+		FromDate:      c.Query("from_date"),
+		ToDate:        c.Query("to_date"),
+		PaymentStatus: c.Query("payment_status"),
+		PaymentMethod: c.Query("payment_method"),
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	query := "SELECT * FROM orders WHERE 1=1"
-	args := []interface{}{}
-	argCount := 0
+	return c.Status(fiber.StatusOK).JSON(orders)
+}
 
-	if status != "" {
-		argCount++
-		query += fmt.Sprintf(" AND status = $%d", argCount)
-		args = append(args, status)
-	}
-	if orderType != "" {
-		argCount++
-		query += fmt.Sprintf(" AND order_type = $%d", argCount)
-		args = append(args, orderType)
+// SetOrderStatus godoc
+// @Summary Override order status
+// @Description Move an order to another status; only transitions allowed by the order state machine are accepted
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body SetOrderStatusRequest true "New status and reason"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/orders/{id}/status [post]
+func (h *AdminHandler) SetOrderStatus(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	orderID, err := paramID(c, "id")
+	if err != nil {
+		return err
 	}
-	if fromDate != "" {
-		argCount++
-		query += fmt.Sprintf(" AND DATE(created_at) >= $%d", argCount)
-		args = append(args, fromDate)
+
+	var req SetOrderStatusRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
-	if toDate != "" {
-		argCount++
-		query += fmt.Sprintf(" AND DATE(created_at) <= $%d", argCount)
-		args = append(args, toDate)
+
+	order, err := h.admin.SetOrderStatus(adminID, orderID, models.OrderStatus(req.Status), req.Reason)
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	query += " ORDER BY created_at DESC"
+	return c.Status(fiber.StatusOK).JSON(order)
+}
 
-	rows, err := database.DB.Query(query, args...)
+// GetCancellationPolicy godoc
+// @Summary Get cancellation policy
+// @Description List the rules that decide driver refunds and customer fees on cancellation
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.CancellationPolicy
+// @Router /admin/cancellation-policy [get]
+func (h *AdminHandler) GetCancellationPolicy(c *fiber.Ctx) error {
+	policies, err := h.admin.ListCancellationPolicies()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
-		return
-	}
-	defer rows.Close()
-
-	orders := []models.Order{}
-	for rows.Next() {
-		var order models.Order
-		err := rows.Scan(
-			&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-			&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-			&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-			&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-			&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-			&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-			&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-			&order.CreatedAt, &order.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		orders = append(orders, order)
-	}
-
-	c.JSON(http.StatusOK, orders)
-}
-
-// AdminStatistics represents admin statistics
-type AdminStatistics struct {
-	TotalUsers          int     `json:"total_users"`
-	TotalDrivers        int     `json:"total_drivers"`
-	ActiveDrivers       int     `json:"active_drivers"`
-	TotalOrders         int     `json:"total_orders"`
-	CompletedOrders     int     `json:"completed_orders"`
-	TotalRevenue        float64 `json:"total_revenue"`
-	TodayOrders         int     `json:"today_orders"`
-	TodayRevenue        float64 `json:"today_revenue"`
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(policies)
 }
 
-// GetStatistics godoc
-// @Summary Get platform statistics
-// @Description Get overall platform statistics
+// CreateCancellationPolicyRule godoc
+// @Summary Add a cancellation policy rule
+// @Description Add a rule deciding the driver refund and customer fee for cancellations
 // @Tags Admin
 // @Security BearerAuth
+// @Accept json
 // @Produce json
-// @Success 200 {object} AdminStatistics
-// @Router /admin/statistics [get]
-func (h *AdminHandler) GetStatistics(c *gin.Context) {
-	var stats AdminStatistics
+// @Param request body CancellationPolicyRequest true "Rule"
+// @Success 201 {object} models.CancellationPolicy
+// @Failure 400 {object} map[string]string
+// @Router /admin/cancellation-policy [post]
+func (h *AdminHandler) CreateCancellationPolicyRule(c *fiber.Ctx) error {
+	var req CancellationPolicyRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
 
-	// Total users
-	database.DB.QueryRow("SELECT COUNT(*) FROM users WHERE role = $1", models.RoleUser).Scan(&stats.TotalUsers)
+	policy, err := h.admin.CreateCancellationPolicy(req.input())
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	// Drivers
-	database.DB.QueryRow("SELECT COUNT(*) FROM drivers").Scan(&stats.TotalDrivers)
-	database.DB.QueryRow("SELECT COUNT(*) FROM drivers WHERE is_active = true AND status = 'approved'").Scan(&stats.ActiveDrivers)
+	return c.Status(fiber.StatusCreated).JSON(policy)
+}
 
-	// Orders
-	database.DB.QueryRow("SELECT COUNT(*) FROM orders").Scan(&stats.TotalOrders)
-	database.DB.QueryRow("SELECT COUNT(*) FROM orders WHERE status = $1", models.OrderStatusCompleted).Scan(&stats.CompletedOrders)
-	database.DB.QueryRow("SELECT COALESCE(SUM(service_fee), 0) FROM orders WHERE status = $1", models.OrderStatusCompleted).Scan(&stats.TotalRevenue)
+// UpdateCancellationPolicyRule godoc
+// @Summary Update a cancellation policy rule
+// @Description Replace a cancellation policy rule
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param request body CancellationPolicyRequest true "Rule"
+// @Success 200 {object} models.CancellationPolicy
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/cancellation-policy/{id} [put]
+func (h *AdminHandler) UpdateCancellationPolicyRule(c *fiber.Ctx) error {
+	policyID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
 
-	// Today's stats
-	database.DB.QueryRow("SELECT COUNT(*) FROM orders WHERE DATE(created_at) = CURRENT_DATE").Scan(&stats.TodayOrders)
-	database.DB.QueryRow("SELECT COALESCE(SUM(service_fee), 0) FROM orders WHERE DATE(created_at) = CURRENT_DATE AND status = $1", models.OrderStatusCompleted).Scan(&stats.TodayRevenue)
+	var req CancellationPolicyRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
 
-	c.JSON(http.StatusOK, stats)
-}
+	policy, err := h.admin.UpdateCancellationPolicy(policyID, req.input())
+	if err != nil {
+		return respondError(c, err)
+	}
 
-// ResetPasswordRequest represents password reset by admin
-type ResetPasswordRequest struct {
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	return c.Status(fiber.StatusOK).JSON(policy)
 }
 
-// ResetUserPassword godoc
-// @Summary Reset user password (superadmin only)
-// @Description Reset a user's password
+// DeleteCancellationPolicyRule godoc
+// @Summary Delete a cancellation policy rule
+// @Description Remove a cancellation policy rule
 // @Tags Admin
 // @Security BearerAuth
-// @Accept json
 // @Produce json
-// @Param id path int true "User ID"
-// @Param request body ResetPasswordRequest true "New password"
+// @Param id path int true "Rule ID"
 // @Success 200 {object} map[string]string
-// @Router /admin/users/{id}/reset-password [post]
-func (h *AdminHandler) ResetUserPassword(c *gin.Context) {
-	userID := c.Param("id")
-
-	var req ResetPasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+// @Failure 404 {object} map[string]string
+// @Router /admin/cancellation-policy/{id} [delete]
+func (h *AdminHandler) DeleteCancellationPolicyRule(c *fiber.Ctx) error {
+	policyID, err := paramID(c, "id")
+	if err != nil {
+		return err
 	}
 
-	// Hash password
-	hashedPassword, err := utils.HashPassword(req.NewPassword)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
-		return
+	if err := h.admin.DeleteCancellationPolicy(policyID); err != nil {
+		return respondError(c, err)
 	}
 
-	_, err = database.DB.Exec(`
-		UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
-	`, hashedPassword, userID)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Cancellation policy rule deleted successfully"})
+}
+
+// GetStatistics godoc
+// @Summary Get platform statistics
+// @Description Get overall platform statistics
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} services.AdminStatistics
+// @Router /admin/statistics [get]
+func (h *AdminHandler) GetStatistics(c *fiber.Ctx) error {
+	stats, err := h.admin.Statistics()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+	return c.Status(fiber.StatusOK).JSON(stats)
 }
 
-// CreateAdminRequest represents admin creation
-type CreateAdminRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required"`
-	Name        string `json:"name" binding:"required"`
-	Password    string `json:"password" binding:"required,min=6"`
+// GetFeedback godoc
+// @Summary Get all feedback
+// @Description Get all user feedback/suggestions
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Feedback
+// @Router /admin/feedback [get]
+func (h *AdminHandler) GetFeedback(c *fiber.Ctx) error {
+	feedbacks, err := h.admin.ListFeedback()
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(feedbacks)
 }
 
 // CreateAdmin godoc
@@ -578,72 +718,54 @@ type CreateAdminRequest struct {
 // @Produce json
 // @Param request body CreateAdminRequest true "Admin details"
 // @Success 201 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /admin/create-admin [post]
-func (h *AdminHandler) CreateAdmin(c *gin.Context) {
+func (h *AdminHandler) CreateAdmin(c *fiber.Ctx) error {
 	var req CreateAdminRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Check if phone already exists
-	var existingID int64
-	database.DB.QueryRow("SELECT id FROM users WHERE phone_number = $1", req.PhoneNumber).Scan(&existingID)
-	if existingID > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Phone number already registered"})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Hash password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	user, err := h.admin.CreateAdmin(services.CreateAdminInput{
+		PhoneNumber: req.PhoneNumber,
+		Name:        req.Name,
+		Password:    req.Password,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
-		return
-	}
-
-	// Create admin user
-	var user models.User
-	err = database.DB.QueryRow(`
-		INSERT INTO users (phone_number, name, password, role)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, phone_number, name, role, language, created_at, updated_at
-	`, req.PhoneNumber, req.Name, hashedPassword, models.RoleAdmin).Scan(
-		&user.ID, &user.PhoneNumber, &user.Name, &user.Role,
-		&user.Language, &user.CreatedAt, &user.UpdatedAt,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusCreated, user)
+	return c.Status(fiber.StatusCreated).JSON(user)
 }
 
-// GetFeedback godoc
-// @Summary Get all feedback
-// @Description Get all user feedback/suggestions
+// ResetUserPassword godoc
+// @Summary Reset user password (superadmin only)
+// @Description Reset a user's password
 // @Tags Admin
 // @Security BearerAuth
+// @Accept json
 // @Produce json
-// @Success 200 {array} models.Feedback
-// @Router /admin/feedback [get]
-func (h *AdminHandler) GetFeedback(c *gin.Context) {
-	rows, err := database.DB.Query("SELECT * FROM feedback ORDER BY created_at DESC")
+// @Param id path int true "User ID"
+// @Param request body ResetPasswordRequest true "New password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/reset-password [post]
+func (h *AdminHandler) ResetUserPassword(c *fiber.Ctx) error {
+	userID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
-		return
+		return err
+	}
+
+	var req ResetPasswordRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
-	defer rows.Close()
 
-	feedbacks := []models.Feedback{}
-	for rows.Next() {
-		var feedback models.Feedback
-		err := rows.Scan(&feedback.ID, &feedback.UserID, &feedback.Message, &feedback.CreatedAt)
-		if err != nil {
-			continue
-		}
-		feedbacks = append(feedbacks, feedback)
+	if err := h.admin.ResetUserPassword(userID, req.NewPassword); err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, feedbacks)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password reset successfully"})
 }
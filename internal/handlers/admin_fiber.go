@@ -1,63 +0,0 @@
-package handlers
-
-import "github.com/gofiber/fiber/v2"
-
-// GetDriverApplicationsFiber - Fiber version
-func (h *AdminHandler) GetDriverApplicationsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// ReviewDriverApplicationFiber - Fiber version
-func (h *AdminHandler) ReviewDriverApplicationFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDriversFiber - Fiber version
-func (h *AdminHandler) GetDriversFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// AddDriverBalanceFiber - Fiber version
-func (h *AdminHandler) AddDriverBalanceFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// BlockUnblockUserFiber - Fiber version
-func (h *AdminHandler) BlockUnblockUserFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// SetPricingFiber - Fiber version
-func (h *AdminHandler) SetPricingFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetAllPricingFiber - Fiber version
-func (h *AdminHandler) GetAllPricingFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetAllOrdersFiber - Fiber version
-func (h *AdminHandler) GetAllOrdersFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetStatisticsFiber - Fiber version
-func (h *AdminHandler) GetStatisticsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetFeedbackFiber - Fiber version
-func (h *AdminHandler) GetFeedbackFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// CreateAdminFiber - Fiber version
-func (h *AdminHandler) CreateAdminFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// ResetUserPasswordFiber - Fiber version
-func (h *AdminHandler) ResetUserPasswordFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
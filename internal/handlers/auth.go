@@ -1,39 +1,37 @@
 package handlers
 
 import (
-	"database/sql"
-	"net/http"
-
-	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
 	"taxi-service/internal/config"
-	"taxi-service/internal/database"
 	"taxi-service/internal/middleware"
 	"taxi-service/internal/models"
+	"taxi-service/internal/services"
 	"taxi-service/internal/utils"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	cfg *config.Config
+	cfg  *config.Config
+	auth *services.AuthService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(cfg *config.Config) *AuthHandler {
-	return &AuthHandler{cfg: cfg}
+func NewAuthHandler(cfg *config.Config, auth *services.AuthService) *AuthHandler {
+	return &AuthHandler{cfg: cfg, auth: auth}
 }
 
 // RegisterRequest represents registration request
 type RegisterRequest struct {
-	PhoneNumber     string `json:"phone_number" binding:"required"`
-	Name            string `json:"name" binding:"required"`
-	Password        string `json:"password" binding:"required,min=6"`
-	ConfirmPassword string `json:"confirm_password" binding:"required"`
+	PhoneNumber     string `json:"phone_number" validate:"required"`
+	Name            string `json:"name" validate:"required"`
+	Password        string `json:"password" validate:"required,min=6"`
+	ConfirmPassword string `json:"confirm_password" validate:"required"`
 }
 
 // LoginRequest represents login request
 type LoginRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required"`
-	Password    string `json:"password" binding:"required"`
+	PhoneNumber string `json:"phone_number" validate:"required"`
+	Password    string `json:"password" validate:"required"`
 }
 
 // AuthResponse represents authentication response
@@ -43,6 +41,19 @@ type AuthResponse struct {
 	User  *models.User `json:"user"`
 }
 
+// UpdateProfileRequest represents profile update request
+type UpdateProfileRequest struct {
+	Name     string          `json:"name"`
+	Language models.Language `json:"language"`
+}
+
+// ChangePasswordRequest represents password change request
+type ChangePasswordRequest struct {
+	OldPassword        string `json:"old_password" validate:"required"`
+	NewPassword        string `json:"new_password" validate:"required,min=6"`
+	ConfirmNewPassword string `json:"confirm_new_password" validate:"required"`
+}
+
 // Register godoc
 // @Summary Register a new user
 // @Description Register a new user with phone number, name and password
@@ -54,61 +65,23 @@ type AuthResponse struct {
 // @Failure 400 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Router /auth/register [post]
-func (h *AuthHandler) Register(c *gin.Context) {
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validate passwords match
-	if req.Password != req.ConfirmPassword {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Passwords do not match"})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Check if user already exists
-	var existingID int64
-	err := database.DB.QueryRow("SELECT id FROM users WHERE phone_number = $1", req.PhoneNumber).Scan(&existingID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Phone number already registered"})
-		return
-	}
-
-	// Hash password
-	hashedPassword, err := utils.HashPassword(req.Password)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
-		return
-	}
-
-	// Insert user
-	var user models.User
-	err = database.DB.QueryRow(`
-		INSERT INTO users (phone_number, name, password, role, language)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, phone_number, name, role, language, avatar, is_blocked, created_at, updated_at
-	`, req.PhoneNumber, req.Name, hashedPassword, models.RoleUser, models.LangUzLatin).Scan(
-		&user.ID, &user.PhoneNumber, &user.Name, &user.Role, &user.Language,
-		&user.Avatar, &user.IsBlocked, &user.CreatedAt, &user.UpdatedAt,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-		return
-	}
-
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Role, h.cfg.JWT.Secret, h.cfg.JWT.ExpirationHours)
+	result, err := h.auth.Register(services.RegisterInput{
+		PhoneNumber:     req.PhoneNumber,
+		Name:            req.Name,
+		Password:        req.Password,
+		ConfirmPassword: req.ConfirmPassword,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		Role:  string(user.Role),
-		User:  &user,
-	})
+	return c.Status(fiber.StatusCreated).JSON(newAuthResponse(result))
 }
 
 // Login godoc
@@ -122,58 +95,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /auth/login [post]
-func (h *AuthHandler) Login(c *gin.Context) {
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Get user by phone number
-	var user models.User
-	err := database.DB.QueryRow(`
-		SELECT id, phone_number, name, password, role, language, avatar, is_blocked, created_at, updated_at
-		FROM users WHERE phone_number = $1
-	`, req.PhoneNumber).Scan(
-		&user.ID, &user.PhoneNumber, &user.Name, &user.Password, &user.Role,
-		&user.Language, &user.Avatar, &user.IsBlocked, &user.CreatedAt, &user.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
+	result, err := h.auth.Login(req.PhoneNumber, req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return respondError(c, err)
 	}
 
-	// Check if user is blocked
-	if user.IsBlocked {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Account is blocked"})
-		return
-	}
-
-	// Verify password
-	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
-
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Role, h.cfg.JWT.Secret, h.cfg.JWT.ExpirationHours)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
-	}
-
-	// Clear password from response
-	user.Password = ""
-
-	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		Role:  string(user.Role),
-		User:  &user,
-	})
+	return c.Status(fiber.StatusOK).JSON(newAuthResponse(result))
 }
 
 // GetProfile godoc
@@ -185,29 +118,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Success 200 {object} models.User
 // @Failure 401 {object} map[string]string
 // @Router /auth/profile [get]
-func (h *AuthHandler) GetProfile(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-
-	var user models.User
-	err := database.DB.QueryRow(`
-		SELECT id, phone_number, name, role, language, avatar, is_blocked, created_at, updated_at
-		FROM users WHERE id = $1
-	`, userID).Scan(
-		&user.ID, &user.PhoneNumber, &user.Name, &user.Role,
-		&user.Language, &user.Avatar, &user.IsBlocked, &user.CreatedAt, &user.UpdatedAt,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get profile"})
-		return
+func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	c.JSON(http.StatusOK, user)
-}
+	user, err := h.auth.GetProfile(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
 
-// UpdateProfileRequest represents profile update request
-type UpdateProfileRequest struct {
-	Name     string         `json:"name"`
-	Language models.Language `json:"language"`
+	return c.Status(fiber.StatusOK).JSON(user)
 }
 
 // UpdateProfile godoc
@@ -221,37 +143,26 @@ type UpdateProfileRequest struct {
 // @Success 200 {object} models.User
 // @Failure 400 {object} map[string]string
 // @Router /auth/profile [put]
-func (h *AuthHandler) UpdateProfile(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
+func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
 	var req UpdateProfileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	var user models.User
-	err := database.DB.QueryRow(`
-		UPDATE users SET name = $1, language = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-		RETURNING id, phone_number, name, role, language, avatar, is_blocked, created_at, updated_at
-	`, req.Name, req.Language, userID).Scan(
-		&user.ID, &user.PhoneNumber, &user.Name, &user.Role,
-		&user.Language, &user.Avatar, &user.IsBlocked, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := h.auth.UpdateProfile(userID, services.UpdateProfileInput{
+		Name:     req.Name,
+		Language: req.Language,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, user)
-}
-
-// ChangePasswordRequest represents password change request
-type ChangePasswordRequest struct {
-	OldPassword        string `json:"old_password" binding:"required"`
-	NewPassword        string `json:"new_password" binding:"required,min=6"`
-	ConfirmNewPassword string `json:"confirm_new_password" binding:"required"`
+	return c.Status(fiber.StatusOK).JSON(user)
 }
 
 // ChangePassword godoc
@@ -265,50 +176,27 @@ type ChangePasswordRequest struct {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Router /auth/change-password [post]
-func (h *AuthHandler) ChangePassword(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-
-	var req ChangePasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Validate new passwords match
-	if req.NewPassword != req.ConfirmNewPassword {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "New passwords do not match"})
-		return
-	}
-
-	// Get current password
-	var currentPassword string
-	err := database.DB.QueryRow("SELECT password FROM users WHERE id = $1", userID).Scan(&currentPassword)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
-	}
-
-	// Verify old password
-	if err := utils.CheckPassword(currentPassword, req.OldPassword); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid old password"})
-		return
-	}
-
-	// Hash new password
-	hashedPassword, err := utils.HashPassword(req.NewPassword)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
-		return
+	var req ChangePasswordRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Update password
-	_, err = database.DB.Exec("UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", hashedPassword, userID)
+	err := h.auth.ChangePassword(userID, services.ChangePasswordInput{
+		OldPassword:        req.OldPassword,
+		NewPassword:        req.NewPassword,
+		ConfirmNewPassword: req.ConfirmNewPassword,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password changed successfully"})
 }
 
 // UploadAvatar godoc
@@ -322,47 +210,48 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Router /auth/avatar [post]
-func (h *AuthHandler) UploadAvatar(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
+func (h *AuthHandler) UploadAvatar(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
 	file, err := c.FormFile("avatar")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
-		return
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No file uploaded"})
 	}
 
 	// Check file size
 	if file.Size > h.cfg.Upload.MaxFileSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large"})
-		return
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "File too large"})
 	}
 
-	// Get old avatar to delete
-	var oldAvatar sql.NullString
-	database.DB.QueryRow("SELECT avatar FROM users WHERE id = $1", userID).Scan(&oldAvatar)
-
-	// Save new file
 	relativePath, err := utils.SaveUploadedFile(file, h.cfg.Upload.Directory, "avatars")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Update user avatar
-	_, err = database.DB.Exec("UPDATE users SET avatar = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", relativePath, userID)
+	oldAvatar, err := h.auth.SetAvatar(userID, relativePath)
 	if err != nil {
 		utils.DeleteFile(h.cfg.Upload.Directory, relativePath)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update avatar"})
-		return
+		return respondError(c, err)
 	}
 
 	// Delete old avatar
-	if oldAvatar.Valid {
-		utils.DeleteFile(h.cfg.Upload.Directory, oldAvatar.String)
+	if oldAvatar != nil {
+		utils.DeleteFile(h.cfg.Upload.Directory, *oldAvatar)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Avatar uploaded successfully",
 		"avatar":  relativePath,
 	})
 }
+
+func newAuthResponse(result *services.AuthResult) AuthResponse {
+	return AuthResponse{
+		Token: result.Token,
+		Role:  string(result.User.Role),
+		User:  result.User,
+	}
+}
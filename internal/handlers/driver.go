@@ -1,34 +1,68 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
-	"net/http"
-	"time"
+	"bytes"
 
-	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
 	"taxi-service/internal/config"
-	"taxi-service/internal/database"
 	"taxi-service/internal/middleware"
-	"taxi-service/internal/models"
+	"taxi-service/internal/services"
+	"taxi-service/internal/statement"
 	"taxi-service/internal/utils"
 )
 
 // DriverHandler handles driver-related endpoints
 type DriverHandler struct {
-	cfg *config.Config
+	cfg     *config.Config
+	drivers *services.DriverService
 }
 
 // NewDriverHandler creates a new driver handler
-func NewDriverHandler(cfg *config.Config) *DriverHandler {
-	return &DriverHandler{cfg: cfg}
+func NewDriverHandler(cfg *config.Config, drivers *services.DriverService) *DriverHandler {
+	return &DriverHandler{cfg: cfg, drivers: drivers}
 }
 
-// ApplyAsDriverRequest represents driver application request
-type ApplyAsDriverRequest struct {
-	FullName   string `json:"full_name" binding:"required"`
-	CarModel   string `json:"car_model" binding:"required"`
-	CarNumber  string `json:"car_number" binding:"required"`
+// UpdateDriverProfileRequest represents driver profile update request
+type UpdateDriverProfileRequest struct {
+	FullName      string   `json:"full_name"`
+	CarModel      string   `json:"car_model"`
+	CarNumber     string   `json:"car_number"`
+	SeatCapacity  int      `json:"seat_capacity" validate:"omitempty,min=1,max=8"`
+	BaseLatitude  *float64 `json:"base_latitude" validate:"omitempty,latitude"`
+	BaseLongitude *float64 `json:"base_longitude" validate:"omitempty,longitude"`
+}
+
+// SetRoutesRequest represents the region pairs a driver serves
+type SetRoutesRequest struct {
+	Routes []ServiceRouteRequest `json:"routes" validate:"dive"`
+}
+
+// ServiceRouteRequest represents one region pair a driver serves
+type ServiceRouteRequest struct {
+	FromRegionID int64 `json:"from_region_id" validate:"required"`
+	ToRegionID   int64 `json:"to_region_id" validate:"required"`
+}
+
+// SetAvailabilityRequest represents the windows a driver can drive in
+type SetAvailabilityRequest struct {
+	Windows []AvailabilityWindowRequest `json:"windows" validate:"dive"`
+}
+
+// AvailabilityWindowRequest represents one window a driver can drive in
+type AvailabilityWindowRequest struct {
+	Date      string `json:"date" validate:"required"` // DD.MM.YYYY
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+}
+
+// ReleaseOrderRequest represents a driver giving an accepted order back
+type ReleaseOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CompleteOrderRequest represents a driver completing an order
+type CompleteOrderRequest struct {
+	CashCollected bool `json:"cash_collected"` // Fare of a cash order was collected
 }
 
 // ApplyAsDriver godoc
@@ -43,77 +77,47 @@ type ApplyAsDriverRequest struct {
 // @Param car_number formData string true "Car Number"
 // @Param license_image formData file true "License Image"
 // @Success 201 {object} models.DriverApplication
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /driver/apply [post]
-func (h *DriverHandler) ApplyAsDriver(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-
-	// Check if user already has driver role
-	var role string
-	database.DB.QueryRow("SELECT role FROM users WHERE id = $1", userID).Scan(&role)
-	if role == string(models.RoleDriver) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "You are already a driver"})
-		return
+func (h *DriverHandler) ApplyAsDriver(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Check if application already exists
-	var existingID int64
-	err := database.DB.QueryRow(`
-		SELECT id FROM driver_applications 
-		WHERE user_id = $1 AND status = 'pending'
-	`, userID).Scan(&existingID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Application already submitted and pending review"})
-		return
+	input := services.ApplyInput{
+		FullName:  c.FormValue("full_name"),
+		CarModel:  c.FormValue("car_model"),
+		CarNumber: c.FormValue("car_number"),
 	}
-
-	// Get form data
-	fullName := c.PostForm("full_name")
-	carModel := c.PostForm("car_model")
-	carNumber := c.PostForm("car_number")
-
-	if fullName == "" || carModel == "" || carNumber == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "All fields are required"})
-		return
+	if input.FullName == "" || input.CarModel == "" || input.CarNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "All fields are required"})
 	}
 
-	// Get phone number
-	var phoneNumber string
-	database.DB.QueryRow("SELECT phone_number FROM users WHERE id = $1", userID).Scan(&phoneNumber)
-
 	// Handle license image upload
 	file, err := c.FormFile("license_image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "License image is required"})
-		return
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "License image is required"})
 	}
 
-	// Save license image
-	licenseImage, err := utils.SaveUploadedFile(file, h.cfg.Upload.Directory, "licenses")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Create application
-	var application models.DriverApplication
-	err = database.DB.QueryRow(`
-		INSERT INTO driver_applications (user_id, full_name, phone_number, car_model, car_number, license_image, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, user_id, full_name, phone_number, car_model, car_number, license_image, status, created_at, updated_at
-	`, userID, fullName, phoneNumber, carModel, carNumber, licenseImage, "pending").Scan(
-		&application.ID, &application.UserID, &application.FullName, &application.PhoneNumber,
-		&application.CarModel, &application.CarNumber, &application.LicenseImage, &application.Status,
-		&application.CreatedAt, &application.UpdatedAt,
-	)
+	// Check file size
+	if file.Size > h.cfg.Upload.MaxFileSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "File too large"})
+	}
+
+	input.LicenseImage, err = utils.SaveUploadedFile(file, h.cfg.Upload.Directory, "licenses")
 	if err != nil {
-		utils.DeleteFile(h.cfg.Upload.Directory, licenseImage)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create application"})
-		return
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// TODO: Send notification to telegram admin group
+	application, err := h.drivers.Apply(userID, input)
+	if err != nil {
+		utils.DeleteFile(h.cfg.Upload.Directory, input.LicenseImage)
+		return respondError(c, err)
+	}
 
-	c.JSON(http.StatusCreated, application)
+	return c.Status(fiber.StatusCreated).JSON(application)
 }
 
 // GetDriverProfile godoc
@@ -123,142 +127,205 @@ func (h *DriverHandler) ApplyAsDriver(c *gin.Context) {
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} models.Driver
+// @Failure 404 {object} map[string]string
 // @Router /driver/profile [get]
-func (h *DriverHandler) GetDriverProfile(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-
-	var driver models.Driver
-	err := database.DB.QueryRow(`
-		SELECT id, user_id, full_name, car_model, car_number, license_image, 
-		       balance, rating, total_ratings, status, is_active, created_at, updated_at
-		FROM drivers WHERE user_id = $1
-	`, userID).Scan(
-		&driver.ID, &driver.UserID, &driver.FullName, &driver.CarModel, &driver.CarNumber,
-		&driver.LicenseImage, &driver.Balance, &driver.Rating, &driver.TotalRatings,
-		&driver.Status, &driver.IsActive, &driver.CreatedAt, &driver.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Driver profile not found"})
-		return
+func (h *DriverHandler) GetDriverProfile(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
+
+	driver, err := h.drivers.GetProfile(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, driver)
-}
-
-// UpdateDriverProfileRequest represents driver profile update
-type UpdateDriverProfileRequest struct {
-	FullName  string `json:"full_name"`
-	CarModel  string `json:"car_model"`
-	CarNumber string `json:"car_number"`
+	return c.Status(fiber.StatusOK).JSON(driver)
 }
 
 // UpdateDriverProfile godoc
 // @Summary Update driver profile
-// @Description Update driver's profile information
+// @Description Update driver's profile information, seat capacity and base point
 // @Tags Driver
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param request body UpdateDriverProfileRequest true "Profile update"
 // @Success 200 {object} models.Driver
+// @Failure 400 {object} map[string]string
 // @Router /driver/profile [put]
-func (h *DriverHandler) UpdateDriverProfile(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
+func (h *DriverHandler) UpdateDriverProfile(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
 	var req UpdateDriverProfileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	var driver models.Driver
-	err := database.DB.QueryRow(`
-		UPDATE drivers SET full_name = $1, car_model = $2, car_number = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE user_id = $4
-		RETURNING id, user_id, full_name, car_model, car_number, license_image, 
-		          balance, rating, total_ratings, status, is_active, created_at, updated_at
-	`, req.FullName, req.CarModel, req.CarNumber, userID).Scan(
-		&driver.ID, &driver.UserID, &driver.FullName, &driver.CarModel, &driver.CarNumber,
-		&driver.LicenseImage, &driver.Balance, &driver.Rating, &driver.TotalRatings,
-		&driver.Status, &driver.IsActive, &driver.CreatedAt, &driver.UpdatedAt,
-	)
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	driver, err := h.drivers.UpdateProfile(userID, services.UpdateDriverProfileInput{
+		FullName:      req.FullName,
+		CarModel:      req.CarModel,
+		CarNumber:     req.CarNumber,
+		SeatCapacity:  req.SeatCapacity,
+		BaseLatitude:  req.BaseLatitude,
+		BaseLongitude: req.BaseLongitude,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, driver)
+	return c.Status(fiber.StatusOK).JSON(driver)
 }
 
-// GetNewOrders godoc
-// @Summary Get new available orders
-// @Description Get list of orders available for drivers to accept
+// GetRoutes godoc
+// @Summary Get service routes
+// @Description Get the region pairs the driver serves
 // @Tags Driver
 // @Security BearerAuth
 // @Produce json
-// @Param type query string false "Filter by type (taxi/delivery)"
-// @Param from_region query int false "Filter by from region"
-// @Param to_region query int false "Filter by to region"
-// @Success 200 {array} models.Order
-// @Router /driver/orders/new [get]
-func (h *DriverHandler) GetNewOrders(c *gin.Context) {
-	orderType := c.Query("type")
-	fromRegion := c.Query("from_region")
-	toRegion := c.Query("to_region")
+// @Success 200 {array} models.DriverRoute
+// @Router /driver/routes [get]
+func (h *DriverHandler) GetRoutes(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	routes, err := h.drivers.Routes(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	query := `SELECT * FROM orders WHERE status = $1 AND (accept_deadline IS NULL OR accept_deadline > CURRENT_TIMESTAMP)`
-	args := []interface{}{models.OrderStatusPending}
-	argCount := 1
+	return c.Status(fiber.StatusOK).JSON(routes)
+}
 
-	if orderType != "" {
-		argCount++
-		query += fmt.Sprintf(" AND order_type = $%d", argCount)
-		args = append(args, orderType)
+// SetRoutes godoc
+// @Summary Set service routes
+// @Description Replace the region pairs the driver serves. New orders are only offered on these routes; with none, on every route.
+// @Tags Driver
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SetRoutesRequest true "Routes"
+// @Success 200 {array} models.DriverRoute
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /driver/routes [put]
+func (h *DriverHandler) SetRoutes(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
-	if fromRegion != "" {
-		argCount++
-		query += fmt.Sprintf(" AND from_region_id = $%d", argCount)
-		args = append(args, fromRegion)
+
+	var req SetRoutesRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
-	if toRegion != "" {
-		argCount++
-		query += fmt.Sprintf(" AND to_region_id = $%d", argCount)
-		args = append(args, toRegion)
+
+	routes := make([]services.ServiceRoute, 0, len(req.Routes))
+	for _, route := range req.Routes {
+		routes = append(routes, services.ServiceRoute{FromRegionID: route.FromRegionID, ToRegionID: route.ToRegionID})
 	}
 
-	query += " ORDER BY created_at DESC"
+	saved, err := h.drivers.SetRoutes(userID, routes)
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	rows, err := database.DB.Query(query, args...)
+	return c.Status(fiber.StatusOK).JSON(saved)
+}
+
+// GetAvailability godoc
+// @Summary Get availability
+// @Description Get the driver's availability windows from today on
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.DriverAvailability
+// @Router /driver/availability [get]
+func (h *DriverHandler) GetAvailability(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	windows, err := h.drivers.Availability(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
-		return
-	}
-	defer rows.Close()
-
-	orders := []models.Order{}
-	for rows.Next() {
-		var order models.Order
-		err := rows.Scan(
-			&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-			&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-			&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-			&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-			&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-			&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-			&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-			&order.CreatedAt, &order.UpdatedAt,
-		)
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(windows)
+}
+
+// SetAvailability godoc
+// @Summary Set availability
+// @Description Replace the driver's availability windows. New orders are only offered when due inside one; with none, on every date.
+// @Tags Driver
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SetAvailabilityRequest true "Availability windows"
+// @Success 200 {array} models.DriverAvailability
+// @Failure 400 {object} map[string]string
+// @Router /driver/availability [put]
+func (h *DriverHandler) SetAvailability(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	var req SetAvailabilityRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	windows := make([]services.AvailabilityWindow, 0, len(req.Windows))
+	for _, window := range req.Windows {
+		date, err := parseScheduledDate(window.Date)
 		if err != nil {
-			continue
+			return err
 		}
-		orders = append(orders, order)
+		windows = append(windows, services.AvailabilityWindow{Date: date, StartTime: window.StartTime, EndTime: window.EndTime})
+	}
+
+	saved, err := h.drivers.SetAvailability(userID, windows)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(saved)
+}
+
+// GetNewOrders godoc
+// @Summary Get new available orders
+// @Description Get the orders open for acceptance that suit the driver's routes, availability and seat capacity, nearest pickup first
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "Filter by type (taxi/delivery)"
+// @Param from_region query int false "Filter by from region"
+// @Param to_region query int false "Filter by to region"
+// @Success 200 {array} models.Order
+// @Router /driver/orders/new [get]
+func (h *DriverHandler) GetNewOrders(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	c.JSON(http.StatusOK, orders)
+	orders, err := h.drivers.ListNewOrders(userID, services.NewOrdersFilter{
+		Type:         c.Query("type"),
+		FromRegionID: int64(c.QueryInt("from_region")),
+		ToRegionID:   int64(c.QueryInt("to_region")),
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(orders)
 }
 
 // AcceptOrder godoc
@@ -269,161 +336,131 @@ func (h *DriverHandler) GetNewOrders(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Order ID"
 // @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /driver/orders/{id}/accept [post]
-func (h *DriverHandler) AcceptOrder(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	orderID := c.Param("id")
-
-	// Get driver info
-	var driver models.Driver
-	err := database.DB.QueryRow(`
-		SELECT id, balance, is_active FROM drivers WHERE user_id = $1
-	`, userID).Scan(&driver.ID, &driver.Balance, &driver.IsActive)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Driver profile not found"})
-		return
-	}
-
-	// Check if driver is active
-	if !driver.IsActive {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Driver account is not active"})
-		return
+func (h *DriverHandler) AcceptOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Get order
-	var order models.Order
-	err = database.DB.QueryRow(`
-		SELECT id, status, service_fee, accept_deadline
-		FROM orders WHERE id = $1
-	`, orderID).Scan(&order.ID, &order.Status, &order.ServiceFee, &order.AcceptDeadline)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-		return
-	}
+	orderID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return err
 	}
 
-	// Check order status
-	if order.Status != models.OrderStatusPending {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order is no longer available"})
-		return
+	order, err := h.drivers.AcceptOrder(userID, orderID)
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	// Check accept deadline
-	if order.AcceptDeadline != nil && order.AcceptDeadline.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order acceptance deadline has passed"})
-		return
-	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
 
-	// Check driver balance
-	if driver.Balance < order.ServiceFee {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance to accept order"})
-		return
+// StartOrder godoc
+// @Summary Start a trip
+// @Description Confirm pickup on an accepted order and move it to in progress
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Router /driver/orders/{id}/start [post]
+func (h *DriverHandler) StartOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Begin transaction
-	tx, err := database.DB.Begin()
+	orderID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return err
 	}
-	defer tx.Rollback()
 
-	// Update order
-	_, err = tx.Exec(`
-		UPDATE orders SET driver_id = $1, status = $2, accepted_at = CURRENT_TIMESTAMP, 
-		                  accept_deadline = NULL, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3 AND status = $4
-	`, driver.ID, models.OrderStatusAccepted, orderID, models.OrderStatusPending)
+	order, err := h.drivers.StartOrder(userID, orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept order"})
-		return
+		return respondError(c, err)
 	}
 
-	// Deduct service fee from driver balance
-	_, err = tx.Exec(`
-		UPDATE drivers SET balance = balance - $1 WHERE id = $2
-	`, order.ServiceFee, driver.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
-		return
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// ReleaseOrder godoc
+// @Summary Release an accepted order
+// @Description Give an accepted order back so other drivers can take it; part or all of the service fee may be kept depending on how close pickup is
+// @Tags Driver
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body ReleaseOrderRequest false "Reason"
+// @Success 200 {object} services.ReleaseResult
+// @Failure 400 {object} map[string]string
+// @Router /driver/orders/{id}/release [post]
+func (h *DriverHandler) ReleaseOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Create transaction record
-	_, err = tx.Exec(`
-		INSERT INTO transactions (driver_id, order_id, amount, type, description)
-		VALUES ($1, $2, $3, $4, $5)
-	`, driver.ID, order.ID, -order.ServiceFee, "debit", "Service fee for accepting order")
+	orderID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
-		return
+		return err
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
+	var req ReleaseOrderRequest
+	if len(c.Body()) > 0 {
+		if err := parseAndValidateJSON(c, &req); err != nil {
+			return err
+		}
 	}
 
-	// Get updated order
-	err = database.DB.QueryRow(`SELECT * FROM orders WHERE id = $1`, orderID).Scan(
-		&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-		&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-		&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-		&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-		&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-		&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-		&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-		&order.CreatedAt, &order.UpdatedAt,
-	)
-
-	// TODO: Send notification to user
+	result, err := h.drivers.ReleaseOrder(userID, orderID, req.Reason)
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	c.JSON(http.StatusOK, order)
+	return c.Status(fiber.StatusOK).JSON(result)
 }
 
 // CompleteOrder godoc
 // @Summary Complete an order
-// @Description Mark an order as completed
+// @Description Mark an in-progress order as completed. For cash orders, set cash_collected once the customer has paid the fare.
 // @Tags Driver
 // @Security BearerAuth
+// @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
+// @Param request body CompleteOrderRequest false "Cash collection"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
 // @Router /driver/orders/{id}/complete [post]
-func (h *DriverHandler) CompleteOrder(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	orderID := c.Param("id")
-
-	// Get driver ID
-	var driverID int64
-	err := database.DB.QueryRow("SELECT id FROM drivers WHERE user_id = $1", userID).Scan(&driverID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Driver profile not found"})
-		return
+func (h *DriverHandler) CompleteOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Update order
-	result, err := database.DB.Exec(`
-		UPDATE orders SET status = $1, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2 AND driver_id = $3 AND status = $4
-	`, models.OrderStatusCompleted, orderID, driverID, models.OrderStatusAccepted)
+	orderID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete order"})
-		return
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order not found or not assigned to you"})
-		return
+	var req CompleteOrderRequest
+	if len(c.Body()) > 0 {
+		if err := parseAndValidateJSON(c, &req); err != nil {
+			return err
+		}
 	}
 
-	// TODO: Send notification to user for rating
+	if err := h.drivers.CompleteOrder(userID, orderID, req.CashCollected); err != nil {
+		return respondError(c, err)
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Order completed successfully"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Order completed successfully"})
 }
 
 // GetDriverOrders godoc
@@ -435,65 +472,18 @@ func (h *DriverHandler) CompleteOrder(c *gin.Context) {
 // @Param status query string false "Filter by status"
 // @Success 200 {array} models.Order
 // @Router /driver/orders [get]
-func (h *DriverHandler) GetDriverOrders(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	status := c.Query("status")
-
-	// Get driver ID
-	var driverID int64
-	err := database.DB.QueryRow("SELECT id FROM drivers WHERE user_id = $1", userID).Scan(&driverID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Driver profile not found"})
-		return
+func (h *DriverHandler) GetDriverOrders(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	query := "SELECT * FROM orders WHERE driver_id = $1"
-	args := []interface{}{driverID}
-
-	if status != "" {
-		query += " AND status = $2"
-		args = append(args, status)
-	}
-
-	query += " ORDER BY created_at DESC"
-
-	rows, err := database.DB.Query(query, args...)
+	orders, err := h.drivers.ListOrders(userID, c.Query("status"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
-		return
-	}
-	defer rows.Close()
-
-	orders := []models.Order{}
-	for rows.Next() {
-		var order models.Order
-		err := rows.Scan(
-			&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-			&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-			&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-			&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-			&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-			&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-			&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-			&order.CreatedAt, &order.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		orders = append(orders, order)
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, orders)
-}
-
-// DriverStatistics represents driver statistics
-type DriverStatistics struct {
-	TotalOrders      int     `json:"total_orders"`
-	CompletedOrders  int     `json:"completed_orders"`
-	TotalEarnings    float64 `json:"total_earnings"`
-	CurrentBalance   float64 `json:"current_balance"`
-	AverageRating    float64 `json:"average_rating"`
-	TotalRatings     int     `json:"total_ratings"`
+	return c.Status(fiber.StatusOK).JSON(orders)
 }
 
 // GetDriverStatistics godoc
@@ -503,52 +493,88 @@ type DriverStatistics struct {
 // @Security BearerAuth
 // @Produce json
 // @Param period query string false "Period (daily/monthly/yearly)"
-// @Success 200 {object} DriverStatistics
+// @Success 200 {object} services.DriverStatistics
 // @Router /driver/statistics [get]
-func (h *DriverHandler) GetDriverStatistics(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	period := c.Query("period")
-
-	// Get driver info
-	var driver models.Driver
-	err := database.DB.QueryRow(`
-		SELECT id, balance, rating, total_ratings FROM drivers WHERE user_id = $1
-	`, userID).Scan(&driver.ID, &driver.Balance, &driver.Rating, &driver.TotalRatings)
+func (h *DriverHandler) GetDriverStatistics(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	stats, err := h.drivers.Statistics(userID, c.Query("period"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Driver profile not found"})
-		return
-	}
-
-	// Build query based on period
-	var timeFilter string
-	switch period {
-	case "daily":
-		timeFilter = "AND DATE(o.created_at) = CURRENT_DATE"
-	case "monthly":
-		timeFilter = "AND DATE_TRUNC('month', o.created_at) = DATE_TRUNC('month', CURRENT_DATE)"
-	case "yearly":
-		timeFilter = "AND DATE_TRUNC('year', o.created_at) = DATE_TRUNC('year', CURRENT_DATE)"
-	default:
-		timeFilter = ""
-	}
-
-	var stats DriverStatistics
-	err = database.DB.QueryRow(fmt.Sprintf(`
-		SELECT 
-			COUNT(o.id) as total_orders,
-			COUNT(CASE WHEN o.status = 'completed' THEN 1 END) as completed_orders,
-			COALESCE(SUM(CASE WHEN o.status = 'completed' THEN o.service_fee ELSE 0 END), 0) as total_earnings
-		FROM orders o
-		WHERE o.driver_id = $1 %s
-	`, timeFilter), driver.ID).Scan(&stats.TotalOrders, &stats.CompletedOrders, &stats.TotalEarnings)
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+// GetWalletTransactions godoc
+// @Summary Get wallet history
+// @Description Get the postings that changed the driver's balance, newest first. Pass next_cursor from the previous page as cursor to get the next page.
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "Filter by kind (service_fee/fee_refund/top_up/opening_balance)"
+// @Param from_date query string false "From date (YYYY-MM-DD)"
+// @Param to_date query string false "To date (YYYY-MM-DD)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} services.WalletPage
+// @Failure 400 {object} map[string]string
+// @Router /driver/wallet/transactions [get]
+func (h *DriverHandler) GetWalletTransactions(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	page, err := h.drivers.WalletTransactions(userID, walletQuery(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// GetWalletStatement godoc
+// @Summary Download wallet statement
+// @Description Download the driver's wallet statement for a calendar month as CSV or PDF
+// @Tags Driver
+// @Security BearerAuth
+// @Produce text/csv
+// @Produce application/pdf
+// @Param month query string false "Month (YYYY-MM), default current month"
+// @Param format query string false "csv or pdf (default csv)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Router /driver/wallet/statement [get]
+func (h *DriverHandler) GetWalletStatement(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "pdf" {
+		return fiber.NewError(fiber.StatusBadRequest, "Format must be csv or pdf")
+	}
+
+	stmt, err := h.drivers.WalletStatement(userID, c.Query("month"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statistics"})
-		return
+		return respondError(c, err)
 	}
 
-	stats.CurrentBalance = driver.Balance
-	stats.AverageRating = driver.Rating
-	stats.TotalRatings = driver.TotalRatings
+	var buf bytes.Buffer
+	if format == "pdf" {
+		err = statement.WritePDF(&buf, stmt)
+	} else {
+		err = statement.WriteCSV(&buf, stmt)
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	c.JSON(http.StatusOK, stats)
+	c.Attachment(stmt.FileName(format))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
 }
@@ -1,43 +0,0 @@
-package handlers
-
-import "github.com/gofiber/fiber/v2"
-
-// ApplyAsDriverFiber - Fiber version
-func (h *DriverHandler) ApplyAsDriverFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDriverProfileFiber - Fiber version
-func (h *DriverHandler) GetDriverProfileFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// UpdateDriverProfileFiber - Fiber version
-func (h *DriverHandler) UpdateDriverProfileFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetNewOrdersFiber - Fiber version
-func (h *DriverHandler) GetNewOrdersFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// AcceptOrderFiber - Fiber version
-func (h *DriverHandler) AcceptOrderFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// CompleteOrderFiber - Fiber version
-func (h *DriverHandler) CompleteOrderFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDriverOrdersFiber - Fiber version
-func (h *DriverHandler) GetDriverOrdersFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDriverStatisticsFiber - Fiber version
-func (h *DriverHandler) GetDriverStatisticsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
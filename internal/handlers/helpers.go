@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"taxi-service/internal/services"
 )
 
 var requestValidator = validator.New()
@@ -40,3 +43,49 @@ func toSnakeCase(input string) string {
 	return strings.ToLower(builder.String())
 }
 
+// respondError writes a service error as a JSON error response; internal
+// failures are logged with their cause and only the safe message is returned
+func respondError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch services.KindOf(err) {
+	case services.KindInvalid:
+		status = fiber.StatusBadRequest
+	case services.KindUnauthorized:
+		status = fiber.StatusUnauthorized
+	case services.KindForbidden:
+		status = fiber.StatusForbidden
+	case services.KindNotFound:
+		status = fiber.StatusNotFound
+	case services.KindConflict:
+		status = fiber.StatusConflict
+	default:
+		log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+	}
+
+	message := "Internal server error"
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		message = serviceErr.Message
+	}
+	return c.Status(status).JSON(fiber.Map{"error": message})
+}
+
+// paramID parses a positive integer path parameter
+func paramID(c *fiber.Ctx, name string) (int64, error) {
+	id, err := c.ParamsInt(name)
+	if err != nil || id <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid "+strings.ReplaceAll(name, "_", " "))
+	}
+	return int64(id), nil
+}
+
+// walletQuery reads the wallet history filters and paging cursor from the query string
+func walletQuery(c *fiber.Ctx) services.WalletQuery {
+	return services.WalletQuery{
+		Kind:     c.Query("type"),
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+		Cursor:   c.Query("cursor"),
+		Limit:    c.QueryInt("limit"),
+	}
+}
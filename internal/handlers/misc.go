@@ -1,27 +1,25 @@
 package handlers
 
 import (
-	"database/sql"
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-	"taxi-service/internal/database"
+	"github.com/gofiber/fiber/v2"
 	"taxi-service/internal/middleware"
-	"taxi-service/internal/models"
+	"taxi-service/internal/services"
 )
 
 // RatingHandler handles rating-related endpoints
-type RatingHandler struct{}
+type RatingHandler struct {
+	ratings *services.RatingService
+}
 
 // NewRatingHandler creates a new rating handler
-func NewRatingHandler() *RatingHandler {
-	return &RatingHandler{}
+func NewRatingHandler(ratings *services.RatingService) *RatingHandler {
+	return &RatingHandler{ratings: ratings}
 }
 
 // CreateRatingRequest represents rating creation request
 type CreateRatingRequest struct {
-	OrderID int64  `json:"order_id" binding:"required"`
-	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	OrderID int64  `json:"order_id" validate:"required"`
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
 	Comment string `json:"comment"`
 }
 
@@ -34,103 +32,30 @@ type CreateRatingRequest struct {
 // @Produce json
 // @Param request body CreateRatingRequest true "Rating details"
 // @Success 201 {object} models.Rating
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /ratings [post]
-func (h *RatingHandler) CreateRating(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-
-	var req CreateRatingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Verify order belongs to user and is completed
-	var order models.Order
-	err := database.DB.QueryRow(`
-		SELECT id, user_id, driver_id, status
-		FROM orders WHERE id = $1 AND user_id = $2
-	`, req.OrderID, userID).Scan(&order.ID, &order.UserID, &order.DriverID, &order.Status)
-	
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-		return
-	}
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+func (h *RatingHandler) CreateRating(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	if order.Status != models.OrderStatusCompleted {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Can only rate completed orders"})
-		return
-	}
-
-	if order.DriverID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order has no driver assigned"})
-		return
-	}
-
-	// Check if already rated
-	var existingID int64
-	database.DB.QueryRow("SELECT id FROM ratings WHERE order_id = $1", req.OrderID).Scan(&existingID)
-	if existingID > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Order already rated"})
-		return
-	}
-
-	// Begin transaction
-	tx, err := database.DB.Begin()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
-	}
-	defer tx.Rollback()
-
-	// Create rating
-	var rating models.Rating
-	var comment *string
-	if req.Comment != "" {
-		comment = &req.Comment
-	}
-	
-	err = tx.QueryRow(`
-		INSERT INTO ratings (order_id, user_id, driver_id, rating, comment)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, order_id, user_id, driver_id, rating, comment, created_at
-	`, req.OrderID, userID, *order.DriverID, req.Rating, comment).Scan(
-		&rating.ID, &rating.OrderID, &rating.UserID, &rating.DriverID, 
-		&rating.Rating, &rating.Comment, &rating.CreatedAt,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create rating"})
-		return
-	}
-
-	// Update driver rating
-	var avgRating float64
-	var totalRatings int
-	err = tx.QueryRow(`
-		SELECT AVG(rating), COUNT(*) FROM ratings WHERE driver_id = $1
-	`, *order.DriverID).Scan(&avgRating, &totalRatings)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate rating"})
-		return
+	var req CreateRatingRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	_, err = tx.Exec(`
-		UPDATE drivers SET rating = $1, total_ratings = $2 WHERE id = $3
-	`, avgRating, totalRatings, *order.DriverID)
+	rating, err := h.ratings.CreateRating(userID, services.CreateRatingInput{
+		OrderID: req.OrderID,
+		Rating:  req.Rating,
+		Comment: req.Comment,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update driver rating"})
-		return
+		return respondError(c, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
-	}
-
-	c.JSON(http.StatusCreated, rating)
+	return c.Status(fiber.StatusCreated).JSON(rating)
 }
 
 // GetDriverRatings godoc
@@ -141,41 +66,28 @@ func (h *RatingHandler) CreateRating(c *gin.Context) {
 // @Param driver_id path int true "Driver ID"
 // @Success 200 {array} models.Rating
 // @Router /ratings/driver/{driver_id} [get]
-func (h *RatingHandler) GetDriverRatings(c *gin.Context) {
-	driverID := c.Param("driver_id")
-
-	rows, err := database.DB.Query(`
-		SELECT id, order_id, user_id, driver_id, rating, comment, created_at
-		FROM ratings WHERE driver_id = $1 ORDER BY created_at DESC
-	`, driverID)
+func (h *RatingHandler) GetDriverRatings(c *fiber.Ctx) error {
+	driverID, err := paramID(c, "driver_id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings"})
-		return
+		return err
 	}
-	defer rows.Close()
 
-	ratings := []models.Rating{}
-	for rows.Next() {
-		var rating models.Rating
-		err := rows.Scan(
-			&rating.ID, &rating.OrderID, &rating.UserID, &rating.DriverID,
-			&rating.Rating, &rating.Comment, &rating.CreatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		ratings = append(ratings, rating)
+	ratings, err := h.ratings.ListDriverRatings(driverID)
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, ratings)
+	return c.Status(fiber.StatusOK).JSON(ratings)
 }
 
 // NotificationHandler handles notification endpoints
-type NotificationHandler struct{}
+type NotificationHandler struct {
+	notifications *services.NotificationService
+}
 
 // NewNotificationHandler creates new notification handler
-func NewNotificationHandler() *NotificationHandler {
-	return &NotificationHandler{}
+func NewNotificationHandler(notifications *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
 }
 
 // GetMyNotifications godoc
@@ -187,37 +99,18 @@ func NewNotificationHandler() *NotificationHandler {
 // @Param unread query bool false "Filter unread only"
 // @Success 200 {array} models.Notification
 // @Router /notifications [get]
-func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	unreadOnly := c.Query("unread") == "true"
-
-	query := "SELECT * FROM notifications WHERE user_id = $1"
-	if unreadOnly {
-		query += " AND is_read = false"
+func (h *NotificationHandler) GetMyNotifications(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
-	query += " ORDER BY created_at DESC"
 
-	rows, err := database.DB.Query(query, userID)
+	notifications, err := h.notifications.List(userID, c.Query("unread") == "true")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
-		return
-	}
-	defer rows.Close()
-
-	notifications := []models.Notification{}
-	for rows.Next() {
-		var notif models.Notification
-		err := rows.Scan(
-			&notif.ID, &notif.UserID, &notif.Title, &notif.Message,
-			&notif.Type, &notif.RelatedID, &notif.IsRead, &notif.CreatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		notifications = append(notifications, notif)
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, notifications)
+	return c.Status(fiber.StatusOK).JSON(notifications)
 }
 
 // MarkNotificationRead godoc
@@ -228,35 +121,41 @@ func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Notification ID"
 // @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /notifications/{id}/read [post]
-func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	notifID := c.Param("id")
+func (h *NotificationHandler) MarkNotificationRead(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
-	_, err := database.DB.Exec(`
-		UPDATE notifications SET is_read = true WHERE id = $1 AND user_id = $2
-	`, notifID, userID)
+	notificationID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
-		return
+		return err
+	}
+
+	if err := h.notifications.MarkRead(userID, notificationID); err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Notification marked as read"})
 }
 
 // RegionHandler handles region and district endpoints
-type RegionHandler struct{}
+type RegionHandler struct {
+	regions *services.RegionService
+}
 
 // NewRegionHandler creates new region handler
-func NewRegionHandler() *RegionHandler {
-	return &RegionHandler{}
+func NewRegionHandler(regions *services.RegionService) *RegionHandler {
+	return &RegionHandler{regions: regions}
 }
 
 // CreateRegionRequest represents region creation request
 type CreateRegionRequest struct {
-	NameUzLat string `json:"name_uz_lat" binding:"required"`
-	NameUzCyr string `json:"name_uz_cyr" binding:"required"`
-	NameRu    string `json:"name_ru" binding:"required"`
+	NameUzLat string `json:"name_uz_lat" validate:"required"`
+	NameUzCyr string `json:"name_uz_cyr" validate:"required"`
+	NameRu    string `json:"name_ru" validate:"required"`
 }
 
 // UpdateRegionRequest represents region update request
@@ -266,6 +165,21 @@ type UpdateRegionRequest struct {
 	NameRu    string `json:"name_ru"`
 }
 
+// CreateDistrictRequest represents district creation request
+type CreateDistrictRequest struct {
+	RegionID  int64  `json:"region_id" validate:"required"`
+	NameUzLat string `json:"name_uz_lat" validate:"required"`
+	NameUzCyr string `json:"name_uz_cyr" validate:"required"`
+	NameRu    string `json:"name_ru" validate:"required"`
+}
+
+// UpdateDistrictRequest represents district update request
+type UpdateDistrictRequest struct {
+	NameUzLat string `json:"name_uz_lat"`
+	NameUzCyr string `json:"name_uz_cyr"`
+	NameRu    string `json:"name_ru"`
+}
+
 // GetRegions godoc
 // @Summary Get all regions
 // @Description Get list of all regions
@@ -273,25 +187,13 @@ type UpdateRegionRequest struct {
 // @Produce json
 // @Success 200 {array} models.Region
 // @Router /regions [get]
-func (h *RegionHandler) GetRegions(c *gin.Context) {
-	rows, err := database.DB.Query("SELECT * FROM regions ORDER BY name_uz_lat")
+func (h *RegionHandler) GetRegions(c *fiber.Ctx) error {
+	regions, err := h.regions.ListRegions()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch regions"})
-		return
+		return respondError(c, err)
 	}
-	defer rows.Close()
 
-	regions := []models.Region{}
-	for rows.Next() {
-		var region models.Region
-		err := rows.Scan(&region.ID, &region.NameUzLat, &region.NameUzCyr, &region.NameRu, &region.CreatedAt)
-		if err != nil {
-			continue
-		}
-		regions = append(regions, region)
-	}
-
-	c.JSON(http.StatusOK, regions)
+	return c.Status(fiber.StatusOK).JSON(regions)
 }
 
 // GetRegion godoc
@@ -301,24 +203,20 @@ func (h *RegionHandler) GetRegions(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Region ID"
 // @Success 200 {object} models.Region
+// @Failure 404 {object} map[string]string
 // @Router /regions/{id} [get]
-func (h *RegionHandler) GetRegion(c *gin.Context) {
-	regionID := c.Param("id")
-
-	var region models.Region
-	err := database.DB.QueryRow("SELECT * FROM regions WHERE id = $1", regionID).Scan(
-		&region.ID, &region.NameUzLat, &region.NameUzCyr, &region.NameRu, &region.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Region not found"})
-		return
+func (h *RegionHandler) GetRegion(c *fiber.Ctx) error {
+	regionID, err := paramID(c, "id")
+	if err != nil {
+		return err
 	}
+
+	region, err := h.regions.GetRegion(regionID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, region)
+	return c.Status(fiber.StatusOK).JSON(region)
 }
 
 // CreateRegion godoc
@@ -331,27 +229,22 @@ func (h *RegionHandler) GetRegion(c *gin.Context) {
 // @Param request body CreateRegionRequest true "Region details"
 // @Success 201 {object} models.Region
 // @Router /regions [post]
-func (h *RegionHandler) CreateRegion(c *gin.Context) {
+func (h *RegionHandler) CreateRegion(c *fiber.Ctx) error {
 	var req CreateRegionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	var region models.Region
-	err := database.DB.QueryRow(`
-		INSERT INTO regions (name_uz_lat, name_uz_cyr, name_ru)
-		VALUES ($1, $2, $3)
-		RETURNING id, name_uz_lat, name_uz_cyr, name_ru, created_at
-	`, req.NameUzLat, req.NameUzCyr, req.NameRu).Scan(
-		&region.ID, &region.NameUzLat, &region.NameUzCyr, &region.NameRu, &region.CreatedAt,
-	)
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	region, err := h.regions.CreateRegion(services.RegionNames{
+		NameUzLat: req.NameUzLat,
+		NameUzCyr: req.NameUzCyr,
+		NameRu:    req.NameRu,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create region"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusCreated, region)
+	return c.Status(fiber.StatusCreated).JSON(region)
 }
 
 // UpdateRegion godoc
@@ -364,88 +257,29 @@ func (h *RegionHandler) CreateRegion(c *gin.Context) {
 // @Param id path int true "Region ID"
 // @Param request body UpdateRegionRequest true "Region details"
 // @Success 200 {object} models.Region
+// @Failure 404 {object} map[string]string
 // @Router /regions/{id} [put]
-func (h *RegionHandler) UpdateRegion(c *gin.Context) {
-	regionID := c.Param("id")
-
-	var req UpdateRegionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Build dynamic update query
-	query := "UPDATE regions SET "
-	args := []interface{}{}
-	argCount := 1
-
-	if req.NameUzLat != "" {
-		query += "name_uz_lat = $1, "
-		args = append(args, req.NameUzLat)
-		argCount++
-	}
-	if req.NameUzCyr != "" {
-		if argCount == 1 {
-			query += "name_uz_cyr = $1, "
-		} else if argCount == 2 {
-			query += "name_uz_cyr = $2, "
-		} else {
-			query += "name_uz_cyr = $3, "
-		}
-		args = append(args, req.NameUzCyr)
-		argCount++
-	}
-	if req.NameRu != "" {
-		if argCount == 1 {
-			query += "name_ru = $1, "
-		} else if argCount == 2 {
-			query += "name_ru = $2, "
-		} else if argCount == 3 {
-			query += "name_ru = $3, "
-		} else {
-			query += "name_ru = $4, "
-		}
-		args = append(args, req.NameRu)
-		argCount++
-	}
-
-	if len(args) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
-		return
-	}
-
-	// Remove trailing comma and add WHERE clause
-	query = query[:len(query)-2]
-	if argCount == 2 {
-		query += " WHERE id = $2"
-	} else if argCount == 3 {
-		query += " WHERE id = $3"
-	} else if argCount == 4 {
-		query += " WHERE id = $4"
-	} else {
-		query += " WHERE id = $5"
-	}
-	args = append(args, regionID)
-
-	result, err := database.DB.Exec(query, args...)
+func (h *RegionHandler) UpdateRegion(c *fiber.Ctx) error {
+	regionID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update region"})
-		return
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Region not found"})
-		return
+	var req UpdateRegionRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Fetch updated region
-	var region models.Region
-	database.DB.QueryRow("SELECT * FROM regions WHERE id = $1", regionID).Scan(
-		&region.ID, &region.NameUzLat, &region.NameUzCyr, &region.NameRu, &region.CreatedAt,
-	)
+	region, err := h.regions.UpdateRegion(regionID, services.RegionNames{
+		NameUzLat: req.NameUzLat,
+		NameUzCyr: req.NameUzCyr,
+		NameRu:    req.NameRu,
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	c.JSON(http.StatusOK, region)
+	return c.Status(fiber.StatusOK).JSON(region)
 }
 
 // DeleteRegion godoc
@@ -456,23 +290,19 @@ func (h *RegionHandler) UpdateRegion(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Region ID"
 // @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /regions/{id} [delete]
-func (h *RegionHandler) DeleteRegion(c *gin.Context) {
-	regionID := c.Param("id")
-
-	result, err := database.DB.Exec("DELETE FROM regions WHERE id = $1", regionID)
+func (h *RegionHandler) DeleteRegion(c *fiber.Ctx) error {
+	regionID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete region"})
-		return
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Region not found"})
-		return
+	if err := h.regions.DeleteRegion(regionID); err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Region deleted successfully"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Region deleted successfully"})
 }
 
 // GetDistricts godoc
@@ -483,42 +313,18 @@ func (h *RegionHandler) DeleteRegion(c *gin.Context) {
 // @Param id path int true "Region ID"
 // @Success 200 {array} models.District
 // @Router /regions/{id}/districts [get]
-func (h *RegionHandler) GetDistricts(c *gin.Context) {
-	regionID := c.Param("id")
-
-	rows, err := database.DB.Query("SELECT * FROM districts WHERE region_id = $1 ORDER BY name_uz_lat", regionID)
+func (h *RegionHandler) GetDistricts(c *fiber.Ctx) error {
+	regionID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch districts"})
-		return
+		return err
 	}
-	defer rows.Close()
 
-	districts := []models.District{}
-	for rows.Next() {
-		var district models.District
-		err := rows.Scan(&district.ID, &district.RegionID, &district.NameUzLat, &district.NameUzCyr, &district.NameRu, &district.CreatedAt)
-		if err != nil {
-			continue
-		}
-		districts = append(districts, district)
+	districts, err := h.regions.ListDistricts(regionID)
+	if err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, districts)
-}
-
-// CreateDistrictRequest represents district creation request
-type CreateDistrictRequest struct {
-	RegionID  int64  `json:"region_id" binding:"required"`
-	NameUzLat string `json:"name_uz_lat" binding:"required"`
-	NameUzCyr string `json:"name_uz_cyr" binding:"required"`
-	NameRu    string `json:"name_ru" binding:"required"`
-}
-
-// UpdateDistrictRequest represents district update request
-type UpdateDistrictRequest struct {
-	NameUzLat string `json:"name_uz_lat"`
-	NameUzCyr string `json:"name_uz_cyr"`
-	NameRu    string `json:"name_ru"`
+	return c.Status(fiber.StatusOK).JSON(districts)
 }
 
 // GetDistrict godoc
@@ -528,24 +334,20 @@ type UpdateDistrictRequest struct {
 // @Produce json
 // @Param id path int true "District ID"
 // @Success 200 {object} models.District
+// @Failure 404 {object} map[string]string
 // @Router /districts/{id} [get]
-func (h *RegionHandler) GetDistrict(c *gin.Context) {
-	districtID := c.Param("id")
-
-	var district models.District
-	err := database.DB.QueryRow("SELECT * FROM districts WHERE id = $1", districtID).Scan(
-		&district.ID, &district.RegionID, &district.NameUzLat, &district.NameUzCyr, &district.NameRu, &district.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "District not found"})
-		return
+func (h *RegionHandler) GetDistrict(c *fiber.Ctx) error {
+	districtID, err := paramID(c, "id")
+	if err != nil {
+		return err
 	}
+
+	district, err := h.regions.GetDistrict(districtID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, district)
+	return c.Status(fiber.StatusOK).JSON(district)
 }
 
 // CreateDistrict godoc
@@ -558,27 +360,22 @@ func (h *RegionHandler) GetDistrict(c *gin.Context) {
 // @Param request body CreateDistrictRequest true "District details"
 // @Success 201 {object} models.District
 // @Router /districts [post]
-func (h *RegionHandler) CreateDistrict(c *gin.Context) {
+func (h *RegionHandler) CreateDistrict(c *fiber.Ctx) error {
 	var req CreateDistrictRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	var district models.District
-	err := database.DB.QueryRow(`
-		INSERT INTO districts (region_id, name_uz_lat, name_uz_cyr, name_ru)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, region_id, name_uz_lat, name_uz_cyr, name_ru, created_at
-	`, req.RegionID, req.NameUzLat, req.NameUzCyr, req.NameRu).Scan(
-		&district.ID, &district.RegionID, &district.NameUzLat, &district.NameUzCyr, &district.NameRu, &district.CreatedAt,
-	)
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	district, err := h.regions.CreateDistrict(req.RegionID, services.RegionNames{
+		NameUzLat: req.NameUzLat,
+		NameUzCyr: req.NameUzCyr,
+		NameRu:    req.NameRu,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create district"})
-		return
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusCreated, district)
+	return c.Status(fiber.StatusCreated).JSON(district)
 }
 
 // UpdateDistrict godoc
@@ -591,88 +388,29 @@ func (h *RegionHandler) CreateDistrict(c *gin.Context) {
 // @Param id path int true "District ID"
 // @Param request body UpdateDistrictRequest true "District details"
 // @Success 200 {object} models.District
+// @Failure 404 {object} map[string]string
 // @Router /districts/{id} [put]
-func (h *RegionHandler) UpdateDistrict(c *gin.Context) {
-	districtID := c.Param("id")
-
-	var req UpdateDistrictRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Build dynamic update query
-	query := "UPDATE districts SET "
-	args := []interface{}{}
-	argCount := 1
-
-	if req.NameUzLat != "" {
-		query += "name_uz_lat = $1, "
-		args = append(args, req.NameUzLat)
-		argCount++
-	}
-	if req.NameUzCyr != "" {
-		if argCount == 1 {
-			query += "name_uz_cyr = $1, "
-		} else if argCount == 2 {
-			query += "name_uz_cyr = $2, "
-		} else {
-			query += "name_uz_cyr = $3, "
-		}
-		args = append(args, req.NameUzCyr)
-		argCount++
-	}
-	if req.NameRu != "" {
-		if argCount == 1 {
-			query += "name_ru = $1, "
-		} else if argCount == 2 {
-			query += "name_ru = $2, "
-		} else if argCount == 3 {
-			query += "name_ru = $3, "
-		} else {
-			query += "name_ru = $4, "
-		}
-		args = append(args, req.NameRu)
-		argCount++
-	}
-
-	if len(args) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
-		return
-	}
-
-	// Remove trailing comma and add WHERE clause
-	query = query[:len(query)-2]
-	if argCount == 2 {
-		query += " WHERE id = $2"
-	} else if argCount == 3 {
-		query += " WHERE id = $3"
-	} else if argCount == 4 {
-		query += " WHERE id = $4"
-	} else {
-		query += " WHERE id = $5"
-	}
-	args = append(args, districtID)
-
-	result, err := database.DB.Exec(query, args...)
+func (h *RegionHandler) UpdateDistrict(c *fiber.Ctx) error {
+	districtID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update district"})
-		return
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "District not found"})
-		return
+	var req UpdateDistrictRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Fetch updated district
-	var district models.District
-	database.DB.QueryRow("SELECT * FROM districts WHERE id = $1", districtID).Scan(
-		&district.ID, &district.RegionID, &district.NameUzLat, &district.NameUzCyr, &district.NameRu, &district.CreatedAt,
-	)
+	district, err := h.regions.UpdateDistrict(districtID, services.RegionNames{
+		NameUzLat: req.NameUzLat,
+		NameUzCyr: req.NameUzCyr,
+		NameRu:    req.NameRu,
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
 
-	c.JSON(http.StatusOK, district)
+	return c.Status(fiber.StatusOK).JSON(district)
 }
 
 // DeleteDistrict godoc
@@ -683,36 +421,34 @@ func (h *RegionHandler) UpdateDistrict(c *gin.Context) {
 // @Produce json
 // @Param id path int true "District ID"
 // @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /districts/{id} [delete]
-func (h *RegionHandler) DeleteDistrict(c *gin.Context) {
-	districtID := c.Param("id")
-
-	result, err := database.DB.Exec("DELETE FROM districts WHERE id = $1", districtID)
+func (h *RegionHandler) DeleteDistrict(c *fiber.Ctx) error {
+	districtID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete district"})
-		return
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "District not found"})
-		return
+	if err := h.regions.DeleteDistrict(districtID); err != nil {
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "District deleted successfully"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "District deleted successfully"})
 }
 
 // FeedbackHandler handles feedback endpoints
-type FeedbackHandler struct{}
+type FeedbackHandler struct {
+	feedback *services.FeedbackService
+}
 
 // NewFeedbackHandler creates new feedback handler
-func NewFeedbackHandler() *FeedbackHandler {
-	return &FeedbackHandler{}
+func NewFeedbackHandler(feedback *services.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{feedback: feedback}
 }
 
 // SubmitFeedbackRequest represents feedback submission
 type SubmitFeedbackRequest struct {
-	Message string `json:"message" binding:"required"`
+	Message string `json:"message" validate:"required"`
 }
 
 // SubmitFeedback godoc
@@ -725,27 +461,21 @@ type SubmitFeedbackRequest struct {
 // @Param request body SubmitFeedbackRequest true "Feedback message"
 // @Success 201 {object} models.Feedback
 // @Router /feedback [post]
-func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
+func (h *FeedbackHandler) SubmitFeedback(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
 	var req SubmitFeedbackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	var feedback models.Feedback
-	err := database.DB.QueryRow(`
-		INSERT INTO feedback (user_id, message)
-		VALUES ($1, $2)
-		RETURNING id, user_id, message, created_at
-	`, userID, req.Message).Scan(&feedback.ID, &feedback.UserID, &feedback.Message, &feedback.CreatedAt)
+	feedback, err := h.feedback.Submit(userID, req.Message)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit feedback"})
-		return
+		return respondError(c, err)
 	}
 
-	// TODO: Send to Telegram admin group
-
-	c.JSON(http.StatusCreated, feedback)
+	return c.Status(fiber.StatusCreated).JSON(feedback)
 }
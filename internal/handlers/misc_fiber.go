@@ -1,78 +0,0 @@
-package handlers
-
-import "github.com/gofiber/fiber/v2"
-
-// CreateRatingFiber - Fiber version
-func (h *RatingHandler) CreateRatingFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDriverRatingsFiber - Fiber version
-func (h *RatingHandler) GetDriverRatingsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetMyNotificationsFiber - Fiber version
-func (h *NotificationHandler) GetMyNotificationsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// MarkNotificationReadFiber - Fiber version
-func (h *NotificationHandler) MarkNotificationReadFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetRegionsFiber - Fiber version
-func (h *RegionHandler) GetRegionsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetRegionFiber - Fiber version
-func (h *RegionHandler) GetRegionFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDistrictsFiber - Fiber version
-func (h *RegionHandler) GetDistrictsFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// GetDistrictFiber - Fiber version
-func (h *RegionHandler) GetDistrictFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// CreateRegionFiber - Fiber version
-func (h *RegionHandler) CreateRegionFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// UpdateRegionFiber - Fiber version
-func (h *RegionHandler) UpdateRegionFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// DeleteRegionFiber - Fiber version
-func (h *RegionHandler) DeleteRegionFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// CreateDistrictFiber - Fiber version
-func (h *RegionHandler) CreateDistrictFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// UpdateDistrictFiber - Fiber version
-func (h *RegionHandler) UpdateDistrictFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// DeleteDistrictFiber - Fiber version
-func (h *RegionHandler) DeleteDistrictFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
-
-// SubmitFeedbackFiber - Fiber version
-func (h *FeedbackHandler) SubmitFeedbackFiber(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotImplemented).JSON(fiber.H{"error": "Not implemented yet"})
-}
@@ -1,69 +1,107 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
-	"net/http"
 	"time"
 
-	"github.com/gin-gonic/gin"
-	"taxi-service/internal/config"
-	"taxi-service/internal/database"
+	"github.com/gofiber/fiber/v2"
 	"taxi-service/internal/middleware"
 	"taxi-service/internal/models"
+	"taxi-service/internal/services"
 )
 
 // OrderHandler handles order-related endpoints
 type OrderHandler struct {
-	cfg *config.Config
+	orders *services.OrderService
 }
 
 // NewOrderHandler creates a new order handler
-func NewOrderHandler(cfg *config.Config) *OrderHandler {
-	return &OrderHandler{cfg: cfg}
+func NewOrderHandler(orders *services.OrderService) *OrderHandler {
+	return &OrderHandler{orders: orders}
 }
 
 // CreateTaxiOrderRequest represents taxi order creation request
 type CreateTaxiOrderRequest struct {
-	CustomerName    string    `json:"customer_name" binding:"required"`
-	CustomerPhone   string    `json:"customer_phone" binding:"required"`
-	FromRegionID    int64     `json:"from_region_id" binding:"required"`
-	FromDistrictID  int64     `json:"from_district_id" binding:"required"`
-	FromLatitude    *float64  `json:"from_latitude"`
-	FromLongitude   *float64  `json:"from_longitude"`
-	FromAddress     *string   `json:"from_address"`
-	ToRegionID      int64     `json:"to_region_id" binding:"required"`
-	ToDistrictID    int64     `json:"to_district_id" binding:"required"`
-	ToLatitude      *float64  `json:"to_latitude"`
-	ToLongitude     *float64  `json:"to_longitude"`
-	ToAddress       *string   `json:"to_address"`
-	PassengerCount  int       `json:"passenger_count" binding:"required,min=1,max=4"`
-	ScheduledDate   string    `json:"scheduled_date" binding:"required"` // DD.MM.YYYY
-	TimeRangeStart  string    `json:"time_range_start" binding:"required"`
-	TimeRangeEnd    string    `json:"time_range_end" binding:"required"`
-	Notes           string    `json:"notes"`
+	CustomerName   string   `json:"customer_name" validate:"required"`
+	CustomerPhone  string   `json:"customer_phone" validate:"required"`
+	FromRegionID   int64    `json:"from_region_id" validate:"required"`
+	FromDistrictID int64    `json:"from_district_id" validate:"required"`
+	FromLatitude   *float64 `json:"from_latitude"`
+	FromLongitude  *float64 `json:"from_longitude"`
+	FromAddress    *string  `json:"from_address"`
+	ToRegionID     int64    `json:"to_region_id" validate:"required"`
+	ToDistrictID   int64    `json:"to_district_id" validate:"required"`
+	ToLatitude     *float64 `json:"to_latitude"`
+	ToLongitude    *float64 `json:"to_longitude"`
+	ToAddress      *string  `json:"to_address"`
+	PassengerCount int      `json:"passenger_count" validate:"required,min=1,max=4"`
+	ScheduledDate  string   `json:"scheduled_date" validate:"required"` // DD.MM.YYYY
+	TimeRangeStart string   `json:"time_range_start" validate:"required"`
+	TimeRangeEnd   string   `json:"time_range_end" validate:"required"`
+	Notes          string   `json:"notes"`
+	PaymentMethod  string   `json:"payment_method" validate:"omitempty,oneof=cash card wallet"` // Defaults to cash
+	QuoteToken     string   `json:"quote_token"`                                                // From POST /orders/quote; books at the quoted price
 }
 
 // CreateDeliveryOrderRequest represents delivery order creation request
 type CreateDeliveryOrderRequest struct {
-	CustomerName    string   `json:"customer_name" binding:"required"`
-	CustomerPhone   string   `json:"customer_phone" binding:"required"`
-	RecipientPhone  string   `json:"recipient_phone" binding:"required"`
-	FromRegionID    int64    `json:"from_region_id" binding:"required"`
-	FromDistrictID  int64    `json:"from_district_id" binding:"required"`
-	FromLatitude    *float64 `json:"from_latitude"`
-	FromLongitude   *float64 `json:"from_longitude"`
-	FromAddress     *string  `json:"from_address"`
-	ToRegionID      int64    `json:"to_region_id" binding:"required"`
-	ToDistrictID    int64    `json:"to_district_id" binding:"required"`
-	ToLatitude      *float64 `json:"to_latitude"`
-	ToLongitude     *float64 `json:"to_longitude"`
-	ToAddress       *string  `json:"to_address"`
-	DeliveryType    string   `json:"delivery_type" binding:"required"`
-	ScheduledDate   string   `json:"scheduled_date" binding:"required"` // DD.MM.YYYY
-	TimeRangeStart  string   `json:"time_range_start" binding:"required"`
-	TimeRangeEnd    string   `json:"time_range_end" binding:"required"`
-	Notes           string   `json:"notes"`
+	CustomerName   string   `json:"customer_name" validate:"required"`
+	CustomerPhone  string   `json:"customer_phone" validate:"required"`
+	RecipientPhone string   `json:"recipient_phone" validate:"required"`
+	FromRegionID   int64    `json:"from_region_id" validate:"required"`
+	FromDistrictID int64    `json:"from_district_id" validate:"required"`
+	FromLatitude   *float64 `json:"from_latitude"`
+	FromLongitude  *float64 `json:"from_longitude"`
+	FromAddress    *string  `json:"from_address"`
+	ToRegionID     int64    `json:"to_region_id" validate:"required"`
+	ToDistrictID   int64    `json:"to_district_id" validate:"required"`
+	ToLatitude     *float64 `json:"to_latitude"`
+	ToLongitude    *float64 `json:"to_longitude"`
+	ToAddress      *string  `json:"to_address"`
+	DeliveryType   string   `json:"delivery_type" validate:"required"`
+	ScheduledDate  string   `json:"scheduled_date" validate:"required"` // DD.MM.YYYY
+	TimeRangeStart string   `json:"time_range_start" validate:"required"`
+	TimeRangeEnd   string   `json:"time_range_end" validate:"required"`
+	Notes          string   `json:"notes"`
+	PaymentMethod  string   `json:"payment_method" validate:"omitempty,oneof=cash card wallet"` // Defaults to cash
+	QuoteToken     string   `json:"quote_token"`                                                // From POST /orders/quote; books at the quoted price
+}
+
+// QuoteOrderRequest represents a price quote request for a taxi or delivery
+type QuoteOrderRequest struct {
+	OrderType      string   `json:"order_type" validate:"required,oneof=taxi delivery"`
+	FromRegionID   int64    `json:"from_region_id" validate:"required"`
+	FromDistrictID int64    `json:"from_district_id" validate:"required"`
+	FromLatitude   *float64 `json:"from_latitude"`
+	FromLongitude  *float64 `json:"from_longitude"`
+	ToRegionID     int64    `json:"to_region_id" validate:"required"`
+	ToDistrictID   int64    `json:"to_district_id" validate:"required"`
+	ToLatitude     *float64 `json:"to_latitude"`
+	ToLongitude    *float64 `json:"to_longitude"`
+	PassengerCount int      `json:"passenger_count" validate:"omitempty,min=1,max=4"` // Taxi only
+	ScheduledDate  string   `json:"scheduled_date" validate:"required"`               // DD.MM.YYYY
+	TimeRangeStart string   `json:"time_range_start" validate:"required"`
+}
+
+// BookTripRequest represents a customer booking seats on a shared trip
+type BookTripRequest struct {
+	CustomerName   string   `json:"customer_name" validate:"required"`
+	CustomerPhone  string   `json:"customer_phone" validate:"required"`
+	FromDistrictID int64    `json:"from_district_id" validate:"required"`
+	FromLatitude   *float64 `json:"from_latitude"`
+	FromLongitude  *float64 `json:"from_longitude"`
+	FromAddress    *string  `json:"from_address"`
+	ToDistrictID   int64    `json:"to_district_id" validate:"required"`
+	ToLatitude     *float64 `json:"to_latitude"`
+	ToLongitude    *float64 `json:"to_longitude"`
+	ToAddress      *string  `json:"to_address"`
+	Seats          int      `json:"seats" validate:"required,min=1,max=8"`
+	Notes          string   `json:"notes"`
+	PaymentMethod  string   `json:"payment_method" validate:"omitempty,oneof=cash card wallet"` // Defaults to cash
+}
+
+// CancelOrderRequest represents order cancellation request
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"required"`
 }
 
 // CreateTaxiOrder godoc
@@ -77,100 +115,53 @@ type CreateDeliveryOrderRequest struct {
 // @Success 201 {object} models.Order
 // @Failure 400 {object} map[string]string
 // @Router /orders/taxi [post]
-func (h *OrderHandler) CreateTaxiOrder(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-
-	var req CreateTaxiOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Parse date
-	scheduledDate, err := time.Parse("02.01.2006", req.ScheduledDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use DD.MM.YYYY"})
-		return
+func (h *OrderHandler) CreateTaxiOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	// Validate regions are different
-	if req.FromRegionID == req.ToRegionID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "From and To regions must be different"})
-		return
+	var req CreateTaxiOrderRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Calculate price
-	price, serviceFee, discount, finalPriceCalc, err := h.calculateTaxiPrice(req.FromRegionID, req.ToRegionID, req.PassengerCount)
+	scheduledDate, err := parseScheduledDate(req.ScheduledDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Create order
-	var order models.Order
-	acceptDeadline := time.Now().Add(5 * time.Minute)
-	
-	var notes *string
-	if req.Notes != "" {
-		notes = &req.Notes
+		return err
 	}
-	
-	err = database.DB.QueryRow(`
-		INSERT INTO orders (
-			user_id, order_type, status, customer_name, customer_phone,
-			from_region_id, from_district_id, from_latitude, from_longitude, from_address,
-			to_region_id, to_district_id, to_latitude, to_longitude, to_address,
-			passenger_count, scheduled_date, time_range_start, time_range_end,
-			price, service_fee, discount_percentage, final_price, notes, accept_deadline
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
-		RETURNING id, created_at, updated_at
-	`, userID, models.OrderTypeTaxi, models.OrderStatusPending,
-		req.CustomerName, req.CustomerPhone, 
-		req.FromRegionID, req.FromDistrictID, req.FromLatitude, req.FromLongitude, req.FromAddress,
-		req.ToRegionID, req.ToDistrictID, req.ToLatitude, req.ToLongitude, req.ToAddress,
-		req.PassengerCount, scheduledDate,
-		req.TimeRangeStart, req.TimeRangeEnd, price, serviceFee, discount, finalPriceCalc,
-		notes, acceptDeadline,
-	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
 
+	order, err := h.orders.CreateTaxiOrder(services.CreateTaxiOrderInput{
+		UserID:        userID,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		Route: services.Route{
+			FromRegionID:   req.FromRegionID,
+			FromDistrictID: req.FromDistrictID,
+			FromLatitude:   req.FromLatitude,
+			FromLongitude:  req.FromLongitude,
+			FromAddress:    req.FromAddress,
+			ToRegionID:     req.ToRegionID,
+			ToDistrictID:   req.ToDistrictID,
+			ToLatitude:     req.ToLatitude,
+			ToLongitude:    req.ToLongitude,
+			ToAddress:      req.ToAddress,
+		},
+		PassengerCount: req.PassengerCount,
+		Schedule: services.Schedule{
+			Date:           scheduledDate,
+			TimeRangeStart: req.TimeRangeStart,
+			TimeRangeEnd:   req.TimeRangeEnd,
+		},
+		Notes:         req.Notes,
+		PaymentMethod: req.PaymentMethod,
+		QuoteToken:    req.QuoteToken,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
-		return
-	}
-
-	// Populate order details for response
-	order.UserID = userID
-	order.OrderType = models.OrderTypeTaxi
-	order.Status = models.OrderStatusPending
-	order.CustomerName = req.CustomerName
-	order.CustomerPhone = req.CustomerPhone
-	order.FromRegionID = req.FromRegionID
-	order.FromDistrictID = req.FromDistrictID
-	order.FromLatitude = req.FromLatitude
-	order.FromLongitude = req.FromLongitude
-	order.FromAddress = req.FromAddress
-	order.ToRegionID = req.ToRegionID
-	order.ToDistrictID = req.ToDistrictID
-	order.ToLatitude = req.ToLatitude
-	order.ToLongitude = req.ToLongitude
-	order.ToAddress = req.ToAddress
-	passengerCount := int64(req.PassengerCount)
-	order.PassengerCount = &passengerCount
-	order.ScheduledDate = scheduledDate
-	order.TimeRangeStart = req.TimeRangeStart
-	order.TimeRangeEnd = req.TimeRangeEnd
-	order.Price = price
-	order.ServiceFee = serviceFee
-	order.DiscountPercentage = discount
-	order.FinalPrice = finalPriceCalc
-	if req.Notes != "" {
-		order.Notes = &req.Notes
+		return respondError(c, err)
 	}
 
-	// TODO: Send notification to all drivers
-	go h.notifyDriversNewOrder(order.ID, models.OrderTypeTaxi)
-
-	c.JSON(http.StatusCreated, order)
+	return c.Status(fiber.StatusCreated).JSON(order)
 }
 
 // CreateDeliveryOrder godoc
@@ -184,71 +175,162 @@ func (h *OrderHandler) CreateTaxiOrder(c *gin.Context) {
 // @Success 201 {object} models.Order
 // @Failure 400 {object} map[string]string
 // @Router /orders/delivery [post]
-func (h *OrderHandler) CreateDeliveryOrder(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
+func (h *OrderHandler) CreateDeliveryOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 
 	var req CreateDeliveryOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	scheduledDate, err := parseScheduledDate(req.ScheduledDate)
+	if err != nil {
+		return err
 	}
 
-	// Parse date
-	scheduledDate, err := time.Parse("02.01.2006", req.ScheduledDate)
+	order, err := h.orders.CreateDeliveryOrder(services.CreateDeliveryOrderInput{
+		UserID:         userID,
+		CustomerName:   req.CustomerName,
+		CustomerPhone:  req.CustomerPhone,
+		RecipientPhone: req.RecipientPhone,
+		Route: services.Route{
+			FromRegionID:   req.FromRegionID,
+			FromDistrictID: req.FromDistrictID,
+			FromLatitude:   req.FromLatitude,
+			FromLongitude:  req.FromLongitude,
+			FromAddress:    req.FromAddress,
+			ToRegionID:     req.ToRegionID,
+			ToDistrictID:   req.ToDistrictID,
+			ToLatitude:     req.ToLatitude,
+			ToLongitude:    req.ToLongitude,
+			ToAddress:      req.ToAddress,
+		},
+		DeliveryType: req.DeliveryType,
+		Schedule: services.Schedule{
+			Date:           scheduledDate,
+			TimeRangeStart: req.TimeRangeStart,
+			TimeRangeEnd:   req.TimeRangeEnd,
+		},
+		Notes:         req.Notes,
+		PaymentMethod: req.PaymentMethod,
+		QuoteToken:    req.QuoteToken,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use DD.MM.YYYY"})
-		return
+		return respondError(c, err)
 	}
 
-	// Validate regions are different
-	if req.FromRegionID == req.ToRegionID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "From and To regions must be different"})
-		return
+	return c.Status(fiber.StatusCreated).JSON(order)
+}
+
+// QuoteOrder godoc
+// @Summary Get a price quote
+// @Description Price a taxi or delivery trip without ordering it. The quote token books the same trip at the quoted price until it expires.
+// @Tags Orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body QuoteOrderRequest true "Trip details"
+// @Success 200 {object} services.Quote
+// @Failure 400 {object} map[string]string
+// @Router /orders/quote [post]
+func (h *OrderHandler) QuoteOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	var req QuoteOrderRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
 	}
 
-	// Calculate price (same as taxi base price)
-	price, serviceFee, _, finalPrice, err := h.calculateTaxiPrice(req.FromRegionID, req.ToRegionID, 1)
+	scheduledDate, err := parseScheduledDate(req.ScheduledDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return err
 	}
 
-	// Create order
-	var order models.Order
-	acceptDeadline := time.Now().Add(5 * time.Minute)
-	
-	var notes *string
-	if req.Notes != "" {
-		notes = &req.Notes
+	quote, err := h.orders.Quote(userID, services.PriceRequest{
+		OrderType: models.OrderType(req.OrderType),
+		Route: services.Route{
+			FromRegionID:   req.FromRegionID,
+			FromDistrictID: req.FromDistrictID,
+			FromLatitude:   req.FromLatitude,
+			FromLongitude:  req.FromLongitude,
+			ToRegionID:     req.ToRegionID,
+			ToDistrictID:   req.ToDistrictID,
+			ToLatitude:     req.ToLatitude,
+			ToLongitude:    req.ToLongitude,
+		},
+		PassengerCount: req.PassengerCount,
+		Schedule: services.Schedule{
+			Date:           scheduledDate,
+			TimeRangeStart: req.TimeRangeStart,
+		},
+	})
+	if err != nil {
+		return respondError(c, err)
 	}
-	
-	err = database.DB.QueryRow(`
-		INSERT INTO orders (
-			user_id, order_type, status, customer_name, customer_phone, recipient_phone,
-			from_region_id, from_district_id, from_latitude, from_longitude, from_address,
-			to_region_id, to_district_id, to_latitude, to_longitude, to_address,
-			delivery_type, scheduled_date, time_range_start, time_range_end,
-			price, service_fee, discount_percentage, final_price, notes, accept_deadline
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
-		RETURNING id, created_at, updated_at
-	`, userID, models.OrderTypeDelivery, models.OrderStatusPending,
-		req.CustomerName, req.CustomerPhone, req.RecipientPhone,
-		req.FromRegionID, req.FromDistrictID, req.FromLatitude, req.FromLongitude, req.FromAddress,
-		req.ToRegionID, req.ToDistrictID, req.ToLatitude, req.ToLongitude, req.ToAddress,
-		req.DeliveryType, scheduledDate, req.TimeRangeStart, req.TimeRangeEnd,
-		price, serviceFee, 0, finalPrice,
-		notes, acceptDeadline,
-	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
 
+	return c.Status(fiber.StatusOK).JSON(quote)
+}
+
+// BookTrip godoc
+// @Summary Book seats on a shared trip
+// @Description Create a taxi order on a driver's published trip. The order is accepted by the trip's driver straight away and priced at the trip's price per seat plus the route's service fee.
+// @Tags Trips
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param request body BookTripRequest true "Booking details"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /trips/{id}/book [post]
+func (h *OrderHandler) BookTrip(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	tripID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
-		return
+		return err
 	}
 
-	// TODO: Send notification to all drivers
-	go h.notifyDriversNewOrder(order.ID, models.OrderTypeDelivery)
+	var req BookTripRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
 
-	c.JSON(http.StatusCreated, order)
+	order, err := h.orders.BookTrip(services.BookTripInput{
+		UserID:        userID,
+		TripID:        tripID,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		Route: services.Route{
+			FromDistrictID: req.FromDistrictID,
+			FromLatitude:   req.FromLatitude,
+			FromLongitude:  req.FromLongitude,
+			FromAddress:    req.FromAddress,
+			ToDistrictID:   req.ToDistrictID,
+			ToLatitude:     req.ToLatitude,
+			ToLongitude:    req.ToLongitude,
+			ToAddress:      req.ToAddress,
+		},
+		Seats:         req.Seats,
+		Notes:         req.Notes,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(order)
 }
 
 // GetMyOrders godoc
@@ -261,56 +343,21 @@ func (h *OrderHandler) CreateDeliveryOrder(c *gin.Context) {
 // @Param type query string false "Filter by type (taxi/delivery)"
 // @Success 200 {array} models.Order
 // @Router /orders/my [get]
-func (h *OrderHandler) GetMyOrders(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	status := c.Query("status")
-	orderType := c.Query("type")
-
-	query := "SELECT * FROM orders WHERE user_id = $1"
-	args := []interface{}{userID}
-
-	if status != "" {
-		query += " AND status = $2"
-		args = append(args, status)
-	}
-	if orderType != "" {
-		if status != "" {
-			query += " AND order_type = $3"
-		} else {
-			query += " AND order_type = $2"
-		}
-		args = append(args, orderType)
+func (h *OrderHandler) GetMyOrders(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	query += " ORDER BY created_at DESC"
-
-	rows, err := database.DB.Query(query, args...)
+	orders, err := h.orders.ListUserOrders(userID, services.OrderFilter{
+		Status: c.Query("status"),
+		Type:   c.Query("type"),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
-		return
-	}
-	defer rows.Close()
-
-	orders := []models.Order{}
-	for rows.Next() {
-		var order models.Order
-		err := rows.Scan(
-			&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-			&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-			&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-			&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-			&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-			&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-			&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-			&order.CreatedAt, &order.UpdatedAt,
-		)
-		if err != nil {
-			continue
-		}
-		orders = append(orders, order)
+		return respondError(c, err)
 	}
 
-	c.JSON(http.StatusOK, orders)
+	return c.Status(fiber.StatusOK).JSON(orders)
 }
 
 // GetOrderByID godoc
@@ -321,204 +368,103 @@ func (h *OrderHandler) GetMyOrders(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Order ID"
 // @Success 200 {object} models.Order
+// @Failure 404 {object} map[string]string
 // @Router /orders/{id} [get]
-func (h *OrderHandler) GetOrderByID(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
+func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
 	userRole, _ := middleware.GetUserRole(c)
-	orderID := c.Param("id")
-
-	var order models.Order
-	query := "SELECT * FROM orders WHERE id = $1"
-	
-	// Regular users can only see their own orders
-	if userRole == models.RoleUser {
-		query += " AND user_id = $2"
-		err := database.DB.QueryRow(query, orderID, userID).Scan(
-			&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-			&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-			&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-			&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-			&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-			&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-			&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-			&order.CreatedAt, &order.UpdatedAt,
-		)
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-			return
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-			return
-		}
-	} else {
-		// Drivers and admins can see any order
-		err := database.DB.QueryRow(query, orderID).Scan(
-			&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
-			&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
-			&order.FromRegionID, &order.FromDistrictID, &order.ToRegionID, &order.ToDistrictID,
-			&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate,
-			&order.TimeRangeStart, &order.TimeRangeEnd, &order.Price, &order.ServiceFee,
-			&order.DiscountPercentage, &order.FinalPrice, &order.Notes, &order.CancellationReason,
-			&order.AcceptedAt, &order.AcceptDeadline, &order.CompletedAt, &order.CancelledAt,
-			&order.CreatedAt, &order.UpdatedAt,
-		)
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-			return
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-			return
-		}
+
+	orderID, err := paramID(c, "id")
+	if err != nil {
+		return err
 	}
 
-	c.JSON(http.StatusOK, order)
+	order, err := h.orders.GetOrder(userID, userRole, orderID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
 }
 
-// CancelOrder godoc
-// @Summary Cancel an order
-// @Description Cancel a pending or accepted order
+// GetOrderTimeline godoc
+// @Summary Get order timeline
+// @Description Get the audit trail of an order: creation, status changes, re-dispatches and refunds, with who made each change
 // @Tags Orders
 // @Security BearerAuth
-// @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
-// @Param request body map[string]string true "Cancellation reason"
-// @Success 200 {object} map[string]string
-// @Router /orders/{id}/cancel [post]
-func (h *OrderHandler) CancelOrder(c *gin.Context) {
-	userID, _ := middleware.GetUserID(c)
-	orderID := c.Param("id")
-
-	var req struct {
-		Reason string `json:"reason" binding:"required"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+// @Success 200 {array} models.OrderEvent
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/timeline [get]
+func (h *OrderHandler) GetOrderTimeline(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
+	userRole, _ := middleware.GetUserRole(c)
 
-	// Get order details
-	var order models.Order
-	err := database.DB.QueryRow(`
-		SELECT id, user_id, driver_id, status, service_fee
-		FROM orders WHERE id = $1 AND user_id = $2
-	`, orderID, userID).Scan(&order.ID, &order.UserID, &order.DriverID, &order.Status, &order.ServiceFee)
-	
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-		return
-	}
+	orderID, err := paramID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return err
 	}
 
-	// Can only cancel pending or accepted orders
-	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusAccepted {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot cancel order in current status"})
-		return
-	}
-
-	// Update order status
-	_, err = database.DB.Exec(`
-		UPDATE orders SET status = $1, cancellation_reason = $2, cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-	`, models.OrderStatusCancelled, req.Reason, orderID)
+	events, err := h.orders.Timeline(userID, userRole, orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel order"})
-		return
+		return respondError(c, err)
 	}
 
-	// Refund driver if order was accepted
-	if order.DriverID != nil {
-		_, err = database.DB.Exec(`
-			UPDATE drivers SET balance = balance + $1 WHERE id = $2
-		`, order.ServiceFee, *order.DriverID)
-		if err != nil {
-			// Log error but don't fail the request
-			fmt.Printf("Failed to refund driver: %v\n", err)
-		}
-
-		// Create transaction record
-		database.DB.Exec(`
-			INSERT INTO transactions (driver_id, order_id, amount, type, description)
-			VALUES ($1, $2, $3, $4, $5)
-		`, *order.DriverID, order.ID, order.ServiceFee, "credit", "Refund for cancelled order")
-	}
-
-	// TODO: Send notification to telegram group
-	// TODO: Notify driver if assigned
-
-	c.JSON(http.StatusOK, gin.H{"message": "Order cancelled successfully"})
+	return c.Status(fiber.StatusOK).JSON(events)
 }
 
-// Helper functions
-
-func (h *OrderHandler) calculateTaxiPrice(fromRegionID, toRegionID int64, passengerCount int) (float64, float64, float64, float64, error) {
-	// Get pricing for route
-	var pricing models.Pricing
-	err := database.DB.QueryRow(`
-		SELECT base_price, price_per_person, service_fee
-		FROM pricing WHERE from_region_id = $1 AND to_region_id = $2
-	`, fromRegionID, toRegionID).Scan(&pricing.BasePrice, &pricing.PricePerPerson, &pricing.ServiceFee)
-	
-	if err == sql.ErrNoRows {
-		return 0, 0, 0, 0, fmt.Errorf("pricing not configured for this route")
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Description Cancel a pending or accepted order; the cancellation policy decides the fee owed
+// @Tags Orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body CancelOrderRequest true "Cancellation reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
 	}
+
+	orderID, err := paramID(c, "id")
 	if err != nil {
-		return 0, 0, 0, 0, fmt.Errorf("database error")
+		return err
 	}
 
-	// Calculate base price
-	basePrice := pricing.BasePrice + (pricing.PricePerPerson * float64(passengerCount))
+	var req CancelOrderRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
 
-	// Get discount
-	var discount float64
-	err = database.DB.QueryRow(`
-		SELECT discount_percentage FROM discounts WHERE passenger_count = $1
-	`, passengerCount).Scan(&discount)
+	outcome, err := h.orders.CancelOrder(userID, orderID, req.Reason)
 	if err != nil {
-		discount = 0
+		return respondError(c, err)
 	}
 
-	// Apply discount
-	discountAmount := basePrice * (discount / 100)
-	priceAfterDiscount := basePrice - discountAmount
-
-	// Calculate service fee
-	serviceFee := priceAfterDiscount * (pricing.ServiceFee / 100)
-
-	// Final price
-	finalPrice := priceAfterDiscount + serviceFee
-
-	return basePrice, serviceFee, discount, finalPrice, nil
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":          "Order cancelled successfully",
+		"cancellation_fee": outcome.CustomerFee,
+	})
 }
 
-func (h *OrderHandler) notifyDriversNewOrder(orderID int64, orderType models.OrderType) {
-	// Get all active drivers
-	rows, err := database.DB.Query(`
-		SELECT u.id FROM users u
-		INNER JOIN drivers d ON u.id = d.user_id
-		WHERE u.role = $1 AND d.status = 'approved' AND d.is_active = true AND u.is_blocked = false
-	`, models.RoleDriver)
+// parseScheduledDate parses a DD.MM.YYYY date from a request
+func parseScheduledDate(value string) (time.Time, error) {
+	date, err := time.Parse("02.01.2006", value)
 	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	title := "New Order Available"
-	message := fmt.Sprintf("A new %s order is available. Check your orders page.", orderType)
-
-	for rows.Next() {
-		var driverUserID int64
-		if rows.Scan(&driverUserID) == nil {
-			// Create notification
-			database.DB.Exec(`
-				INSERT INTO notifications (user_id, title, message, type, related_id)
-				VALUES ($1, $2, $3, $4, $5)
-			`, driverUserID, title, message, "new_order", orderID)
-		}
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid date format, use DD.MM.YYYY")
 	}
+	return date, nil
 }
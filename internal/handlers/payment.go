@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"taxi-service/internal/middleware"
+	"taxi-service/internal/models"
+	"taxi-service/internal/payments"
+	"taxi-service/internal/services"
+)
+
+// PaymentHandler handles driver top-ups, order fare payments and payment
+// provider callbacks
+type PaymentHandler struct {
+	topUps   *services.TopUpService
+	payments *services.PaymentService
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(topUps *services.TopUpService, payments *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{topUps: topUps, payments: payments}
+}
+
+// CreateTopUpRequest represents a driver starting a top-up
+type CreateTopUpRequest struct {
+	Provider string  `json:"provider" validate:"required"`
+	Amount   float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// PayOrderRequest represents a customer paying a card order
+type PayOrderRequest struct {
+	Provider string `json:"provider" validate:"required"`
+}
+
+// DisputePaymentRequest represents a customer contesting an order's fare
+type DisputePaymentRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ResolvePaymentRequest represents an admin settling an order's payment
+type ResolvePaymentRequest struct {
+	Status string `json:"status" validate:"required,oneof=paid waived refunded"`
+	Note   string `json:"note"`
+}
+
+// CreateTopUp godoc
+// @Summary Start a wallet top-up
+// @Description Create a pending top-up and get the provider's checkout link. The balance is credited once the provider confirms the payment.
+// @Tags Driver
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateTopUpRequest true "Provider and amount"
+// @Success 201 {object} services.StartedTopUp
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /driver/wallet/top-ups [post]
+func (h *PaymentHandler) CreateTopUp(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	var req CreateTopUpRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	started, err := h.topUps.Start(userID, req.Provider, req.Amount)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(started)
+}
+
+// GetTopUps godoc
+// @Summary Get my top-ups
+// @Description Get the driver's top-ups, newest first
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.TopUp
+// @Router /driver/wallet/top-ups [get]
+func (h *PaymentHandler) GetTopUps(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	topUps, err := h.topUps.List(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(topUps)
+}
+
+// GetTopUp godoc
+// @Summary Get top-up
+// @Description Get one of the driver's top-ups, e.g. to poll its status after checkout
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Top-up ID"
+// @Success 200 {object} models.TopUp
+// @Failure 404 {object} map[string]string
+// @Router /driver/wallet/top-ups/{id} [get]
+func (h *PaymentHandler) GetTopUp(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	topUpID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	topUp, err := h.topUps.Get(userID, topUpID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(topUp)
+}
+
+// GetTopUpProviders godoc
+// @Summary Get top-up providers
+// @Description Get the payment providers drivers can top up through and customers can pay card orders with
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string][]string
+// @Router /driver/wallet/top-up-providers [get]
+// @Router /payment-providers [get]
+func (h *PaymentHandler) GetTopUpProviders(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"providers": h.topUps.Providers()})
+}
+
+// Callback godoc
+// @Summary Payment provider callback
+// @Description Webhook called by a payment provider. Each provider signs its requests and gets replies in its own format.
+// @Tags Payments
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param provider path string true "Provider (click/payme/fake)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /payments/{provider}/callback [post]
+func (h *PaymentHandler) Callback(c *fiber.Ctx) error {
+	res, err := h.topUps.HandleCallback(c.Params("provider"), payments.Request{
+		Header: func(key string) string { return c.Get(key) },
+		Body:   c.Body(),
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(res.Status).JSON(res.Body)
+}
+
+// PayOrder godoc
+// @Summary Pay an order by card
+// @Description Start a card payment of a card order's fare and get the provider's checkout link. The order is marked paid once the provider confirms the payment.
+// @Tags Orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body PayOrderRequest true "Provider"
+// @Success 201 {object} services.StartedTopUp
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/pay [post]
+func (h *PaymentHandler) PayOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	orderID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req PayOrderRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	started, err := h.topUps.PayOrder(userID, orderID, req.Provider)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(started)
+}
+
+// DisputePayment godoc
+// @Summary Dispute an order's fare
+// @Description Contest the fare of a completed order; an admin resolves the dispute
+// @Tags Orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body DisputePaymentRequest true "Reason"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/payment/dispute [post]
+func (h *PaymentHandler) DisputePayment(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	orderID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req DisputePaymentRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	order, err := h.payments.Dispute(userID, orderID, req.Reason)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// GetWallet godoc
+// @Summary Get my wallet
+// @Description Get the customer's wallet balance. Refunded fares are credited here and can pay wallet orders.
+// @Tags Orders
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} services.CustomerWallet
+// @Router /wallet [get]
+func (h *PaymentHandler) GetWallet(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	wallet, err := h.payments.Wallet(userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(wallet)
+}
+
+// GetOutstandingPayments godoc
+// @Summary Get outstanding payments
+// @Description Get completed orders whose fare is unpaid or disputed and cancelled orders whose cancellation fee is owed (admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param method query string false "Filter by payment method (cash/card/wallet)"
+// @Success 200 {array} models.Order
+// @Failure 400 {object} map[string]string
+// @Router /admin/payments/outstanding [get]
+func (h *PaymentHandler) GetOutstandingPayments(c *fiber.Ctx) error {
+	orders, err := h.payments.ListOutstanding(c.Query("method"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(orders)
+}
+
+// ResolvePayment godoc
+// @Summary Resolve an order's payment
+// @Description Record an unpaid or disputed fare or an owed cancellation fee as paid, waive it, or refund a fare paid by card or wallet to the customer's wallet (admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body ResolvePaymentRequest true "New payment status and note"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/orders/{id}/payment [post]
+func (h *PaymentHandler) ResolvePayment(c *fiber.Ctx) error {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	orderID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req ResolvePaymentRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	order, err := h.payments.Resolve(adminID, orderID, models.PaymentStatus(req.Status), req.Note)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"taxi-service/internal/middleware"
+	"taxi-service/internal/services"
+)
+
+// TripHandler handles the shared intercity trips drivers publish
+type TripHandler struct {
+	trips *services.TripService
+}
+
+// NewTripHandler creates a new trip handler
+func NewTripHandler(trips *services.TripService) *TripHandler {
+	return &TripHandler{trips: trips}
+}
+
+// PublishTripRequest represents a driver publishing a trip
+type PublishTripRequest struct {
+	FromRegionID   int64   `json:"from_region_id" validate:"required"`
+	ToRegionID     int64   `json:"to_region_id" validate:"required"`
+	ScheduledDate  string  `json:"scheduled_date" validate:"required"` // DD.MM.YYYY
+	DepartureStart string  `json:"departure_start" validate:"required"`
+	DepartureEnd   string  `json:"departure_end" validate:"required"`
+	Seats          int     `json:"seats" validate:"required,min=1,max=8"`
+	PricePerSeat   float64 `json:"price_per_seat" validate:"required,gt=0"`
+	Notes          string  `json:"notes"`
+}
+
+// CancelTripRequest represents a driver calling off a trip
+type CancelTripRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SearchTrips godoc
+// @Summary Search shared trips
+// @Description Find open trips drivers have published on a region pair and date with enough seats left, earliest departure first
+// @Tags Trips
+// @Security BearerAuth
+// @Produce json
+// @Param from_region_id query int true "From region"
+// @Param to_region_id query int true "To region"
+// @Param date query string true "Date (DD.MM.YYYY)"
+// @Param seats query int false "Seats needed (default 1)"
+// @Success 200 {array} services.TripOffer
+// @Failure 400 {object} map[string]string
+// @Router /trips/search [get]
+func (h *TripHandler) SearchTrips(c *fiber.Ctx) error {
+	date, err := parseScheduledDate(c.Query("date"))
+	if err != nil {
+		return err
+	}
+
+	offers, err := h.trips.Search(services.TripSearch{
+		FromRegionID: int64(c.QueryInt("from_region_id")),
+		ToRegionID:   int64(c.QueryInt("to_region_id")),
+		Date:         date,
+		Seats:        c.QueryInt("seats"),
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(offers)
+}
+
+// GetTrip godoc
+// @Summary Get a shared trip
+// @Description Get an open trip with its seats left, price per seat and driver
+// @Tags Trips
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {object} services.TripOffer
+// @Failure 404 {object} map[string]string
+// @Router /trips/{id} [get]
+func (h *TripHandler) GetTrip(c *fiber.Ctx) error {
+	tripID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	offer, err := h.trips.Offer(tripID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(offer)
+}
+
+// PublishTrip godoc
+// @Summary Publish a trip
+// @Description Offer seats on an intercity ride. Pending orders on the same region pair and date can then be booked onto it until its seats are taken.
+// @Tags Driver
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PublishTripRequest true "Trip details"
+// @Success 201 {object} models.Trip
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /driver/trips [post]
+func (h *TripHandler) PublishTrip(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	var req PublishTripRequest
+	if err := parseAndValidateJSON(c, &req); err != nil {
+		return err
+	}
+
+	scheduledDate, err := parseScheduledDate(req.ScheduledDate)
+	if err != nil {
+		return err
+	}
+
+	trip, err := h.trips.Publish(userID, services.PublishTripInput{
+		FromRegionID:   req.FromRegionID,
+		ToRegionID:     req.ToRegionID,
+		Date:           scheduledDate,
+		DepartureStart: req.DepartureStart,
+		DepartureEnd:   req.DepartureEnd,
+		Seats:          req.Seats,
+		PricePerSeat:   req.PricePerSeat,
+		Notes:          req.Notes,
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(trip)
+}
+
+// GetMyTrips godoc
+// @Summary Get my trips
+// @Description Get the trips the driver has published
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status (open/closed/cancelled)"
+// @Success 200 {array} models.Trip
+// @Router /driver/trips [get]
+func (h *TripHandler) GetMyTrips(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	trips, err := h.trips.List(userID, c.Query("status"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(trips)
+}
+
+// GetMyTrip godoc
+// @Summary Get trip
+// @Description Get one of the driver's trips with its seats and the orders booked on it
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {object} services.TripDetails
+// @Failure 404 {object} map[string]string
+// @Router /driver/trips/{id} [get]
+func (h *TripHandler) GetMyTrip(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	tripID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	trip, err := h.trips.Get(userID, tripID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(trip)
+}
+
+// GetTripCandidates godoc
+// @Summary Get orders that fit a trip
+// @Description Get pending orders on the trip's region pair and date whose pickup time falls in its departure window and whose passengers fit in the seats left
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.Order
+// @Failure 404 {object} map[string]string
+// @Router /driver/trips/{id}/candidates [get]
+func (h *TripHandler) GetTripCandidates(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	tripID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	orders, err := h.trips.Candidates(userID, tripID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(orders)
+}
+
+// AttachOrder godoc
+// @Summary Book an order onto a trip
+// @Description Accept a pending order onto one of the driver's open trips. The service fee is charged as for any accepted order and the order's passengers take seats on the trip.
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param order_id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /driver/trips/{id}/orders/{order_id} [post]
+func (h *TripHandler) AttachOrder(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	tripID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+	orderID, err := paramID(c, "order_id")
+	if err != nil {
+		return err
+	}
+
+	order, err := h.trips.Attach(userID, tripID, orderID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// CloseTrip godoc
+// @Summary Close a trip
+// @Description Stop a trip from taking more orders, e.g. once the car has left. Orders already on it are unaffected.
+// @Tags Driver
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {object} models.Trip
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /driver/trips/{id}/close [post]
+func (h *TripHandler) CloseTrip(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	tripID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	trip, err := h.trips.Close(userID, tripID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(trip)
+}
+
+// CancelTrip godoc
+// @Summary Cancel a trip
+// @Description Call off a trip before anyone is picked up. Each order on it is released back to pending on its own terms: the service fee is refunded less the release penalty for how close pickup is.
+// @Tags Driver
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param request body CancelTripRequest false "Reason"
+// @Success 200 {object} services.TripCancellation
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /driver/trips/{id}/cancel [post]
+func (h *TripHandler) CancelTrip(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	tripID, err := paramID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req CancelTripRequest
+	if len(c.Body()) > 0 {
+		if err := parseAndValidateJSON(c, &req); err != nil {
+			return err
+		}
+	}
+
+	result, err := h.trips.Cancel(userID, tripID, req.Reason)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
@@ -0,0 +1,248 @@
+// Package jobs runs background work from a queue kept in Postgres. Workers
+// claim due jobs with FOR UPDATE SKIP LOCKED, so any number of workers across
+// replicas share the queue without running the same job twice at once. A
+// failing job is retried with a growing delay until it runs out of attempts.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// cancelGrace is how long Stop waits for cancelled jobs to return before it
+// abandons their workers
+const cancelGrace = 5 * time.Second
+
+// Handler runs one job of a kind. The context is cancelled when the job's
+// lease runs out or shutdown stops waiting for it.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Queue enqueues jobs and runs them on a pool of workers
+type Queue struct {
+	jobs     *repository.JobRepository
+	cfg      *config.JobsConfig
+	handlers map[string]Handler
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	// ctx is cancelled when Stop gives up waiting, aborting running handlers
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewQueue creates a queue; register handlers, then Start it
+func NewQueue(db repository.Querier, cfg *config.JobsConfig) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		jobs:     repository.NewJobRepository(db),
+		cfg:      cfg,
+		handlers: map[string]Handler{},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register sets the handler for a kind of job. Call it before Start.
+func (q *Queue) Register(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// EnqueueTx stores a job to run as soon as a worker is free. Given the
+// transaction of the change that needs the job, the job exists exactly when
+// that change commits; given the database it is queued right away. The
+// payload is stored as JSON. Call Wake once the transaction has committed.
+func (q *Queue) EnqueueTx(tx repository.Querier, kind string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s payload: %w", kind, err)
+	}
+	maxAttempts := q.cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if _, err := repository.NewJobRepository(tx).Enqueue(kind, data, maxAttempts); err != nil {
+		return fmt.Errorf("enqueue %s: %w", kind, err)
+	}
+	return nil
+}
+
+// Wake lets an idle worker of this process look for due jobs without waiting
+// for the next poll
+func (q *Queue) Wake() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start launches the workers
+func (q *Queue) Start() {
+	q.startOnce.Do(func() {
+		workers := q.cfg.Workers
+		if workers < 1 {
+			log.Printf("Jobs: queue disabled (%d workers)", workers)
+			return
+		}
+		for i := 0; i < workers; i++ {
+			q.wg.Add(1)
+			go q.work()
+		}
+		log.Printf("Jobs: started %d workers", workers)
+	})
+}
+
+// Stop stops the workers from claiming jobs and waits for running ones to
+// finish, or until ctx is done. Jobs still running then are cancelled and
+// their workers get cancelGrace to return; their leases run out and another
+// worker retries them. Stop only returns nil once every worker has returned,
+// so the database can be closed safely after it.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.stopOnce.Do(func() {
+		close(q.stop)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		log.Println("Jobs: drained")
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Println("Jobs: running jobs did not finish in time, cancelling them")
+	q.cancel()
+	select {
+	case <-done:
+		return fmt.Errorf("running jobs cancelled: %w", ctx.Err())
+	case <-time.After(cancelGrace):
+		return fmt.Errorf("workers abandoned still running after cancellation: %w", ctx.Err())
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		// Run jobs back to back while any are due
+		for q.runNext() {
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job; it reports whether there was one
+func (q *Queue) runNext() bool {
+	job, err := q.jobs.ClaimNext(q.cfg.Lease())
+	if errors.Is(err, repository.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Jobs: failed to claim a job: %v", err)
+		return false
+	}
+
+	// A job whose worker keeps dying is claimed again when its lease runs
+	// out; give up on it rather than crash every worker in turn
+	if job.Attempts > job.MaxAttempts {
+		q.finish(job, errors.New("lease ran out on the last attempt"))
+		return true
+	}
+
+	q.finish(job, q.run(job))
+	return true
+}
+
+func (q *Queue) run(job *models.Job) (err error) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	ctx, cancel := context.WithTimeout(q.ctx, q.cfg.Lease())
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+// finish deletes a job that ran, or schedules its retry, or gives up on it
+func (q *Queue) finish(job *models.Job, runErr error) {
+	var err error
+	switch {
+	case runErr == nil:
+		err = q.jobs.Complete(job.ID, job.Attempts)
+	case job.Attempts >= job.MaxAttempts:
+		log.Printf("Jobs: %s job %d failed for good after %d attempts: %v", job.Kind, job.ID, job.Attempts, runErr)
+		err = q.jobs.Fail(job.ID, job.Attempts, runErr.Error())
+	default:
+		delay := q.retryDelay(job.Attempts)
+		log.Printf("Jobs: %s job %d failed (attempt %d/%d), retrying in %s: %v", job.Kind, job.ID, job.Attempts, job.MaxAttempts, delay, runErr)
+		err = q.jobs.Retry(job.ID, job.Attempts, delay, runErr.Error())
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Jobs: %s job %d was taken over by another worker after its lease ran out", job.Kind, job.ID)
+		return
+	}
+	if err != nil {
+		log.Printf("Jobs: failed to record the outcome of %s job %d: %v", job.Kind, job.ID, err)
+	}
+}
+
+// retryDelay doubles the backoff with every failed attempt, up to an hour
+func (q *Queue) retryDelay(attempt int) time.Duration {
+	delay := q.cfg.RetryBackoff()
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+func (q *Queue) pollInterval() time.Duration {
+	if interval := q.cfg.PollInterval(); interval > 0 {
+		return interval
+	}
+	return time.Second
+}
@@ -0,0 +1,78 @@
+// Package lifecycle runs the server until the process is told to stop, then
+// shuts its parts down one after another within a shared deadline: first
+// whatever takes in new work, then the workers, and the database pool last.
+package lifecycle
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle owns the shutdown of the application
+type Lifecycle struct {
+	timeout time.Duration
+	hooks   []hook
+}
+
+// New creates a lifecycle whose shutdown may take up to timeout in total
+func New(timeout time.Duration) *Lifecycle {
+	return &Lifecycle{timeout: timeout}
+}
+
+// OnShutdown adds a step to the shutdown. Steps run in the order they were
+// added; each gets the context of the shared deadline and must return when it
+// is done, without waiting on anything past the deadline.
+func (l *Lifecycle) OnShutdown(name string, stop func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, hook{name: name, stop: stop})
+}
+
+// Run starts serve and blocks until SIGINT or SIGTERM arrives or serve fails,
+// then shuts down. A second signal while shutting down kills the process at
+// once. It returns the error serve failed with, if any.
+func (l *Lifecycle) Run(serve func() error) error {
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	var err error
+	select {
+	case <-signals.Done():
+		log.Println("Shutdown signal received")
+	case err = <-served:
+		log.Printf("Server stopped unexpectedly: %v", err)
+	}
+	// Restore the default handling so a second signal is not swallowed
+	stopSignals()
+
+	l.shutdown()
+	return err
+}
+
+// shutdown runs the steps in order. Once the deadline has passed the
+// remaining steps still run with the expired context, so they give up on
+// waiting but the database pool is still closed.
+func (l *Lifecycle) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	log.Printf("Shutting down (timeout %s)", l.timeout)
+	for _, h := range l.hooks {
+		if err := h.stop(ctx); err != nil {
+			log.Printf("Shutdown: %s: %v", h.name, err)
+		}
+	}
+	log.Println("Shutdown complete")
+}
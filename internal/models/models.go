@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -39,19 +40,41 @@ type User struct {
 
 // Driver represents additional driver information
 type Driver struct {
+	ID            int64     `json:"id" db:"id"`
+	UserID        int64     `json:"user_id" db:"user_id"`
+	FullName      string    `json:"full_name" db:"full_name"`
+	CarModel      string    `json:"car_model" db:"car_model"`
+	CarNumber     string    `json:"car_number" db:"car_number"`
+	LicenseImage  *string   `json:"license_image,omitempty" db:"license_image"`
+	SeatCapacity  int       `json:"seat_capacity" db:"seat_capacity"`           // Passenger seats in the car
+	BaseLatitude  *float64  `json:"base_latitude,omitempty" db:"base_latitude"` // Where the driver usually starts from
+	BaseLongitude *float64  `json:"base_longitude,omitempty" db:"base_longitude"`
+	Balance       float64   `json:"balance" db:"balance"`
+	Rating        float64   `json:"rating" db:"rating"`
+	TotalRatings  int       `json:"total_ratings" db:"total_ratings"`
+	Status        string    `json:"status" db:"status"` // pending, approved, rejected
+	IsActive      bool      `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DriverRoute is a region pair a driver serves
+type DriverRoute struct {
 	ID           int64     `json:"id" db:"id"`
-	UserID       int64     `json:"user_id" db:"user_id"`
-	FullName     string    `json:"full_name" db:"full_name"`
-	CarModel     string    `json:"car_model" db:"car_model"`
-	CarNumber    string    `json:"car_number" db:"car_number"`
-	LicenseImage *string   `json:"license_image,omitempty" db:"license_image"`
-	Balance      float64   `json:"balance" db:"balance"`
-	Rating       float64   `json:"rating" db:"rating"`
-	TotalRatings int       `json:"total_ratings" db:"total_ratings"`
-	Status       string    `json:"status" db:"status"` // pending, approved, rejected
-	IsActive     bool      `json:"is_active" db:"is_active"`
+	DriverID     int64     `json:"driver_id" db:"driver_id"`
+	FromRegionID int64     `json:"from_region_id" db:"from_region_id"`
+	ToRegionID   int64     `json:"to_region_id" db:"to_region_id"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DriverAvailability is a time window on a date when a driver can drive
+type DriverAvailability struct {
+	ID        int64     `json:"id" db:"id"`
+	DriverID  int64     `json:"driver_id" db:"driver_id"`
+	Date      time.Time `json:"date" db:"available_date"`
+	StartTime string    `json:"start_time" db:"start_time"` // HH:MM
+	EndTime   string    `json:"end_time" db:"end_time"`     // HH:MM
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 // Region represents a region/province
@@ -90,6 +113,7 @@ const (
 	OrderStatusInProgress OrderStatus = "in_progress"
 	OrderStatusCompleted  OrderStatus = "completed"
 	OrderStatusCancelled  OrderStatus = "cancelled"
+	OrderStatusExpired    OrderStatus = "expired" // no driver accepted after all re-dispatches
 )
 
 // PassengerCount represents number of passengers
@@ -113,6 +137,28 @@ const (
 	DeliveryOther     DeliveryType = "other"
 )
 
+// PaymentMethod is how the customer pays an order's fare
+type PaymentMethod string
+
+const (
+	PaymentCash   PaymentMethod = "cash"   // collected by the driver
+	PaymentCard   PaymentMethod = "card"   // paid through a payment provider
+	PaymentWallet PaymentMethod = "wallet" // charged to the customer's wallet when ordering
+)
+
+// PaymentStatus tracks whether an order's fare was paid
+type PaymentStatus string
+
+const (
+	PaymentUnpaid   PaymentStatus = "unpaid"
+	PaymentPaid     PaymentStatus = "paid"
+	PaymentDisputed PaymentStatus = "disputed"
+	PaymentRefunded PaymentStatus = "refunded" // returned to the customer's wallet
+	PaymentWaived   PaymentStatus = "waived"   // written off by an admin
+	PaymentVoid     PaymentStatus = "void"     // order ended before anything was paid
+	PaymentOwed     PaymentStatus = "owed"     // order cancelled unpaid; the customer owes its cancellation fee
+)
+
 // Order represents both taxi and delivery orders
 type Order struct {
 	ID                 int64       `json:"id" db:"id"`
@@ -159,28 +205,168 @@ type Order struct {
 	// Additional info
 	Notes              *string `json:"notes,omitempty" db:"notes"`
 	CancellationReason *string `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+
+	// Cancellation outcome
+	CancellationPolicyID *int64   `json:"cancellation_policy_id,omitempty" db:"cancellation_policy_id"`
+	CancellationRefund   *float64 `json:"cancellation_refund,omitempty" db:"cancellation_refund"` // Service fee returned to the driver
+	CancellationFee      *float64 `json:"cancellation_fee,omitempty" db:"cancellation_fee"`       // Owed by the customer
+
+	// Payment
+	PaymentMethod PaymentMethod `json:"payment_method" db:"payment_method"`
+	PaymentStatus PaymentStatus `json:"payment_status" db:"payment_status"`
+	PaidAt        *time.Time    `json:"paid_at,omitempty" db:"paid_at"`
+	PaymentNote   *string       `json:"payment_note,omitempty" db:"payment_note"` // Dispute reason or admin resolution
+
+	// Shared ride the order is booked on
+	TripID *int64 `json:"trip_id,omitempty" db:"trip_id"`
 	
 	// Timing
 	AcceptedAt         *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	StartedAt          *time.Time `json:"started_at,omitempty" db:"started_at"`
 	AcceptDeadline     *time.Time `json:"accept_deadline,omitempty" db:"accept_deadline"`
+	DispatchAttempts   int        `json:"dispatch_attempts" db:"dispatch_attempts"`
 	CompletedAt        *time.Time `json:"completed_at,omitempty" db:"completed_at"`
 	CancelledAt        *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// OrderActor identifies the kind of party that changed an order
+type OrderActor string
+
+const (
+	ActorCustomer OrderActor = "customer"
+	ActorDriver   OrderActor = "driver"
+	ActorAdmin    OrderActor = "admin"
+	ActorSystem   OrderActor = "system" // background jobs such as order expiry
+)
+
+// OrderEventType classifies an entry of an order's timeline
+type OrderEventType string
+
+const (
+	OrderEventCreated       OrderEventType = "created"
+	OrderEventStatusChanged OrderEventType = "status_changed"
+	OrderEventRedispatched  OrderEventType = "redispatched"
+	OrderEventFeeRefunded   OrderEventType = "fee_refunded"
+	OrderEventPayment       OrderEventType = "payment"
+	OrderEventTripJoined    OrderEventType = "trip_joined"
+	OrderEventTripLeft      OrderEventType = "trip_left"
+)
+
+// OrderEvent is one entry of an order's audit trail
+type OrderEvent struct {
+	ID         int64           `json:"id" db:"id"`
+	OrderID    int64           `json:"order_id" db:"order_id"`
+	Type       OrderEventType  `json:"type" db:"event_type"`
+	FromStatus *OrderStatus    `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   *OrderStatus    `json:"to_status,omitempty" db:"to_status"` // nil when the status did not change
+	ActorType  OrderActor      `json:"actor_type" db:"actor_type"`
+	ActorID    *int64          `json:"actor_id,omitempty" db:"actor_id"` // User ID; nil for system events
+	Reason     *string         `json:"reason,omitempty" db:"reason"`
+	Details    json.RawMessage `json:"details" db:"details"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// CancellationPolicy is a rule deciding what a customer cancellation costs
+type CancellationPolicy struct {
+	ID                   int64       `json:"id" db:"id"`
+	OrderType            *OrderType  `json:"order_type,omitempty" db:"order_type"` // nil matches every type
+	OrderStatus          OrderStatus `json:"order_status" db:"order_status"`       // Status the order is cancelled from
+	MinHoursBeforePickup float64     `json:"min_hours_before_pickup" db:"min_hours_before_pickup"`
+	DriverRefundPercent  float64     `json:"driver_refund_percent" db:"driver_refund_percent"` // Of the service fee
+	CustomerFeePercent   float64     `json:"customer_fee_percent" db:"customer_fee_percent"`   // Of the final price
+	Description          *string     `json:"description,omitempty" db:"description"`
+	CreatedAt            time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// PricingStrategy decides how a route's rates turn into a trip price
+type PricingStrategy string
+
+const (
+	PricingFlat     PricingStrategy = "flat"     // Base price plus a price per passenger
+	PricingDistance PricingStrategy = "distance" // Flat price plus a price per km between the coordinates
+	PricingPerSeat  PricingStrategy = "per_seat" // Seats on a shared trip at the driver's price; not a route strategy
+)
+
 // Pricing represents pricing configuration between regions
 type Pricing struct {
+	ID             int64           `json:"id" db:"id"`
+	FromRegionID   int64           `json:"from_region_id" db:"from_region_id"`
+	ToRegionID     int64           `json:"to_region_id" db:"to_region_id"`
+	Strategy       PricingStrategy `json:"strategy" db:"strategy"`
+	BasePrice      float64         `json:"base_price" db:"base_price"`
+	PricePerPerson float64         `json:"price_per_person" db:"price_per_person"`
+	PricePerKm     float64         `json:"price_per_km" db:"price_per_km"`
+	ServiceFee     float64         `json:"service_fee" db:"service_fee"` // Percentage
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// DistrictPricing replaces the rates of a region route for one district pair
+type DistrictPricing struct {
 	ID             int64     `json:"id" db:"id"`
-	FromRegionID   int64     `json:"from_region_id" db:"from_region_id"`
-	ToRegionID     int64     `json:"to_region_id" db:"to_region_id"`
+	FromDistrictID int64     `json:"from_district_id" db:"from_district_id"`
+	ToDistrictID   int64     `json:"to_district_id" db:"to_district_id"`
 	BasePrice      float64   `json:"base_price" db:"base_price"`
 	PricePerPerson float64   `json:"price_per_person" db:"price_per_person"`
-	ServiceFee     float64   `json:"service_fee" db:"service_fee"` // Percentage
+	PricePerKm     float64   `json:"price_per_km" db:"price_per_km"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// SurchargeKind tells when a pricing surcharge applies
+type SurchargeKind string
+
+const (
+	SurchargeNight   SurchargeKind = "night"   // Trips starting between StartTime and EndTime
+	SurchargeHoliday SurchargeKind = "holiday" // Trips scheduled on HolidayDate
+)
+
+// PricingSurcharge is a percentage added to the price of matching trips
+type PricingSurcharge struct {
+	ID          int64         `json:"id" db:"id"`
+	Kind        SurchargeKind `json:"kind" db:"kind"`
+	StartTime   *string       `json:"start_time,omitempty" db:"start_time"` // HH:MM, night only
+	EndTime     *string       `json:"end_time,omitempty" db:"end_time"`     // HH:MM, night only; before StartTime wraps past midnight
+	HolidayDate *time.Time    `json:"holiday_date,omitempty" db:"holiday_date"`
+	Percent     float64       `json:"percent" db:"percent"`
+	Description *string       `json:"description,omitempty" db:"description"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// TripStatus represents the status of a published trip
+type TripStatus string
+
+const (
+	TripOpen      TripStatus = "open"   // Takes orders while seats are left
+	TripClosed    TripStatus = "closed" // Takes no more orders, e.g. the car has left
+	TripCancelled TripStatus = "cancelled"
+)
+
+// Trip is an intercity ride a driver publishes ahead of time. Orders on its
+// region pair and date attach to it until its seats are taken, so one car
+// carries several bookings.
+type Trip struct {
+	ID                 int64      `json:"id" db:"id"`
+	DriverID           int64      `json:"driver_id" db:"driver_id"`
+	FromRegionID       int64      `json:"from_region_id" db:"from_region_id"`
+	ToRegionID         int64      `json:"to_region_id" db:"to_region_id"`
+	ScheduledDate      time.Time  `json:"scheduled_date" db:"scheduled_date"`
+	DepartureStart     string     `json:"departure_start" db:"departure_start"` // HH:MM
+	DepartureEnd       string     `json:"departure_end" db:"departure_end"`     // HH:MM
+	SeatsTotal         int        `json:"seats_total" db:"seats_total"`
+	SeatsBooked        int        `json:"seats_booked" db:"seats_booked"`
+	PricePerSeat       float64    `json:"price_per_seat" db:"price_per_seat"`
+	Status             TripStatus `json:"status" db:"status"`
+	Notes              *string    `json:"notes,omitempty" db:"notes"`
+	CancellationReason *string    `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
 // Discount represents discount configuration
 type Discount struct {
 	ID                 int64     `json:"id" db:"id"`
@@ -230,16 +416,87 @@ type DriverApplication struct {
 	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// Transaction represents balance transactions
-type Transaction struct {
-	ID          int64     `json:"id" db:"id"`
-	DriverID    int64     `json:"driver_id" db:"driver_id"`
-	OrderID     *int64    `json:"order_id,omitempty" db:"order_id"`
-	Amount      float64   `json:"amount" db:"amount"`
-	Type        string    `json:"type" db:"type"` // debit, credit, refund
-	Description string    `json:"description" db:"description"`
-	CreatedBy   *int64    `json:"created_by,omitempty" db:"created_by"` // Admin ID if manual
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+// LedgerKind classifies a movement of money in the ledger
+type LedgerKind string
+
+const (
+	LedgerServiceFee     LedgerKind = "service_fee"     // charged when a driver accepts an order
+	LedgerFeeRefund      LedgerKind = "fee_refund"      // service fee returned on release or cancellation
+	LedgerTopUp          LedgerKind = "top_up"          // money paid into a driver's wallet
+	LedgerOpeningBalance LedgerKind = "opening_balance" // balance carried over when the ledger was introduced
+	LedgerFarePayment    LedgerKind = "fare_payment"    // order fare paid by card or from a customer's wallet
+	LedgerFareRefund     LedgerKind = "fare_refund"     // fare returned to a customer's wallet
+)
+
+// Platform ledger accounts; every wallet posting is balanced against one
+const (
+	AccountPlatformRevenue = "platform_revenue"
+	AccountDriverFunding   = "driver_funding"
+	AccountOpeningBalances = "opening_balances"
+	AccountFareReceipts    = "fare_receipts"
+	AccountCustomerFunding = "customer_funding"
+)
+
+// LedgerTransaction is one movement of money between ledger accounts. Its
+// entries always sum to zero; neither is ever changed once written.
+type LedgerTransaction struct {
+	ID          int64         `json:"id" db:"id"`
+	Kind        LedgerKind    `json:"kind" db:"kind"`
+	OrderID     *int64        `json:"order_id,omitempty" db:"order_id"`
+	Description string        `json:"description" db:"description"`
+	CreatedBy   *int64        `json:"created_by,omitempty" db:"created_by"` // Admin ID if manual
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	Entries     []LedgerEntry `json:"entries,omitempty"`
+}
+
+// LedgerEntry is one side of a ledger transaction
+type LedgerEntry struct {
+	ID            int64   `json:"id" db:"id"`
+	TransactionID int64   `json:"transaction_id" db:"transaction_id"`
+	AccountID     int64   `json:"account_id" db:"account_id"`
+	Amount        float64 `json:"amount" db:"amount"`
+}
+
+// WalletTransaction is a ledger transaction as seen from a driver's wallet:
+// Amount is the change to the driver's balance
+type WalletTransaction struct {
+	ID          int64      `json:"id" db:"id"`
+	DriverID    int64      `json:"driver_id" db:"driver_id"`
+	OrderID     *int64     `json:"order_id,omitempty" db:"order_id"`
+	Kind        LedgerKind `json:"kind" db:"kind"`
+	Amount      float64    `json:"amount" db:"amount"`
+	Description string     `json:"description" db:"description"`
+	CreatedBy   *int64     `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TopUpStatus is the state of a driver top-up; pending is the only state
+// that changes
+type TopUpStatus string
+
+const (
+	TopUpPending   TopUpStatus = "pending"
+	TopUpConfirmed TopUpStatus = "confirmed"
+	TopUpFailed    TopUpStatus = "failed"
+)
+
+// TopUp is a payment through a payment provider: a driver paying into their
+// wallet, or a customer paying an order's fare by card
+type TopUp struct {
+	ID                  int64       `json:"id" db:"id"`
+	DriverID            *int64      `json:"driver_id,omitempty" db:"driver_id"`
+	OrderID             *int64      `json:"order_id,omitempty" db:"order_id"`
+	Provider            string      `json:"provider" db:"provider"`
+	Amount              float64     `json:"amount" db:"amount"`
+	Status              TopUpStatus `json:"status" db:"status"`
+	ProviderRef         *string     `json:"provider_ref,omitempty" db:"provider_ref"`                   // Provider's transaction ID
+	ProviderCreatedAt   *time.Time  `json:"provider_created_at,omitempty" db:"provider_created_at"`     // When the provider transaction was attached
+	FailureReason       *string     `json:"failure_reason,omitempty" db:"failure_reason"`
+	LedgerTransactionID *int64      `json:"ledger_transaction_id,omitempty" db:"ledger_transaction_id"` // Credit posted on confirmation
+	ConfirmedAt         *time.Time  `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	FailedAt            *time.Time  `json:"failed_at,omitempty" db:"failed_at"`
+	CreatedAt           time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at" db:"updated_at"`
 }
 
 // Feedback represents user feedback/suggestions
@@ -249,3 +506,27 @@ type Feedback struct {
 	Message   string    `json:"message" db:"message"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending" // Waiting for run_at
+	JobRunning JobStatus = "running" // Claimed by a worker until locked_until
+	JobFailed  JobStatus = "failed"  // Ran out of attempts
+)
+
+// Job is a unit of background work in the job queue
+type Job struct {
+	ID          int64           `json:"id" db:"id"`
+	Kind        string          `json:"kind" db:"kind"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      JobStatus       `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	LockedUntil *time.Time      `json:"locked_until,omitempty" db:"locked_until"`
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,204 @@
+package payments
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+)
+
+const clickCheckoutURL = "https://my.click.uz/services/pay"
+
+// Click SHOP API error codes
+const (
+	clickOK            = 0
+	clickSignFailed    = -1
+	clickBadAmount     = -2
+	clickBadAction     = -3
+	clickAlreadyPaid   = -4
+	clickTopUpNotFound = -5
+	clickTxNotFound    = -6
+	clickUpdateFailed  = -7
+	clickBadRequest    = -8
+	clickTxCancelled   = -9
+)
+
+const (
+	clickActionPrepare  = "0"
+	clickActionComplete = "1"
+)
+
+// Click implements the Click SHOP API. Click calls Prepare when the driver
+// submits payment and Complete once the money has moved or the payment was
+// abandoned; merchant_trans_id carries the top-up ID.
+type Click struct {
+	serviceID  string
+	merchantID string
+	secretKey  string
+	returnURL  string
+}
+
+// NewClick creates the Click provider
+func NewClick(cfg *config.PaymentsConfig) *Click {
+	return &Click{
+		serviceID:  cfg.ClickServiceID,
+		merchantID: cfg.ClickMerchantID,
+		secretKey:  cfg.ClickSecretKey,
+		returnURL:  cfg.ReturnURL,
+	}
+}
+
+type clickReply struct {
+	ClickTransID      string `json:"click_trans_id"`
+	MerchantTransID   string `json:"merchant_trans_id"`
+	MerchantPrepareID int64  `json:"merchant_prepare_id,omitempty"`
+	MerchantConfirmID int64  `json:"merchant_confirm_id,omitempty"`
+	Error             int    `json:"error"`
+	ErrorNote         string `json:"error_note"`
+}
+
+// Name returns the provider name
+func (c *Click) Name() string {
+	return "click"
+}
+
+// CheckoutURL returns the Click payment page for the top-up
+func (c *Click) CheckoutURL(topUp *models.TopUp) (string, error) {
+	q := url.Values{}
+	q.Set("service_id", c.serviceID)
+	q.Set("merchant_id", c.merchantID)
+	q.Set("amount", strconv.FormatFloat(topUp.Amount, 'f', 2, 64))
+	q.Set("transaction_param", strconv.FormatInt(topUp.ID, 10))
+	if c.returnURL != "" {
+		q.Set("return_url", c.returnURL)
+	}
+	return clickCheckoutURL + "?" + q.Encode(), nil
+}
+
+// HandleCallback answers a form-encoded Prepare or Complete request. Click
+// always expects HTTP 200 with the outcome in the error field.
+func (c *Click) HandleCallback(req Request, book Book) Response {
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		return clickResponse(clickReply{Error: clickBadRequest, ErrorNote: "Invalid request"})
+	}
+
+	reply := clickReply{
+		ClickTransID:    form.Get("click_trans_id"),
+		MerchantTransID: form.Get("merchant_trans_id"),
+	}
+	fail := func(code int, note string) Response {
+		reply.Error, reply.ErrorNote = code, note
+		return clickResponse(reply)
+	}
+
+	action := form.Get("action")
+	if reply.ClickTransID == "" || reply.MerchantTransID == "" || form.Get("sign_time") == "" {
+		return fail(clickBadRequest, "Missing parameters")
+	}
+	if form.Get("service_id") != c.serviceID || !c.validSignature(form) {
+		return fail(clickSignFailed, "Sign check failed")
+	}
+	amount, err := strconv.ParseFloat(form.Get("amount"), 64)
+	if err != nil {
+		return fail(clickBadAmount, "Incorrect amount")
+	}
+	topUpID, err := strconv.ParseInt(reply.MerchantTransID, 10, 64)
+	if err != nil {
+		return fail(clickTopUpNotFound, "Top-up not found")
+	}
+
+	switch action {
+	case clickActionPrepare:
+		topUp, err := book.TopUp(topUpID)
+		if err != nil {
+			return fail(clickFailure(err))
+		}
+		if !sameAmount(amount, topUp.Amount) {
+			return fail(clickBadAmount, "Incorrect amount")
+		}
+		topUp, err = book.Attach(topUp.ID, reply.ClickTransID)
+		if err != nil {
+			return fail(clickFailure(err))
+		}
+		switch topUp.Status {
+		case models.TopUpConfirmed:
+			return fail(clickAlreadyPaid, "Already paid")
+		case models.TopUpFailed:
+			return fail(clickTxCancelled, "Transaction cancelled")
+		}
+		reply.MerchantPrepareID = topUp.ID
+
+	case clickActionComplete:
+		topUp, err := book.TopUpByRef(reply.ClickTransID)
+		if errors.Is(err, ErrTopUpNotFound) || (err == nil && (topUp.ID != topUpID || form.Get("merchant_prepare_id") != reply.MerchantTransID)) {
+			return fail(clickTxNotFound, "Transaction not found")
+		}
+		if err != nil {
+			return fail(clickFailure(err))
+		}
+
+		// A negative error means Click could not take the money
+		if code, _ := strconv.Atoi(form.Get("error")); code < 0 {
+			if _, err := book.Fail(topUp.ID, fmt.Sprintf("click:%d", code)); err != nil {
+				return fail(clickFailure(err))
+			}
+			return fail(clickTxCancelled, "Transaction cancelled")
+		}
+		if !sameAmount(amount, topUp.Amount) {
+			return fail(clickBadAmount, "Incorrect amount")
+		}
+		if topUp.Status == models.TopUpConfirmed {
+			return fail(clickAlreadyPaid, "Already paid")
+		}
+		if _, err := book.Confirm(topUp.ID); err != nil {
+			return fail(clickFailure(err))
+		}
+		reply.MerchantConfirmID = topUp.ID
+
+	default:
+		return fail(clickBadAction, "Action not found")
+	}
+
+	reply.Error, reply.ErrorNote = clickOK, "Success"
+	return clickResponse(reply)
+}
+
+// validSignature checks sign_string, the MD5 of the request fields and the
+// secret key; Complete requests also sign merchant_prepare_id
+func (c *Click) validSignature(form url.Values) bool {
+	signed := form.Get("click_trans_id") + form.Get("service_id") + c.secretKey + form.Get("merchant_trans_id")
+	if form.Get("action") == clickActionComplete {
+		signed += form.Get("merchant_prepare_id")
+	}
+	signed += form.Get("amount") + form.Get("action") + form.Get("sign_time")
+
+	sum := md5.Sum([]byte(signed))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(form.Get("sign_string"))) == 1
+}
+
+func clickFailure(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrTopUpNotFound):
+		return clickTopUpNotFound, "Top-up not found"
+	case errors.Is(err, ErrTopUpBusy):
+		return clickAlreadyPaid, "Top-up is being paid by another transaction"
+	case errors.Is(err, ErrTopUpConfirmed):
+		return clickAlreadyPaid, "Already paid"
+	case errors.Is(err, ErrTopUpFailed):
+		return clickTxCancelled, "Transaction cancelled"
+	}
+	return clickUpdateFailed, "Failed to update top-up"
+}
+
+func clickResponse(reply clickReply) Response {
+	return Response{Status: http.StatusOK, Body: reply}
+}
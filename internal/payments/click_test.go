@@ -0,0 +1,73 @@
+package payments
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/url"
+	"testing"
+
+	"taxi-service/internal/config"
+)
+
+func testClick() *Click {
+	return NewClick(&config.PaymentsConfig{ClickServiceID: "100", ClickMerchantID: "200", ClickSecretKey: "click-secret"})
+}
+
+// clickForm builds a callback signed the way Click signs it
+func clickForm(action, secret string) url.Values {
+	form := url.Values{}
+	form.Set("click_trans_id", "555")
+	form.Set("service_id", "100")
+	form.Set("merchant_trans_id", "42")
+	form.Set("amount", "50000.00")
+	form.Set("action", action)
+	form.Set("sign_time", "2025-03-08 09:00:00")
+
+	signed := "555" + "100" + secret + "42"
+	if action == clickActionComplete {
+		form.Set("merchant_prepare_id", "42")
+		signed += "42"
+	}
+	signed += "50000.00" + action + "2025-03-08 09:00:00"
+	sum := md5.Sum([]byte(signed))
+	form.Set("sign_string", hex.EncodeToString(sum[:]))
+	return form
+}
+
+func TestClickValidSignature(t *testing.T) {
+	click := testClick()
+	tests := []struct {
+		name string
+		form func() url.Values
+		want bool
+	}{
+		{"prepare", func() url.Values { return clickForm(clickActionPrepare, "click-secret") }, true},
+		{"complete", func() url.Values { return clickForm(clickActionComplete, "click-secret") }, true},
+		{"another secret", func() url.Values { return clickForm(clickActionPrepare, "other-secret") }, false},
+		{"tampered amount", func() url.Values {
+			form := clickForm(clickActionPrepare, "click-secret")
+			form.Set("amount", "1.00")
+			return form
+		}, false},
+		{"tampered prepare ID on complete", func() url.Values {
+			form := clickForm(clickActionComplete, "click-secret")
+			form.Set("merchant_prepare_id", "43")
+			return form
+		}, false},
+		{"wrong digest", func() url.Values {
+			form := clickForm(clickActionPrepare, "click-secret")
+			form.Set("sign_string", "ABC")
+			return form
+		}, false},
+		{"missing signature", func() url.Values {
+			form := clickForm(clickActionPrepare, "click-secret")
+			form.Del("sign_string")
+			return form
+		}, false},
+	}
+	for _, tt := range tests {
+		if got := click.validSignature(tt.form()); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+)
+
+// Fake is a provider for local testing and automated checks. There is no
+// checkout page: whoever holds the secret settles a top-up by posting a
+// callback signed with it.
+type Fake struct {
+	secret []byte
+}
+
+// NewFake creates the fake provider
+func NewFake(cfg *config.PaymentsConfig) *Fake {
+	return &Fake{secret: []byte(cfg.FakeSecret)}
+}
+
+// fakeCallback is the body of a fake provider callback. It is signed with
+// HMAC-SHA256 of the raw body, hex encoded in the X-Signature header.
+type fakeCallback struct {
+	TopUpID   int64   `json:"top_up_id"`
+	Reference string  `json:"reference"`
+	Status    string  `json:"status"` // paid or failed
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+}
+
+type fakeError struct {
+	Error string `json:"error"`
+}
+
+// Name returns the provider name
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// CheckoutURL returns no link; the top-up is settled by a callback
+func (f *Fake) CheckoutURL(topUp *models.TopUp) (string, error) {
+	return "", nil
+}
+
+// HandleCallback attaches the reference to the top-up, then confirms or
+// fails it. It replies with the top-up as it stands afterwards.
+func (f *Fake) HandleCallback(req Request, book Book) Response {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(req.Body)
+	signature, err := hex.DecodeString(req.Header("X-Signature"))
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return fakeReply(http.StatusUnauthorized, "Invalid signature")
+	}
+
+	var cb fakeCallback
+	if err := json.Unmarshal(req.Body, &cb); err != nil {
+		return fakeReply(http.StatusBadRequest, "Invalid request body")
+	}
+	if cb.Reference == "" {
+		return fakeReply(http.StatusBadRequest, "Reference is required")
+	}
+
+	topUp, err := book.TopUp(cb.TopUpID)
+	if err != nil {
+		return fakeFailure(err)
+	}
+
+	switch cb.Status {
+	case "paid":
+		if !sameAmount(cb.Amount, topUp.Amount) {
+			return fakeReply(http.StatusBadRequest, "Amount does not match the top-up")
+		}
+		if _, err := book.Attach(topUp.ID, cb.Reference); err != nil {
+			return fakeFailure(err)
+		}
+		topUp, err = book.Confirm(topUp.ID)
+	case "failed":
+		if _, err := book.Attach(topUp.ID, cb.Reference); err != nil && !errors.Is(err, ErrTopUpFailed) {
+			return fakeFailure(err)
+		}
+		reason := cb.Reason
+		if reason == "" {
+			reason = "declined"
+		}
+		topUp, err = book.Fail(topUp.ID, reason)
+	default:
+		return fakeReply(http.StatusBadRequest, "Status must be paid or failed")
+	}
+	if err != nil {
+		return fakeFailure(err)
+	}
+	return Response{Status: http.StatusOK, Body: topUp}
+}
+
+func fakeFailure(err error) Response {
+	switch {
+	case errors.Is(err, ErrTopUpNotFound):
+		return fakeReply(http.StatusNotFound, "Top-up not found")
+	case errors.Is(err, ErrTopUpBusy):
+		return fakeReply(http.StatusConflict, "Top-up is being paid by another transaction")
+	case errors.Is(err, ErrTopUpConfirmed):
+		return fakeReply(http.StatusConflict, "Top-up already confirmed")
+	case errors.Is(err, ErrTopUpFailed):
+		return fakeReply(http.StatusConflict, "Top-up already failed")
+	}
+	return fakeReply(http.StatusInternalServerError, "Failed to process callback")
+}
+
+func fakeReply(status int, message string) Response {
+	return Response{Status: status, Body: fakeError{Error: message}}
+}
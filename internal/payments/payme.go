@@ -0,0 +1,361 @@
+package payments
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+)
+
+// Payme Merchant API error codes
+const (
+	paymeBadAmount       = -31001
+	paymeTxNotFound      = -31003
+	paymeCannotCancel    = -31007
+	paymeCannotPerform   = -31008
+	paymeAccountNotFound = -31050
+	paymeAccountBusy     = -31099
+	paymeSystemError     = -32400
+	paymeUnauthorized    = -32504
+	paymeInvalidRequest  = -32600
+	paymeMethodNotFound  = -32601
+	paymeParseError      = -32700
+)
+
+// Payme transaction states
+const (
+	paymeStatePending   = 1
+	paymeStatePerformed = 2
+	paymeStateCancelled = -1
+)
+
+// paymeReasonTimeout is the cancel reason reported for top-ups that expired
+// or failed without a Payme reason
+const paymeReasonTimeout = 4
+
+// Payme implements the Payme Merchant API: a JSON-RPC endpoint Payme calls
+// with Basic auth. Amounts are in tiyin and times in Unix milliseconds; the
+// top-up ID travels in account.top_up_id.
+type Payme struct {
+	merchantID  string
+	key         string
+	checkoutURL string
+	returnURL   string
+}
+
+// NewPayme creates the Payme provider
+func NewPayme(cfg *config.PaymentsConfig) *Payme {
+	return &Payme{
+		merchantID:  cfg.PaymeMerchantID,
+		key:         cfg.PaymeKey,
+		checkoutURL: strings.TrimRight(cfg.PaymeCheckoutURL, "/"),
+		returnURL:   cfg.ReturnURL,
+	}
+}
+
+type paymeRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type paymeParams struct {
+	ID      string  `json:"id"`
+	Amount  float64 `json:"amount"`
+	Reason  int     `json:"reason"`
+	From    int64   `json:"from"`
+	To      int64   `json:"to"`
+	Account struct {
+		TopUpID json.Number `json:"top_up_id"`
+	} `json:"account"`
+}
+
+type paymeError struct {
+	Code    int               `json:"code"`
+	Message map[string]string `json:"message"`
+	Data    string            `json:"data,omitempty"`
+}
+
+func (e *paymeError) Error() string {
+	return fmt.Sprintf("payme error %d: %s", e.Code, e.Message["en"])
+}
+
+func newPaymeError(code int, message string) *paymeError {
+	return &paymeError{Code: code, Message: map[string]string{"ru": message, "uz": message, "en": message}}
+}
+
+// paymeTransaction describes a top-up in Payme's terms
+type paymeTransaction struct {
+	ID          string                 `json:"id"`
+	Time        int64                  `json:"time"`
+	Amount      int64                  `json:"amount"`
+	Account     map[string]interface{} `json:"account"`
+	CreateTime  int64                  `json:"create_time"`
+	PerformTime int64                  `json:"perform_time"`
+	CancelTime  int64                  `json:"cancel_time"`
+	Transaction string                 `json:"transaction"`
+	State       int                    `json:"state"`
+	Reason      *int                   `json:"reason"`
+}
+
+// Name returns the provider name
+func (p *Payme) Name() string {
+	return "payme"
+}
+
+// CheckoutURL returns the Payme checkout link for the top-up
+func (p *Payme) CheckoutURL(topUp *models.TopUp) (string, error) {
+	params := fmt.Sprintf("m=%s;ac.top_up_id=%d;a=%d", p.merchantID, topUp.ID, toTiyin(topUp.Amount))
+	if p.returnURL != "" {
+		params += ";c=" + p.returnURL
+	}
+	return p.checkoutURL + "/" + base64.StdEncoding.EncodeToString([]byte(params)), nil
+}
+
+// HandleCallback answers one JSON-RPC call. Payme expects HTTP 200 for
+// every reply, errors included.
+func (p *Payme) HandleCallback(req Request, book Book) Response {
+	var call paymeRequest
+	if err := json.Unmarshal(req.Body, &call); err != nil {
+		return paymeReply(nil, nil, newPaymeError(paymeParseError, "Invalid JSON"))
+	}
+	if !p.authorized(req.Header("Authorization")) {
+		return paymeReply(call.ID, nil, newPaymeError(paymeUnauthorized, "Insufficient privileges"))
+	}
+
+	var params paymeParams
+	if err := json.Unmarshal(call.Params, &params); err != nil {
+		return paymeReply(call.ID, nil, newPaymeError(paymeInvalidRequest, "Invalid params"))
+	}
+
+	var result interface{}
+	var err error
+	switch call.Method {
+	case "CheckPerformTransaction":
+		_, err = p.payableTopUp(params, book)
+		result = map[string]bool{"allow": true}
+	case "CreateTransaction":
+		result, err = p.createTransaction(params, book)
+	case "PerformTransaction":
+		result, err = p.performTransaction(params, book)
+	case "CancelTransaction":
+		result, err = p.cancelTransaction(params, book)
+	case "CheckTransaction":
+		result, err = p.checkTransaction(params, book)
+	case "GetStatement":
+		result, err = p.statement(params, book)
+	default:
+		err = newPaymeError(paymeMethodNotFound, "Method not found")
+	}
+	if err != nil {
+		return paymeReply(call.ID, nil, paymeFailure(err))
+	}
+	return paymeReply(call.ID, result, nil)
+}
+
+func (p *Payme) authorized(header string) bool {
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("Paycom:"+p.key))
+	return subtle.ConstantTimeCompare([]byte(header), []byte(expected)) == 1
+}
+
+// payableTopUp finds the pending top-up named in account and checks the amount
+func (p *Payme) payableTopUp(params paymeParams, book Book) (*models.TopUp, error) {
+	id, err := params.Account.TopUpID.Int64()
+	if err != nil {
+		return nil, accountNotFound()
+	}
+	topUp, err := book.TopUp(id)
+	if err != nil {
+		return nil, err
+	}
+	if int64(math.Round(params.Amount)) != toTiyin(topUp.Amount) {
+		return nil, newPaymeError(paymeBadAmount, "Incorrect amount")
+	}
+	if topUp.Status != models.TopUpPending {
+		return nil, newPaymeError(paymeAccountBusy, "Top-up is not awaiting payment")
+	}
+	return topUp, nil
+}
+
+func (p *Payme) createTransaction(params paymeParams, book Book) (interface{}, error) {
+	topUp, err := book.TopUpByRef(params.ID)
+	if errors.Is(err, ErrTopUpNotFound) {
+		topUp, err = p.payableTopUp(params, book)
+		if err != nil {
+			return nil, err
+		}
+		topUp, err = book.Attach(topUp.ID, params.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if topUp.Status != models.TopUpPending {
+		return nil, newPaymeError(paymeCannotPerform, "Transaction cannot be performed")
+	}
+
+	tx := describe(topUp)
+	return map[string]interface{}{"create_time": tx.CreateTime, "transaction": tx.Transaction, "state": tx.State}, nil
+}
+
+// transaction finds the top-up a Payme transaction is attached to
+func (p *Payme) transaction(ref string, book Book) (*models.TopUp, error) {
+	topUp, err := book.TopUpByRef(ref)
+	if errors.Is(err, ErrTopUpNotFound) {
+		return nil, newPaymeError(paymeTxNotFound, "Transaction not found")
+	}
+	return topUp, err
+}
+
+func (p *Payme) performTransaction(params paymeParams, book Book) (interface{}, error) {
+	topUp, err := p.transaction(params.ID, book)
+	if err != nil {
+		return nil, err
+	}
+	topUp, err = book.Confirm(topUp.ID)
+	if errors.Is(err, ErrTopUpFailed) {
+		return nil, newPaymeError(paymeCannotPerform, "Transaction cannot be performed")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx := describe(topUp)
+	return map[string]interface{}{"transaction": tx.Transaction, "perform_time": tx.PerformTime, "state": tx.State}, nil
+}
+
+func (p *Payme) cancelTransaction(params paymeParams, book Book) (interface{}, error) {
+	topUp, err := p.transaction(params.ID, book)
+	if err != nil {
+		return nil, err
+	}
+	topUp, err = book.Fail(topUp.ID, fmt.Sprintf("payme:%d", params.Reason))
+	if errors.Is(err, ErrTopUpConfirmed) {
+		// Wallet credits are not reversed automatically
+		return nil, newPaymeError(paymeCannotCancel, "Top-up already credited")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx := describe(topUp)
+	return map[string]interface{}{"transaction": tx.Transaction, "cancel_time": tx.CancelTime, "state": tx.State}, nil
+}
+
+func (p *Payme) checkTransaction(params paymeParams, book Book) (interface{}, error) {
+	topUp, err := p.transaction(params.ID, book)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := describe(topUp)
+	return map[string]interface{}{
+		"create_time":  tx.CreateTime,
+		"perform_time": tx.PerformTime,
+		"cancel_time":  tx.CancelTime,
+		"transaction":  tx.Transaction,
+		"state":        tx.State,
+		"reason":       tx.Reason,
+	}, nil
+}
+
+func (p *Payme) statement(params paymeParams, book Book) (interface{}, error) {
+	topUps, err := book.List(fromMillis(params.From), fromMillis(params.To))
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]paymeTransaction, len(topUps))
+	for i := range topUps {
+		transactions[i] = describe(&topUps[i])
+	}
+	return map[string]interface{}{"transactions": transactions}, nil
+}
+
+// describe maps a top-up onto a Payme transaction
+func describe(topUp *models.TopUp) paymeTransaction {
+	tx := paymeTransaction{
+		Time:        millis(topUp.ProviderCreatedAt),
+		Amount:      toTiyin(topUp.Amount),
+		Account:     map[string]interface{}{"top_up_id": topUp.ID},
+		CreateTime:  millis(topUp.ProviderCreatedAt),
+		PerformTime: millis(topUp.ConfirmedAt),
+		CancelTime:  millis(topUp.FailedAt),
+		Transaction: strconv.FormatInt(topUp.ID, 10),
+	}
+	if topUp.ProviderRef != nil {
+		tx.ID = *topUp.ProviderRef
+	}
+
+	switch topUp.Status {
+	case models.TopUpConfirmed:
+		tx.State = paymeStatePerformed
+	case models.TopUpFailed:
+		tx.State = paymeStateCancelled
+		reason := paymeReasonTimeout
+		if topUp.FailureReason != nil {
+			if code, err := strconv.Atoi(strings.TrimPrefix(*topUp.FailureReason, "payme:")); err == nil {
+				reason = code
+			}
+		}
+		tx.Reason = &reason
+	default:
+		tx.State = paymeStatePending
+	}
+	return tx
+}
+
+func paymeFailure(err error) *paymeError {
+	var perr *paymeError
+	if errors.As(err, &perr) {
+		return perr
+	}
+	switch {
+	case errors.Is(err, ErrTopUpNotFound):
+		return accountNotFound()
+	case errors.Is(err, ErrTopUpBusy):
+		return newPaymeError(paymeAccountBusy, "Top-up is being paid by another transaction")
+	case errors.Is(err, ErrTopUpConfirmed), errors.Is(err, ErrTopUpFailed):
+		return newPaymeError(paymeCannotPerform, "Transaction cannot be performed")
+	}
+	return newPaymeError(paymeSystemError, "System error")
+}
+
+// accountNotFound reports an account.top_up_id that names no top-up
+func accountNotFound() *paymeError {
+	e := newPaymeError(paymeAccountNotFound, "Top-up not found")
+	e.Data = "top_up_id"
+	return e
+}
+
+func paymeReply(id json.RawMessage, result interface{}, err *paymeError) Response {
+	body := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if err != nil {
+		body["error"] = err
+	} else {
+		body["result"] = result
+	}
+	return Response{Status: http.StatusOK, Body: body}
+}
+
+func toTiyin(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func millis(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,34 @@
+package payments
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"taxi-service/internal/config"
+)
+
+func TestPaymeAuthorized(t *testing.T) {
+	payme := NewPayme(&config.PaymentsConfig{PaymeMerchantID: "merchant", PaymeKey: "payme-key"})
+	basic := func(credentials string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"Paycom with the key", basic("Paycom:payme-key"), true},
+		{"wrong key", basic("Paycom:other-key"), false},
+		{"wrong login", basic("merchant:payme-key"), false},
+		{"key without a login", basic("payme-key"), false},
+		{"another scheme", "Bearer " + base64.StdEncoding.EncodeToString([]byte("Paycom:payme-key")), false},
+		{"unencoded credentials", "Basic Paycom:payme-key", false},
+		{"no header", "", false},
+	}
+	for _, tt := range tests {
+		if got := payme.authorized(tt.header); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package payments adapts external payment providers to top-ups: drivers
+// paying into their wallet and customers paying order fares by card. Each
+// provider turns a pending top-up into a checkout link and translates
+// the provider's callbacks into changes on a Book, replying in whatever
+// format the provider expects.
+package payments
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+)
+
+// Errors a Book returns for top-ups that cannot take the requested change
+var (
+	ErrTopUpNotFound  = errors.New("top-up not found")
+	ErrTopUpBusy      = errors.New("top-up is being paid by another transaction")
+	ErrTopUpConfirmed = errors.New("top-up already confirmed")
+	ErrTopUpFailed    = errors.New("top-up already failed")
+)
+
+// Book is the store of top-ups as seen by one provider: top-ups started with
+// another provider are not found. Confirm and Fail are idempotent, so a
+// provider retrying a callback gets the same answer again.
+type Book interface {
+	TopUp(id int64) (*models.TopUp, error)
+	TopUpByRef(ref string) (*models.TopUp, error)
+	// Attach links the provider's transaction to a pending top-up; attaching
+	// the same reference again returns the top-up whatever its state
+	Attach(id int64, ref string) (*models.TopUp, error)
+	// Confirm credits the driver's wallet or pays the order and marks the
+	// top-up confirmed
+	Confirm(id int64) (*models.TopUp, error)
+	Fail(id int64, reason string) (*models.TopUp, error)
+	// List returns top-ups whose provider transaction was attached in [from, to)
+	List(from, to time.Time) ([]models.TopUp, error)
+}
+
+// Request is an incoming provider callback
+type Request struct {
+	Header func(key string) string
+	Body   []byte
+}
+
+// Response is the reply a provider expects; Body is encoded as JSON
+type Response struct {
+	Status int
+	Body   interface{}
+}
+
+// Provider is a payment provider top-ups can be paid through
+type Provider interface {
+	Name() string
+	// CheckoutURL is where the payer pays for the top-up; empty when the
+	// provider has no hosted checkout
+	CheckoutURL(topUp *models.TopUp) (string, error)
+	HandleCallback(req Request, book Book) Response
+}
+
+// Registry holds the providers enabled by configuration
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry enables every provider whose credentials are configured
+func NewRegistry(cfg *config.PaymentsConfig) *Registry {
+	r := &Registry{providers: make(map[string]Provider)}
+	if cfg.ClickServiceID != "" && cfg.ClickMerchantID != "" && cfg.ClickSecretKey != "" {
+		r.add(NewClick(cfg))
+	}
+	if cfg.PaymeMerchantID != "" && cfg.PaymeKey != "" {
+		r.add(NewPayme(cfg))
+	}
+	if cfg.FakeSecret != "" {
+		r.add(NewFake(cfg))
+	}
+	return r
+}
+
+func (r *Registry) add(p Provider) {
+	r.providers[p.Name()] = p
+}
+
+// Get returns an enabled provider by name
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names lists the enabled providers in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sameAmount compares sums in som to the tiyin
+func sameAmount(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
@@ -0,0 +1,231 @@
+// Package realtime pushes order and notification events to connected clients
+// over WebSocket. Services publish through the Hub; each connection belongs to
+// the user authenticated on upgrade and only receives that user's events.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+
+	"taxi-service/internal/models"
+)
+
+const (
+	sendBuffer = 32
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+)
+
+// Event types sent to clients
+const (
+	EventOrderCreated        = "order.created"
+	EventOrderStatusChanged  = "order.status_changed"
+	EventNotificationCreated = "notification.created"
+)
+
+// Event is the JSON envelope of every message pushed to a client
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+type client struct {
+	userID int64
+	send   chan []byte
+}
+
+// Hub tracks open connections per user and fans events out to them
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[int64]map[*client]struct{}
+
+	// closing is closed by Close to send every connection away; conns counts
+	// the open ones so Close can wait for them
+	closing   chan struct{}
+	closeOnce sync.Once
+	conns     sync.WaitGroup
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{
+		clients: map[int64]map[*client]struct{}{},
+		closing: make(chan struct{}),
+	}
+}
+
+// Handler upgrades the request to a WebSocket owned by the user that the auth
+// middleware stored in the request locals
+func (h *Hub) Handler() fiber.Handler {
+	return websocket.New(h.serve)
+}
+
+// OrderCreated tells the given drivers about a new order open for acceptance
+func (h *Hub) OrderCreated(driverUserIDs []int64, order *models.Order) {
+	h.publish(driverUserIDs, Event{Type: EventOrderCreated, Data: order})
+}
+
+// OrderStatusChanged tells a user that one of their orders changed status
+func (h *Hub) OrderStatusChanged(userID int64, order *models.Order) {
+	h.publish([]int64{userID}, Event{Type: EventOrderStatusChanged, Data: order})
+}
+
+// NotificationCreated delivers a new in-app notification to its owner
+func (h *Hub) NotificationCreated(notification *models.Notification) {
+	h.publish([]int64{notification.UserID}, Event{Type: EventNotificationCreated, Data: notification})
+}
+
+// Connections returns the number of open connections
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n := 0
+	for _, clients := range h.clients {
+		n += len(clients)
+	}
+	return n
+}
+
+// Close tells every client the server is going away so it reconnects to
+// another instance, and waits until the connections are closed or ctx is
+// done. Connections opened afterwards are refused.
+func (h *Hub) Close(ctx context.Context) error {
+	h.closeOnce.Do(func() {
+		h.mu.Lock()
+		close(h.closing)
+		h.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		h.conns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d connections still open: %w", h.Connections(), ctx.Err())
+	}
+}
+
+// publish queues an event for every connection of the given users; a client
+// whose buffer is full misses the event rather than blocking the publisher
+func (h *Hub) publish(userIDs []int64, event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, userID := range userIDs {
+		for c := range h.clients[userID] {
+			select {
+			case c.send <- payload:
+			default:
+				log.Printf("WebSocket buffer full for user %d, dropping %s event", userID, event.Type)
+			}
+		}
+	}
+}
+
+// add registers a connection; it reports false once the hub is closing
+func (h *Hub) add(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	select {
+	case <-h.closing:
+		return false
+	default:
+	}
+
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = map[*client]struct{}{}
+	}
+	h.clients[c.userID][c] = struct{}{}
+	h.conns.Add(1)
+	return true
+}
+
+func (h *Hub) remove(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.conns.Done()
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+// serve runs for the lifetime of one connection: a reader goroutine handles
+// pongs and detects disconnects while this goroutine writes events and pings
+func (h *Hub) serve(conn *websocket.Conn) {
+	userID, ok := conn.Locals("user_id").(int64)
+	if !ok {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthenticated"))
+		return
+	}
+
+	goingAway := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	c := &client{userID: userID, send: make(chan []byte, sendBuffer)}
+	if !h.add(c) {
+		conn.WriteMessage(websocket.CloseMessage, goingAway)
+		return
+	}
+	defer h.remove(c)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		for {
+			// Clients have nothing to say; reading only surfaces pongs and closes
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case payload := <-c.send:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-h.closing:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			conn.WriteMessage(websocket.CloseMessage, goingAway)
+			return
+		}
+	}
+}
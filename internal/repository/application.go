@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"taxi-service/internal/models"
+)
+
+const applicationColumns = `
+	id, user_id, full_name, phone_number, car_model, car_number, license_image,
+	status, rejection_reason, reviewed_by, reviewed_at, created_at, updated_at`
+
+func scanApplication(row rowScanner) (*models.DriverApplication, error) {
+	var app models.DriverApplication
+	err := row.Scan(
+		&app.ID, &app.UserID, &app.FullName, &app.PhoneNumber, &app.CarModel, &app.CarNumber, &app.LicenseImage,
+		&app.Status, &app.RejectionReason, &app.ReviewedBy, &app.ReviewedAt, &app.CreatedAt, &app.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// ApplicationRepository reads and writes driver applications
+type ApplicationRepository struct {
+	db Querier
+}
+
+// NewApplicationRepository creates a driver application repository
+func NewApplicationRepository(db Querier) *ApplicationRepository {
+	return &ApplicationRepository{db: db}
+}
+
+// List returns applications, optionally filtered by status, newest first
+func (r *ApplicationRepository) List(status string) ([]models.DriverApplication, error) {
+	if status != "" {
+		return query(r.db, scanApplication, `SELECT `+applicationColumns+` FROM driver_applications WHERE status = $1 ORDER BY created_at DESC`, status)
+	}
+	return query(r.db, scanApplication, `SELECT `+applicationColumns+` FROM driver_applications ORDER BY created_at DESC`)
+}
+
+// HasPending reports whether the user has an application awaiting review
+func (r *ApplicationRepository) HasPending(userID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM driver_applications WHERE user_id = $1 AND status = 'pending')
+	`, userID).Scan(&exists)
+	return exists, err
+}
+
+// GetPendingForUpdate returns a pending application and locks its row until the transaction ends
+func (r *ApplicationRepository) GetPendingForUpdate(id int64) (*models.DriverApplication, error) {
+	return scanOne(r.db.QueryRow(`
+		SELECT `+applicationColumns+` FROM driver_applications
+		WHERE id = $1 AND status = 'pending'
+		FOR UPDATE
+	`, id), scanApplication)
+}
+
+// Create inserts a pending application
+func (r *ApplicationRepository) Create(app *models.DriverApplication) (*models.DriverApplication, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO driver_applications (user_id, full_name, phone_number, car_model, car_number, license_image, status)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending')
+		RETURNING `+applicationColumns,
+		app.UserID, app.FullName, app.PhoneNumber, app.CarModel, app.CarNumber, app.LicenseImage,
+	), scanApplication)
+}
+
+// Review records an admin's decision on an application
+func (r *ApplicationRepository) Review(id, adminID int64, status string, rejectionReason *string) error {
+	return affected(r.db.Exec(`
+		UPDATE driver_applications
+		SET status = $1, rejection_reason = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, status, rejectionReason, adminID, id))
+}
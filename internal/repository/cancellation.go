@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"taxi-service/internal/models"
+)
+
+const cancellationPolicyColumns = `
+	id, order_type, order_status, min_hours_before_pickup,
+	driver_refund_percent, customer_fee_percent, description, created_at, updated_at`
+
+func scanCancellationPolicy(row rowScanner) (*models.CancellationPolicy, error) {
+	var p models.CancellationPolicy
+	err := row.Scan(
+		&p.ID, &p.OrderType, &p.OrderStatus, &p.MinHoursBeforePickup,
+		&p.DriverRefundPercent, &p.CustomerFeePercent, &p.Description, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CancellationPolicyRepository reads and writes cancellation policy rules
+type CancellationPolicyRepository struct {
+	db Querier
+}
+
+// NewCancellationPolicyRepository creates a cancellation policy repository
+func NewCancellationPolicyRepository(db Querier) *CancellationPolicyRepository {
+	return &CancellationPolicyRepository{db: db}
+}
+
+// List returns all rules grouped by status, then from the earliest cutoff down
+func (r *CancellationPolicyRepository) List() ([]models.CancellationPolicy, error) {
+	return query(r.db, scanCancellationPolicy, `
+		SELECT `+cancellationPolicyColumns+` FROM cancellation_policies
+		ORDER BY order_status, order_type NULLS FIRST, min_hours_before_pickup DESC
+	`)
+}
+
+// ListForStatus returns the rules that apply to orders cancelled from status
+func (r *CancellationPolicyRepository) ListForStatus(status models.OrderStatus) ([]models.CancellationPolicy, error) {
+	return query(r.db, scanCancellationPolicy, `SELECT `+cancellationPolicyColumns+` FROM cancellation_policies WHERE order_status = $1`, status)
+}
+
+// Create inserts a rule
+func (r *CancellationPolicyRepository) Create(p *models.CancellationPolicy) (*models.CancellationPolicy, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO cancellation_policies (order_type, order_status, min_hours_before_pickup, driver_refund_percent, customer_fee_percent, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+cancellationPolicyColumns,
+		p.OrderType, p.OrderStatus, p.MinHoursBeforePickup, p.DriverRefundPercent, p.CustomerFeePercent, p.Description,
+	), scanCancellationPolicy)
+}
+
+// Update replaces every field of a rule
+func (r *CancellationPolicyRepository) Update(p *models.CancellationPolicy) (*models.CancellationPolicy, error) {
+	return scanOne(r.db.QueryRow(`
+		UPDATE cancellation_policies
+		SET order_type = $1, order_status = $2, min_hours_before_pickup = $3,
+		    driver_refund_percent = $4, customer_fee_percent = $5, description = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING `+cancellationPolicyColumns,
+		p.OrderType, p.OrderStatus, p.MinHoursBeforePickup, p.DriverRefundPercent, p.CustomerFeePercent, p.Description, p.ID,
+	), scanCancellationPolicy)
+}
+
+// Delete removes a rule
+func (r *CancellationPolicyRepository) Delete(id int64) error {
+	return affected(r.db.Exec(`DELETE FROM cancellation_policies WHERE id = $1`, id))
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"taxi-service/internal/models"
+)
+
+const driverColumns = `
+	id, user_id, full_name, car_model, car_number, license_image,
+	seat_capacity, base_latitude, base_longitude, balance, rating, total_ratings, status, is_active, created_at, updated_at`
+
+func scanDriver(row rowScanner) (*models.Driver, error) {
+	var driver models.Driver
+	err := row.Scan(
+		&driver.ID, &driver.UserID, &driver.FullName, &driver.CarModel, &driver.CarNumber, &driver.LicenseImage,
+		&driver.SeatCapacity, &driver.BaseLatitude, &driver.BaseLongitude, &driver.Balance, &driver.Rating, &driver.TotalRatings, &driver.Status, &driver.IsActive, &driver.CreatedAt, &driver.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// DriverRepository reads and writes driver profiles
+type DriverRepository struct {
+	db Querier
+}
+
+// NewDriverRepository creates a driver repository
+func NewDriverRepository(db Querier) *DriverRepository {
+	return &DriverRepository{db: db}
+}
+
+// Get returns a driver by ID
+func (r *DriverRepository) Get(id int64) (*models.Driver, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+driverColumns+` FROM drivers WHERE id = $1`, id), scanDriver)
+}
+
+// GetForUpdate returns a driver by ID and locks its row until the
+// transaction ends
+func (r *DriverRepository) GetForUpdate(id int64) (*models.Driver, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+driverColumns+` FROM drivers WHERE id = $1 FOR UPDATE`, id), scanDriver)
+}
+
+// GetByUserID returns the driver profile of a user
+func (r *DriverRepository) GetByUserID(userID int64) (*models.Driver, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+driverColumns+` FROM drivers WHERE user_id = $1`, userID), scanDriver)
+}
+
+// GetByUserIDForUpdate returns the driver profile of a user and locks its row
+// until the transaction ends
+func (r *DriverRepository) GetByUserIDForUpdate(userID int64) (*models.Driver, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+driverColumns+` FROM drivers WHERE user_id = $1 FOR UPDATE`, userID), scanDriver)
+}
+
+// List returns all drivers, optionally filtered by status, newest first
+func (r *DriverRepository) List(status string) ([]models.Driver, error) {
+	if status != "" {
+		return query(r.db, scanDriver, `SELECT `+driverColumns+` FROM drivers WHERE status = $1 ORDER BY created_at DESC`, status)
+	}
+	return query(r.db, scanDriver, `SELECT `+driverColumns+` FROM drivers ORDER BY created_at DESC`)
+}
+
+// Create inserts an approved driver profile with a zero balance
+func (r *DriverRepository) Create(userID int64, fullName, carModel, carNumber string, licenseImage *string) (*models.Driver, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO drivers (user_id, full_name, car_model, car_number, license_image, status, balance)
+		VALUES ($1, $2, $3, $4, $5, 'approved', 0)
+		RETURNING `+driverColumns,
+		userID, fullName, carModel, carNumber, licenseImage,
+	), scanDriver)
+}
+
+// DriverProfileUpdate holds the driver fields UpdateProfile changes; empty
+// strings, a zero capacity and nil coordinates are left as is
+type DriverProfileUpdate struct {
+	FullName      string
+	CarModel      string
+	CarNumber     string
+	SeatCapacity  int
+	BaseLatitude  *float64
+	BaseLongitude *float64
+}
+
+// UpdateProfile changes the driver's name, car details and base point
+func (r *DriverRepository) UpdateProfile(userID int64, u DriverProfileUpdate) (*models.Driver, error) {
+	return scanOne(r.db.QueryRow(`
+		UPDATE drivers SET
+			full_name = COALESCE(NULLIF($1, ''), full_name),
+			car_model = COALESCE(NULLIF($2, ''), car_model),
+			car_number = COALESCE(NULLIF($3, ''), car_number),
+			seat_capacity = COALESCE(NULLIF($4, 0), seat_capacity),
+			base_latitude = COALESCE($5, base_latitude),
+			base_longitude = COALESCE($6, base_longitude),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $7
+		RETURNING `+driverColumns,
+		u.FullName, u.CarModel, u.CarNumber, u.SeatCapacity, u.BaseLatitude, u.BaseLongitude, userID,
+	), scanDriver)
+}
+
+// AddBalance adds a (possibly negative) amount to the driver's cached balance.
+// Only the ledger posting code should call it, next to the matching entries.
+func (r *DriverRepository) AddBalance(id int64, amount float64) error {
+	return affected(r.db.Exec(`
+		UPDATE drivers SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, amount, id))
+}
+
+// Debit subtracts amount from the driver's cached balance only if the balance
+// covers it; ErrNotFound means the driver is missing or the balance is too low.
+// Like AddBalance it is only called next to the matching ledger entries.
+func (r *DriverRepository) Debit(id int64, amount float64) error {
+	return affected(r.db.Exec(`
+		UPDATE drivers SET balance = balance - $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND balance >= $1
+	`, amount, id))
+}
+
+// RefreshRating recomputes the driver's average rating from the ratings table
+func (r *DriverRepository) RefreshRating(id int64) error {
+	return affected(r.db.Exec(`
+		UPDATE drivers SET
+			rating = (SELECT COALESCE(AVG(rating), 0) FROM ratings WHERE driver_id = $1),
+			total_ratings = (SELECT COUNT(*) FROM ratings WHERE driver_id = $1),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id))
+}
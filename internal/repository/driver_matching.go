@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+func scanDriverRoute(row rowScanner) (*models.DriverRoute, error) {
+	var route models.DriverRoute
+	if err := row.Scan(&route.ID, &route.DriverID, &route.FromRegionID, &route.ToRegionID, &route.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func scanDriverAvailability(row rowScanner) (*models.DriverAvailability, error) {
+	var window models.DriverAvailability
+	err := row.Scan(&window.ID, &window.DriverID, &window.Date, &window.StartTime, &window.EndTime, &window.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// ListRoutes returns the region pairs a driver serves
+func (r *DriverRepository) ListRoutes(driverID int64) ([]models.DriverRoute, error) {
+	return query(r.db, scanDriverRoute, `
+		SELECT id, driver_id, from_region_id, to_region_id, created_at
+		FROM driver_routes WHERE driver_id = $1 ORDER BY from_region_id, to_region_id
+	`, driverID)
+}
+
+// ReplaceRoutes swaps the region pairs a driver serves for the given ones;
+// run it in a transaction so a failed insert keeps the old set
+func (r *DriverRepository) ReplaceRoutes(driverID int64, routes []models.DriverRoute) error {
+	if _, err := r.db.Exec(`DELETE FROM driver_routes WHERE driver_id = $1`, driverID); err != nil {
+		return err
+	}
+	for _, route := range routes {
+		_, err := r.db.Exec(`
+			INSERT INTO driver_routes (driver_id, from_region_id, to_region_id) VALUES ($1, $2, $3)
+			ON CONFLICT (driver_id, from_region_id, to_region_id) DO NOTHING
+		`, driverID, route.FromRegionID, route.ToRegionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAvailability returns a driver's availability windows on or after a
+// date, soonest first
+func (r *DriverRepository) ListAvailability(driverID int64, from time.Time) ([]models.DriverAvailability, error) {
+	return query(r.db, scanDriverAvailability, `
+		SELECT id, driver_id, available_date, start_time, end_time, created_at
+		FROM driver_availability WHERE driver_id = $1 AND available_date >= $2
+		ORDER BY available_date, start_time
+	`, driverID, from)
+}
+
+// ListAvailabilityOn returns every driver's availability windows on a date
+func (r *DriverRepository) ListAvailabilityOn(date time.Time) ([]models.DriverAvailability, error) {
+	return query(r.db, scanDriverAvailability, `
+		SELECT id, driver_id, available_date, start_time, end_time, created_at
+		FROM driver_availability WHERE available_date = $1
+		ORDER BY driver_id, start_time
+	`, date)
+}
+
+// ReplaceAvailability swaps a driver's availability windows, past ones
+// included, for the given ones; run it in a transaction
+func (r *DriverRepository) ReplaceAvailability(driverID int64, windows []models.DriverAvailability) error {
+	if _, err := r.db.Exec(`DELETE FROM driver_availability WHERE driver_id = $1`, driverID); err != nil {
+		return err
+	}
+	for _, window := range windows {
+		_, err := r.db.Exec(`
+			INSERT INTO driver_availability (driver_id, available_date, start_time, end_time) VALUES ($1, $2, $3, $4)
+		`, driverID, window.Date, window.StartTime, window.EndTime)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListMatching returns the approved, active, unblocked drivers an order can
+// be offered to: their car has room for its passengers, they serve its
+// region pair or have not picked any, and they are available on its date or
+// have not declared any windows on or after from, the caller's today. Times
+// within the date are left to the caller.
+func (r *DriverRepository) ListMatching(order *models.Order, from time.Time) ([]models.Driver, error) {
+	seats := int64(0)
+	if order.OrderType == models.OrderTypeTaxi && order.PassengerCount != nil {
+		seats = *order.PassengerCount
+	}
+	return query(r.db, scanDriver, `
+		SELECT `+driverColumns+` FROM drivers d
+		WHERE d.status = 'approved' AND d.is_active = true
+		  AND d.user_id IN (SELECT id FROM users WHERE role = $1 AND is_blocked = false)
+		  AND d.seat_capacity >= $2
+		  AND (NOT EXISTS (SELECT 1 FROM driver_routes r WHERE r.driver_id = d.id)
+		       OR EXISTS (SELECT 1 FROM driver_routes r
+		                  WHERE r.driver_id = d.id AND r.from_region_id = $3 AND r.to_region_id = $4))
+		  AND (NOT EXISTS (SELECT 1 FROM driver_availability a WHERE a.driver_id = d.id AND a.available_date >= $6)
+		       OR EXISTS (SELECT 1 FROM driver_availability a WHERE a.driver_id = d.id AND a.available_date = $5))
+	`, models.RoleDriver, seats, order.FromRegionID, order.ToRegionID, order.ScheduledDate, from)
+}
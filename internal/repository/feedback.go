@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"taxi-service/internal/models"
+)
+
+const feedbackColumns = `id, user_id, message, created_at`
+
+func scanFeedback(row rowScanner) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := row.Scan(&feedback.ID, &feedback.UserID, &feedback.Message, &feedback.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+// FeedbackRepository reads and writes user feedback
+type FeedbackRepository struct {
+	db Querier
+}
+
+// NewFeedbackRepository creates a feedback repository
+func NewFeedbackRepository(db Querier) *FeedbackRepository {
+	return &FeedbackRepository{db: db}
+}
+
+// Create stores a feedback message
+func (r *FeedbackRepository) Create(userID int64, message string) (*models.Feedback, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO feedback (user_id, message)
+		VALUES ($1, $2)
+		RETURNING `+feedbackColumns,
+		userID, message,
+	), scanFeedback)
+}
+
+// List returns all feedback, newest first
+func (r *FeedbackRepository) List() ([]models.Feedback, error) {
+	return query(r.db, scanFeedback, `SELECT `+feedbackColumns+` FROM feedback ORDER BY created_at DESC`)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, run_at, locked_until, last_error, created_at, updated_at`
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var payload []byte
+	err := row.Scan(
+		&job.ID, &job.Kind, &payload, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.RunAt, &job.LockedUntil, &job.LastError, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	return &job, nil
+}
+
+// JobRepository reads and writes the background job queue
+type JobRepository struct {
+	db Querier
+}
+
+// NewJobRepository creates a job repository
+func NewJobRepository(db Querier) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue adds a job that is due right away and returns its ID
+func (r *JobRepository) Enqueue(kind string, payload []byte, maxAttempts int) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`
+		INSERT INTO jobs (kind, payload, max_attempts) VALUES ($1, $2, $3) RETURNING id
+	`, kind, string(payload), maxAttempts).Scan(&id)
+	return id, err
+}
+
+// ClaimNext takes the oldest due job, or a running one whose lease ran out,
+// and leases it to the caller. Rows other workers are claiming are skipped
+// rather than waited on. It returns ErrNotFound when nothing is due.
+func (r *JobRepository) ClaimNext(lease time.Duration) (*models.Job, error) {
+	return scanOne(r.db.QueryRow(`
+		UPDATE jobs SET
+			status = $1, attempts = attempts + 1,
+			locked_until = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond',
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = $3 AND run_at <= CURRENT_TIMESTAMP)
+			   OR (status = $1 AND locked_until < CURRENT_TIMESTAMP)
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns,
+		models.JobRunning, lease.Milliseconds(), models.JobPending,
+	), scanJob)
+}
+
+// Complete deletes a finished job. attempt fences off a worker whose lease
+// ran out: ErrNotFound means another worker has taken the job over since.
+func (r *JobRepository) Complete(id int64, attempt int) error {
+	return affected(r.db.Exec(`DELETE FROM jobs WHERE id = $1 AND attempts = $2`, id, attempt))
+}
+
+// Retry puts a failed job back in the queue to run again after delay
+func (r *JobRepository) Retry(id int64, attempt int, delay time.Duration, lastError string) error {
+	return affected(r.db.Exec(`
+		UPDATE jobs SET
+			status = $1, run_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond',
+			locked_until = NULL, last_error = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND attempts = $5
+	`, models.JobPending, delay.Milliseconds(), lastError, id, attempt))
+}
+
+// Fail gives up on a job that ran out of attempts
+func (r *JobRepository) Fail(id int64, attempt int, lastError string) error {
+	return affected(r.db.Exec(`
+		UPDATE jobs SET status = $1, locked_until = NULL, last_error = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND attempts = $4
+	`, models.JobFailed, lastError, id, attempt))
+}
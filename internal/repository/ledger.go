@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"fmt"
+
+	"taxi-service/internal/models"
+)
+
+const ledgerTransactionColumns = `id, kind, order_id, description, created_by, created_at`
+
+func scanLedgerTransaction(row rowScanner) (*models.LedgerTransaction, error) {
+	var t models.LedgerTransaction
+	err := row.Scan(&t.ID, &t.Kind, &t.OrderID, &t.Description, &t.CreatedBy, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+const ledgerEntryColumns = `id, transaction_id, account_id, amount`
+
+func scanLedgerEntry(row rowScanner) (*models.LedgerEntry, error) {
+	var e models.LedgerEntry
+	err := row.Scan(&e.ID, &e.TransactionID, &e.AccountID, &e.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// walletTransactionColumns select a driver's side of a ledger transaction from
+// ledger_entries e joined with ledger_accounts a and ledger_transactions t
+const walletTransactionColumns = `t.id, a.driver_id, t.order_id, t.kind, e.amount, t.description, t.created_by, t.created_at`
+
+func scanWalletTransaction(row rowScanner) (*models.WalletTransaction, error) {
+	var t models.WalletTransaction
+	err := row.Scan(&t.ID, &t.DriverID, &t.OrderID, &t.Kind, &t.Amount, &t.Description, &t.CreatedBy, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// platformAccountNames names the platform accounts when they are first used
+var platformAccountNames = map[string]string{
+	models.AccountPlatformRevenue: "Service fees kept by the platform",
+	models.AccountDriverFunding:   "Money paid in by drivers",
+	models.AccountOpeningBalances: "Balances carried over from before the ledger",
+	models.AccountFareReceipts:    "Fares paid by card or wallet",
+	models.AccountCustomerFunding: "Money paid in by customers",
+}
+
+// WalletFilter narrows down a driver's wallet history; zero values are ignored
+type WalletFilter struct {
+	DriverID int64
+	Kind     string
+	FromDate string // YYYY-MM-DD, compared against created_at
+	ToDate   string // YYYY-MM-DD, compared against created_at
+	BeforeID int64  // Only postings older than this ledger transaction (paging cursor)
+	Limit    int
+}
+
+// LedgerDrift is a driver whose cached balance disagrees with their ledger
+type LedgerDrift struct {
+	DriverID      int64
+	FullName      string
+	Balance       float64 // drivers.balance
+	LedgerBalance float64 // sum of the entries on the driver's wallet
+}
+
+// LedgerImbalance is a ledger transaction whose entries do not sum to zero
+type LedgerImbalance struct {
+	TransactionID int64
+	Total         float64
+}
+
+// LedgerRepository writes and reads the double-entry ledger of driver money
+type LedgerRepository struct {
+	db Querier
+}
+
+// NewLedgerRepository creates a ledger repository
+func NewLedgerRepository(db Querier) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// DriverAccount returns the wallet account of a driver, opening it on first use
+func (r *LedgerRepository) DriverAccount(driverID int64) (int64, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO ledger_accounts (driver_id, name) VALUES ($1, $2)
+		ON CONFLICT (driver_id) DO NOTHING
+	`, driverID, fmt.Sprintf("Wallet of driver %d", driverID))
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = r.db.QueryRow(`SELECT id FROM ledger_accounts WHERE driver_id = $1`, driverID).Scan(&id)
+	return id, err
+}
+
+// CustomerAccount returns the wallet account of a customer, opening it on first use
+func (r *LedgerRepository) CustomerAccount(userID int64) (int64, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO ledger_accounts (user_id, name) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID, fmt.Sprintf("Wallet of customer %d", userID))
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = r.db.QueryRow(`SELECT id FROM ledger_accounts WHERE user_id = $1`, userID).Scan(&id)
+	return id, err
+}
+
+// AccountBalance returns the sum of the entries on an account
+func (r *LedgerRepository) AccountBalance(accountID int64) (float64, error) {
+	var balance float64
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account_id = $1`, accountID).Scan(&balance)
+	return balance, err
+}
+
+// LockAccount holds the account row until the transaction ends, so that a
+// balance read after it stays true until then
+func (r *LedgerRepository) LockAccount(accountID int64) error {
+	var id int64
+	return r.db.QueryRow(`SELECT id FROM ledger_accounts WHERE id = $1 FOR UPDATE`, accountID).Scan(&id)
+}
+
+// PlatformAccount returns a platform account by code, opening it on first use
+func (r *LedgerRepository) PlatformAccount(code string) (int64, error) {
+	name, ok := platformAccountNames[code]
+	if !ok {
+		name = code
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO ledger_accounts (code, name) VALUES ($1, $2)
+		ON CONFLICT (code) DO NOTHING
+	`, code, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = r.db.QueryRow(`SELECT id FROM ledger_accounts WHERE code = $1`, code).Scan(&id)
+	return id, err
+}
+
+// CreateTransaction writes a ledger transaction and its entries. The database
+// rejects the commit if the entries do not sum to zero.
+func (r *LedgerRepository) CreateTransaction(t *models.LedgerTransaction) (*models.LedgerTransaction, error) {
+	created, err := scanOne(r.db.QueryRow(`
+		INSERT INTO ledger_transactions (kind, order_id, description, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+ledgerTransactionColumns,
+		t.Kind, t.OrderID, t.Description, t.CreatedBy,
+	), scanLedgerTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range t.Entries {
+		entry, err := scanOne(r.db.QueryRow(`
+			INSERT INTO ledger_entries (transaction_id, account_id, amount)
+			VALUES ($1, $2, $3)
+			RETURNING `+ledgerEntryColumns,
+			created.ID, e.AccountID, e.Amount,
+		), scanLedgerEntry)
+		if err != nil {
+			return nil, err
+		}
+		created.Entries = append(created.Entries, *entry)
+	}
+	return created, nil
+}
+
+// ListForDriver returns the postings on a driver's wallet, newest first
+func (r *LedgerRepository) ListForDriver(driverID int64) ([]models.WalletTransaction, error) {
+	return query(r.db, scanWalletTransaction, `
+		SELECT `+walletTransactionColumns+`
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE a.driver_id = $1
+		ORDER BY t.created_at DESC, t.id DESC
+	`, driverID)
+}
+
+// ListWallet returns the postings on a driver's wallet matching the filter,
+// newest first. Ledger transaction IDs only grow, so the last ID of a page is
+// a stable cursor for the next one.
+func (r *LedgerRepository) ListWallet(filter WalletFilter) ([]models.WalletTransaction, error) {
+	q := `
+		SELECT ` + walletTransactionColumns + `
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE a.driver_id = $1`
+	args := []interface{}{filter.DriverID}
+
+	add := func(clause string, value interface{}) {
+		args = append(args, value)
+		q += fmt.Sprintf(clause, len(args))
+	}
+
+	if filter.Kind != "" {
+		add(" AND t.kind = $%d", filter.Kind)
+	}
+	if filter.FromDate != "" {
+		add(" AND DATE(t.created_at) >= $%d", filter.FromDate)
+	}
+	if filter.ToDate != "" {
+		add(" AND DATE(t.created_at) <= $%d", filter.ToDate)
+	}
+	if filter.BeforeID != 0 {
+		add(" AND t.id < $%d", filter.BeforeID)
+	}
+
+	q += " ORDER BY t.id DESC"
+	if filter.Limit > 0 {
+		add(" LIMIT $%d", filter.Limit)
+	}
+
+	return query(r.db, scanWalletTransaction, q, args...)
+}
+
+// WalletBalanceBefore sums a driver's wallet entries posted before a date
+// (YYYY-MM-DD): the balance the driver had when that day started
+func (r *LedgerRepository) WalletBalanceBefore(driverID int64, date string) (float64, error) {
+	var balance float64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(e.amount), 0)
+		FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE a.driver_id = $1 AND DATE(t.created_at) < $2
+	`, driverID, date).Scan(&balance)
+	return balance, err
+}
+
+// ListDrift returns the drivers whose cached balance differs from the sum of
+// their wallet entries
+func (r *LedgerRepository) ListDrift() ([]LedgerDrift, error) {
+	rows, err := r.db.Query(`
+		SELECT d.id, d.full_name, d.balance, COALESCE(SUM(e.amount), 0)
+		FROM drivers d
+		LEFT JOIN ledger_accounts a ON a.driver_id = d.id
+		LEFT JOIN ledger_entries e ON e.account_id = a.id
+		GROUP BY d.id, d.full_name, d.balance
+		HAVING d.balance <> COALESCE(SUM(e.amount), 0)
+		ORDER BY d.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAll(rows, func(row rowScanner) (*LedgerDrift, error) {
+		var d LedgerDrift
+		if err := row.Scan(&d.DriverID, &d.FullName, &d.Balance, &d.LedgerBalance); err != nil {
+			return nil, err
+		}
+		return &d, nil
+	})
+}
+
+// ListImbalances returns ledger transactions whose entries do not sum to zero.
+// The balance trigger should make this impossible; reconcile checks anyway.
+func (r *LedgerRepository) ListImbalances() ([]LedgerImbalance, error) {
+	rows, err := r.db.Query(`
+		SELECT transaction_id, SUM(amount)
+		FROM ledger_entries
+		GROUP BY transaction_id
+		HAVING SUM(amount) <> 0
+		ORDER BY transaction_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAll(rows, func(row rowScanner) (*LedgerImbalance, error) {
+		var i LedgerImbalance
+		if err := row.Scan(&i.TransactionID, &i.Total); err != nil {
+			return nil, err
+		}
+		return &i, nil
+	})
+}
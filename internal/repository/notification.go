@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"taxi-service/internal/models"
+)
+
+// notificationBatchSize caps the rows of one multi-row notification insert
+const notificationBatchSize = 500
+
+const notificationColumns = `id, user_id, title, message, type, related_id, is_read, created_at`
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var notif models.Notification
+	err := row.Scan(
+		&notif.ID, &notif.UserID, &notif.Title, &notif.Message,
+		&notif.Type, &notif.RelatedID, &notif.IsRead, &notif.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &notif, nil
+}
+
+// NotificationRepository reads and writes in-app notifications
+type NotificationRepository struct {
+	db Querier
+}
+
+// NewNotificationRepository creates a notification repository
+func NewNotificationRepository(db Querier) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores a notification for a user; relatedID may be 0 when there is no related record
+func (r *NotificationRepository) Create(userID int64, title, message, notifType string, relatedID int64) (*models.Notification, error) {
+	var related *int64
+	if relatedID != 0 {
+		related = &relatedID
+	}
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO notifications (user_id, title, message, type, related_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+notificationColumns,
+		userID, title, message, notifType, related,
+	), scanNotification)
+}
+
+// CreateBatch stores the same notification for many users with one
+// multi-row insert per batch. Run it in a transaction so a failed batch
+// leaves none behind.
+func (r *NotificationRepository) CreateBatch(userIDs []int64, title, message, notifType string, relatedID int64) ([]models.Notification, error) {
+	var related *int64
+	if relatedID != 0 {
+		related = &relatedID
+	}
+
+	created := make([]models.Notification, 0, len(userIDs))
+	for start := 0; start < len(userIDs); start += notificationBatchSize {
+		batch := userIDs[start:min(start+notificationBatchSize, len(userIDs))]
+
+		// $1-$4 are shared by every row, user IDs follow
+		args := []interface{}{title, message, notifType, related}
+		rows := make([]string, 0, len(batch))
+		for _, userID := range batch {
+			args = append(args, userID)
+			rows = append(rows, fmt.Sprintf("($%d, $1, $2, $3, $4)", len(args)))
+		}
+
+		notifications, err := query(r.db, scanNotification, `
+			INSERT INTO notifications (user_id, title, message, type, related_id)
+			VALUES `+strings.Join(rows, ", ")+`
+			RETURNING `+notificationColumns,
+			args...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, notifications...)
+	}
+	return created, nil
+}
+
+// ListForUser returns the user's notifications, newest first
+func (r *NotificationRepository) ListForUser(userID int64, unreadOnly bool) ([]models.Notification, error) {
+	q := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = $1`
+	if unreadOnly {
+		q += " AND is_read = false"
+	}
+	q += " ORDER BY created_at DESC"
+	return query(r.db, scanNotification, q, userID)
+}
+
+// MarkRead marks one of the user's notifications as read
+func (r *NotificationRepository) MarkRead(userID, id int64) error {
+	return affected(r.db.Exec(`UPDATE notifications SET is_read = true WHERE id = $1 AND user_id = $2`, id, userID))
+}
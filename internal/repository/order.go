@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+const orderColumns = `
+	id, user_id, driver_id, order_type, status,
+	customer_name, customer_phone, recipient_phone,
+	from_region_id, from_district_id, from_latitude, from_longitude, from_address,
+	to_region_id, to_district_id, to_latitude, to_longitude, to_address,
+	passenger_count, delivery_type, scheduled_date, time_range_start, time_range_end,
+	price, service_fee, discount_percentage, final_price,
+	notes, cancellation_reason, cancellation_policy_id, cancellation_refund, cancellation_fee,
+	payment_method, payment_status, paid_at, payment_note, trip_id,
+	accepted_at, started_at, accept_deadline, dispatch_attempts, completed_at, cancelled_at, created_at, updated_at`
+
+func scanOrder(row rowScanner) (*models.Order, error) {
+	var order models.Order
+	err := row.Scan(
+		&order.ID, &order.UserID, &order.DriverID, &order.OrderType, &order.Status,
+		&order.CustomerName, &order.CustomerPhone, &order.RecipientPhone,
+		&order.FromRegionID, &order.FromDistrictID, &order.FromLatitude, &order.FromLongitude, &order.FromAddress,
+		&order.ToRegionID, &order.ToDistrictID, &order.ToLatitude, &order.ToLongitude, &order.ToAddress,
+		&order.PassengerCount, &order.DeliveryType, &order.ScheduledDate, &order.TimeRangeStart, &order.TimeRangeEnd,
+		&order.Price, &order.ServiceFee, &order.DiscountPercentage, &order.FinalPrice,
+		&order.Notes, &order.CancellationReason, &order.CancellationPolicyID, &order.CancellationRefund, &order.CancellationFee,
+		&order.PaymentMethod, &order.PaymentStatus, &order.PaidAt, &order.PaymentNote, &order.TripID,
+		&order.AcceptedAt, &order.StartedAt, &order.AcceptDeadline, &order.DispatchAttempts, &order.CompletedAt, &order.CancelledAt, &order.CreatedAt, &order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// OrderFilter narrows down an order listing; zero values are ignored
+type OrderFilter struct {
+	UserID       int64
+	DriverID     int64
+	Status       string
+	Type         string
+	FromRegionID int64
+	ToRegionID   int64
+	FromDate     string // YYYY-MM-DD, compared against created_at
+	ToDate       string // YYYY-MM-DD, compared against created_at
+	TripID       int64
+
+	PaymentStatus string
+	PaymentMethod string
+	// OutstandingPayment keeps only completed orders whose fare is unpaid or
+	// disputed and cancelled orders whose cancellation fee is owed
+	OutstandingPayment bool
+
+	// OpenForAcceptance keeps only orders whose accept deadline has not passed
+	OpenForAcceptance bool
+	// MatchDriverID keeps only orders on the driver's routes whose passengers
+	// fit in the driver's car
+	MatchDriverID int64
+}
+
+// OrderRepository reads and writes orders
+type OrderRepository struct {
+	db Querier
+}
+
+// NewOrderRepository creates an order repository
+func NewOrderRepository(db Querier) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// Get returns an order by ID
+func (r *OrderRepository) Get(id int64) (*models.Order, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id = $1`, id), scanOrder)
+}
+
+// GetForUpdate returns an order by ID and locks its row until the transaction ends
+func (r *OrderRepository) GetForUpdate(id int64) (*models.Order, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id = $1 FOR UPDATE`, id), scanOrder)
+}
+
+// GetForUser returns an order only if it was placed by the given user
+func (r *OrderRepository) GetForUser(id, userID int64) (*models.Order, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id = $1 AND user_id = $2`, id, userID), scanOrder)
+}
+
+// List returns the orders matching the filter, newest first
+func (r *OrderRepository) List(filter OrderFilter) ([]models.Order, error) {
+	q := `SELECT ` + orderColumns + ` FROM orders WHERE 1=1`
+	args := []interface{}{}
+	argCount := 0
+
+	add := func(clause string, value interface{}) {
+		argCount++
+		q += fmt.Sprintf(clause, argCount)
+		args = append(args, value)
+	}
+
+	if filter.UserID != 0 {
+		add(" AND user_id = $%d", filter.UserID)
+	}
+	if filter.DriverID != 0 {
+		add(" AND driver_id = $%d", filter.DriverID)
+	}
+	if filter.Status != "" {
+		add(" AND status = $%d", filter.Status)
+	}
+	if filter.Type != "" {
+		add(" AND order_type = $%d", filter.Type)
+	}
+	if filter.FromRegionID != 0 {
+		add(" AND from_region_id = $%d", filter.FromRegionID)
+	}
+	if filter.ToRegionID != 0 {
+		add(" AND to_region_id = $%d", filter.ToRegionID)
+	}
+	if filter.TripID != 0 {
+		add(" AND trip_id = $%d", filter.TripID)
+	}
+	if filter.FromDate != "" {
+		add(" AND DATE(created_at) >= $%d", filter.FromDate)
+	}
+	if filter.ToDate != "" {
+		add(" AND DATE(created_at) <= $%d", filter.ToDate)
+	}
+	if filter.PaymentStatus != "" {
+		add(" AND payment_status = $%d", filter.PaymentStatus)
+	}
+	if filter.PaymentMethod != "" {
+		add(" AND payment_method = $%d", filter.PaymentMethod)
+	}
+	if filter.OutstandingPayment {
+		q += ` AND ((status = 'completed' AND payment_status IN ('unpaid', 'disputed'))
+			OR (status = 'cancelled' AND payment_status = 'owed'))`
+	}
+	if filter.OpenForAcceptance {
+		q += " AND (accept_deadline IS NULL OR accept_deadline > CURRENT_TIMESTAMP)"
+	}
+	if filter.MatchDriverID != 0 {
+		add(` AND (order_type <> 'taxi' OR passenger_count <= (SELECT seat_capacity FROM drivers WHERE id = $%[1]d))
+			AND (NOT EXISTS (SELECT 1 FROM driver_routes r WHERE r.driver_id = $%[1]d)
+			     OR EXISTS (SELECT 1 FROM driver_routes r
+			                WHERE r.driver_id = $%[1]d AND r.from_region_id = orders.from_region_id AND r.to_region_id = orders.to_region_id))`,
+			filter.MatchDriverID)
+	}
+
+	q += " ORDER BY created_at DESC"
+
+	return query(r.db, scanOrder, q, args...)
+}
+
+// Create inserts a new order and fills in its ID and timestamps
+func (r *OrderRepository) Create(order *models.Order) error {
+	return r.db.QueryRow(`
+		INSERT INTO orders (
+			user_id, order_type, status, customer_name, customer_phone, recipient_phone,
+			from_region_id, from_district_id, from_latitude, from_longitude, from_address,
+			to_region_id, to_district_id, to_latitude, to_longitude, to_address,
+			passenger_count, delivery_type, scheduled_date, time_range_start, time_range_end,
+			price, service_fee, discount_percentage, final_price, notes, accept_deadline,
+			payment_method, payment_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)
+		RETURNING id, created_at, updated_at
+	`, order.UserID, order.OrderType, order.Status, order.CustomerName, order.CustomerPhone, order.RecipientPhone,
+		order.FromRegionID, order.FromDistrictID, order.FromLatitude, order.FromLongitude, order.FromAddress,
+		order.ToRegionID, order.ToDistrictID, order.ToLatitude, order.ToLongitude, order.ToAddress,
+		order.PassengerCount, order.DeliveryType, order.ScheduledDate, order.TimeRangeStart, order.TimeRangeEnd,
+		order.Price, order.ServiceFee, order.DiscountPercentage, order.FinalPrice, order.Notes, order.AcceptDeadline,
+		order.PaymentMethod, order.PaymentStatus,
+	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+}
+
+// ListOverdueForUpdate locks up to limit pending orders whose accept deadline
+// has passed. Rows locked by another transaction are skipped, so several
+// instances can sweep at the same time without handling an order twice.
+func (r *OrderRepository) ListOverdueForUpdate(limit int) ([]models.Order, error) {
+	return query(r.db, scanOrder, `
+		SELECT `+orderColumns+` FROM orders
+		WHERE status = $1 AND accept_deadline <= CURRENT_TIMESTAMP
+		ORDER BY accept_deadline
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, models.OrderStatusPending, limit)
+}
+
+// ExtendDeadline reopens a pending order for acceptance until deadline and
+// counts the re-dispatch
+func (r *OrderRepository) ExtendDeadline(id int64, deadline time.Time) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET accept_deadline = $1, dispatch_attempts = dispatch_attempts + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+	`, deadline, id, models.OrderStatusPending))
+}
+
+// Assign gives a pending order to a driver
+func (r *OrderRepository) Assign(id, driverID int64) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET driver_id = $1, status = $2, accepted_at = CURRENT_TIMESTAMP,
+		                  accept_deadline = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = $4
+	`, driverID, models.OrderStatusAccepted, id, models.OrderStatusPending))
+}
+
+// Release takes an accepted order away from its driver and trip and reopens
+// it for acceptance until deadline with a fresh re-dispatch count
+func (r *OrderRepository) Release(id int64, deadline time.Time) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET driver_id = NULL, trip_id = NULL, status = $1, accepted_at = NULL, accept_deadline = $2,
+		                  dispatch_attempts = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = $4
+	`, models.OrderStatusPending, deadline, id, models.OrderStatusAccepted))
+}
+
+// SetTrip books an order on a driver's trip
+func (r *OrderRepository) SetTrip(id, tripID int64) error {
+	return affected(r.db.Exec(`UPDATE orders SET trip_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, tripID, id))
+}
+
+// ListOnTripForUpdate locks the accepted and in-progress orders booked on a
+// trip until the transaction ends
+func (r *OrderRepository) ListOnTripForUpdate(tripID int64) ([]models.Order, error) {
+	return query(r.db, scanOrder, `
+		SELECT `+orderColumns+` FROM orders
+		WHERE trip_id = $1 AND status IN ($2, $3)
+		ORDER BY id
+		FOR UPDATE
+	`, tripID, models.OrderStatusAccepted, models.OrderStatusInProgress)
+}
+
+// ListTripCandidates returns the pending orders that could join a trip: on
+// its region pair and date, still open for acceptance and needing at most
+// seats seats, oldest first. Deliveries need no seat.
+func (r *OrderRepository) ListTripCandidates(trip *models.Trip, seats int) ([]models.Order, error) {
+	return query(r.db, scanOrder, `
+		SELECT `+orderColumns+` FROM orders
+		WHERE status = $1 AND from_region_id = $2 AND to_region_id = $3 AND scheduled_date = $4
+		  AND (accept_deadline IS NULL OR accept_deadline > CURRENT_TIMESTAMP)
+		  AND COALESCE(passenger_count, 0) <= $5
+		ORDER BY created_at
+	`, models.OrderStatusPending, trip.FromRegionID, trip.ToRegionID, trip.ScheduledDate, seats)
+}
+
+// Start marks an accepted order as picked up
+func (r *OrderRepository) Start(id int64) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET status = $1, started_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+	`, models.OrderStatusInProgress, id, models.OrderStatusAccepted))
+}
+
+// Complete marks an order in progress as completed
+func (r *OrderRepository) Complete(id int64) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET status = $1, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+	`, models.OrderStatusCompleted, id, models.OrderStatusInProgress))
+}
+
+// Cancel marks an order as cancelled; the caller has checked that its current
+// status allows it
+func (r *OrderRepository) Cancel(id int64, reason string) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET status = $1, cancellation_reason = $2, accept_deadline = NULL,
+		                  cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, models.OrderStatusCancelled, reason, id))
+}
+
+// SetCancellationOutcome stores what the cancellation of an order cost the
+// driver and the customer
+func (r *OrderRepository) SetCancellationOutcome(id int64, policyID *int64, refund, fee float64) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET cancellation_policy_id = $1, cancellation_refund = $2, cancellation_fee = $3
+		WHERE id = $4
+	`, policyID, refund, fee, id))
+}
+
+// SetPaymentStatus records a change of an order's payment status. paid_at is
+// set when the fare is paid; note replaces the stored dispute or resolution
+// note when given.
+func (r *OrderRepository) SetPaymentStatus(id int64, status models.PaymentStatus, note *string) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET
+			payment_status = $1,
+			paid_at = CASE WHEN $1 = 'paid' THEN CURRENT_TIMESTAMP ELSE paid_at END,
+			payment_note = COALESCE($2, payment_note),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, note, id))
+}
+
+// Expire closes a pending order that no driver accepted
+func (r *OrderRepository) Expire(id int64) error {
+	return affected(r.db.Exec(`
+		UPDATE orders SET status = $1, accept_deadline = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+	`, models.OrderStatusExpired, id, models.OrderStatusPending))
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"taxi-service/internal/models"
+)
+
+const orderEventColumns = `id, order_id, event_type, from_status, to_status, actor_type, actor_id, reason, details, created_at`
+
+func scanOrderEvent(row rowScanner) (*models.OrderEvent, error) {
+	var e models.OrderEvent
+	var details []byte
+	err := row.Scan(&e.ID, &e.OrderID, &e.Type, &e.FromStatus, &e.ToStatus, &e.ActorType, &e.ActorID, &e.Reason, &details, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	e.Details = details
+	return &e, nil
+}
+
+// OrderEventRepository records the audit trail of orders
+type OrderEventRepository struct {
+	db Querier
+}
+
+// NewOrderEventRepository creates an order event repository
+func NewOrderEventRepository(db Querier) *OrderEventRepository {
+	return &OrderEventRepository{db: db}
+}
+
+// Create appends an event to an order's trail; empty details are stored as {}
+func (r *OrderEventRepository) Create(e *models.OrderEvent) (*models.OrderEvent, error) {
+	details := "{}"
+	if len(e.Details) > 0 {
+		details = string(e.Details)
+	}
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO order_events (order_id, event_type, from_status, to_status, actor_type, actor_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+orderEventColumns,
+		e.OrderID, e.Type, e.FromStatus, e.ToStatus, e.ActorType, e.ActorID, e.Reason, details,
+	), scanOrderEvent)
+}
+
+// ListForOrder returns the events of an order, oldest first
+func (r *OrderEventRepository) ListForOrder(orderID int64) ([]models.OrderEvent, error) {
+	return query(r.db, scanOrderEvent, `SELECT `+orderEventColumns+` FROM order_events WHERE order_id = $1 ORDER BY created_at, id`, orderID)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/models"
+)
+
+const pricingColumns = `id, from_region_id, to_region_id, strategy, base_price, price_per_person, price_per_km, service_fee, created_at, updated_at`
+
+func scanPricing(row rowScanner) (*models.Pricing, error) {
+	var pricing models.Pricing
+	err := row.Scan(
+		&pricing.ID, &pricing.FromRegionID, &pricing.ToRegionID, &pricing.Strategy, &pricing.BasePrice,
+		&pricing.PricePerPerson, &pricing.PricePerKm, &pricing.ServiceFee, &pricing.CreatedAt, &pricing.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pricing, nil
+}
+
+const districtPricingColumns = `id, from_district_id, to_district_id, base_price, price_per_person, price_per_km, created_at, updated_at`
+
+func scanDistrictPricing(row rowScanner) (*models.DistrictPricing, error) {
+	var p models.DistrictPricing
+	err := row.Scan(
+		&p.ID, &p.FromDistrictID, &p.ToDistrictID, &p.BasePrice,
+		&p.PricePerPerson, &p.PricePerKm, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+const surchargeColumns = `id, kind, start_time, end_time, holiday_date, percent, description, created_at, updated_at`
+
+func scanSurcharge(row rowScanner) (*models.PricingSurcharge, error) {
+	var s models.PricingSurcharge
+	err := row.Scan(
+		&s.ID, &s.Kind, &s.StartTime, &s.EndTime, &s.HolidayDate,
+		&s.Percent, &s.Description, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// PricingRepository reads and writes route pricing, district overrides,
+// surcharges and passenger discounts
+type PricingRepository struct {
+	db Querier
+}
+
+// NewPricingRepository creates a pricing repository
+func NewPricingRepository(db Querier) *PricingRepository {
+	return &PricingRepository{db: db}
+}
+
+// GetRoute returns the pricing of a route
+func (r *PricingRepository) GetRoute(fromRegionID, toRegionID int64) (*models.Pricing, error) {
+	return scanOne(r.db.QueryRow(`
+		SELECT `+pricingColumns+` FROM pricing WHERE from_region_id = $1 AND to_region_id = $2
+	`, fromRegionID, toRegionID), scanPricing)
+}
+
+// List returns all configured routes, newest first
+func (r *PricingRepository) List() ([]models.Pricing, error) {
+	return query(r.db, scanPricing, `SELECT `+pricingColumns+` FROM pricing ORDER BY created_at DESC`)
+}
+
+// Upsert creates or updates the pricing of a route
+func (r *PricingRepository) Upsert(p *models.Pricing) (*models.Pricing, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO pricing (from_region_id, to_region_id, strategy, base_price, price_per_person, price_per_km, service_fee)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (from_region_id, to_region_id)
+		DO UPDATE SET strategy = $3, base_price = $4, price_per_person = $5, price_per_km = $6, service_fee = $7, updated_at = CURRENT_TIMESTAMP
+		RETURNING `+pricingColumns,
+		p.FromRegionID, p.ToRegionID, p.Strategy, p.BasePrice, p.PricePerPerson, p.PricePerKm, p.ServiceFee,
+	), scanPricing)
+}
+
+// GetDistrictOverride returns the rates of a district pair
+func (r *PricingRepository) GetDistrictOverride(fromDistrictID, toDistrictID int64) (*models.DistrictPricing, error) {
+	return scanOne(r.db.QueryRow(`
+		SELECT `+districtPricingColumns+` FROM district_pricing WHERE from_district_id = $1 AND to_district_id = $2
+	`, fromDistrictID, toDistrictID), scanDistrictPricing)
+}
+
+// ListDistrictOverrides returns all district overrides, newest first
+func (r *PricingRepository) ListDistrictOverrides() ([]models.DistrictPricing, error) {
+	return query(r.db, scanDistrictPricing, `SELECT `+districtPricingColumns+` FROM district_pricing ORDER BY created_at DESC`)
+}
+
+// UpsertDistrictOverride creates or updates the rates of a district pair
+func (r *PricingRepository) UpsertDistrictOverride(p *models.DistrictPricing) (*models.DistrictPricing, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO district_pricing (from_district_id, to_district_id, base_price, price_per_person, price_per_km)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (from_district_id, to_district_id)
+		DO UPDATE SET base_price = $3, price_per_person = $4, price_per_km = $5, updated_at = CURRENT_TIMESTAMP
+		RETURNING `+districtPricingColumns,
+		p.FromDistrictID, p.ToDistrictID, p.BasePrice, p.PricePerPerson, p.PricePerKm,
+	), scanDistrictPricing)
+}
+
+// DeleteDistrictOverride removes a district override
+func (r *PricingRepository) DeleteDistrictOverride(id int64) error {
+	return affected(r.db.Exec(`DELETE FROM district_pricing WHERE id = $1`, id))
+}
+
+// ListSurcharges returns all surcharges, night ones first
+func (r *PricingRepository) ListSurcharges() ([]models.PricingSurcharge, error) {
+	return query(r.db, scanSurcharge, `
+		SELECT `+surchargeColumns+` FROM pricing_surcharges
+		ORDER BY kind DESC, start_time, holiday_date
+	`)
+}
+
+// CreateSurcharge inserts a surcharge
+func (r *PricingRepository) CreateSurcharge(s *models.PricingSurcharge) (*models.PricingSurcharge, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO pricing_surcharges (kind, start_time, end_time, holiday_date, percent, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+surchargeColumns,
+		s.Kind, s.StartTime, s.EndTime, s.HolidayDate, s.Percent, s.Description,
+	), scanSurcharge)
+}
+
+// UpdateSurcharge replaces every field of a surcharge
+func (r *PricingRepository) UpdateSurcharge(s *models.PricingSurcharge) (*models.PricingSurcharge, error) {
+	return scanOne(r.db.QueryRow(`
+		UPDATE pricing_surcharges
+		SET kind = $1, start_time = $2, end_time = $3, holiday_date = $4, percent = $5, description = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING `+surchargeColumns,
+		s.Kind, s.StartTime, s.EndTime, s.HolidayDate, s.Percent, s.Description, s.ID,
+	), scanSurcharge)
+}
+
+// DeleteSurcharge removes a surcharge
+func (r *PricingRepository) DeleteSurcharge(id int64) error {
+	return affected(r.db.Exec(`DELETE FROM pricing_surcharges WHERE id = $1`, id))
+}
+
+// DiscountFor returns the discount percentage for a passenger count, or 0 if none is configured
+func (r *PricingRepository) DiscountFor(passengerCount int) (float64, error) {
+	var discount float64
+	err := r.db.QueryRow(`
+		SELECT discount_percentage FROM discounts WHERE passenger_count = $1
+	`, passengerCount).Scan(&discount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return discount, err
+}
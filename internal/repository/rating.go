@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"taxi-service/internal/models"
+)
+
+const ratingColumns = `id, order_id, user_id, driver_id, rating, comment, created_at`
+
+func scanRating(row rowScanner) (*models.Rating, error) {
+	var rating models.Rating
+	err := row.Scan(
+		&rating.ID, &rating.OrderID, &rating.UserID, &rating.DriverID,
+		&rating.Rating, &rating.Comment, &rating.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// RatingRepository reads and writes driver ratings
+type RatingRepository struct {
+	db Querier
+}
+
+// NewRatingRepository creates a rating repository
+func NewRatingRepository(db Querier) *RatingRepository {
+	return &RatingRepository{db: db}
+}
+
+// ExistsForOrder reports whether an order has already been rated
+func (r *RatingRepository) ExistsForOrder(orderID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM ratings WHERE order_id = $1)`, orderID).Scan(&exists)
+	return exists, err
+}
+
+// Create inserts a rating
+func (r *RatingRepository) Create(rating *models.Rating) (*models.Rating, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO ratings (order_id, user_id, driver_id, rating, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+ratingColumns,
+		rating.OrderID, rating.UserID, rating.DriverID, rating.Rating, rating.Comment,
+	), scanRating)
+}
+
+// ListForDriver returns all ratings of a driver, newest first
+func (r *RatingRepository) ListForDriver(driverID int64) ([]models.Rating, error) {
+	return query(r.db, scanRating, `SELECT `+ratingColumns+` FROM ratings WHERE driver_id = $1 ORDER BY created_at DESC`, driverID)
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"taxi-service/internal/models"
+)
+
+const regionColumns = `id, name_uz_lat, name_uz_cyr, name_ru, created_at`
+
+const districtColumns = `id, region_id, name_uz_lat, name_uz_cyr, name_ru, created_at`
+
+func scanRegion(row rowScanner) (*models.Region, error) {
+	var region models.Region
+	err := row.Scan(&region.ID, &region.NameUzLat, &region.NameUzCyr, &region.NameRu, &region.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+func scanDistrict(row rowScanner) (*models.District, error) {
+	var district models.District
+	err := row.Scan(&district.ID, &district.RegionID, &district.NameUzLat, &district.NameUzCyr, &district.NameRu, &district.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &district, nil
+}
+
+// Names holds the localized names of a region or district; on update, empty
+// names are left unchanged
+type Names struct {
+	UzLat string
+	UzCyr string
+	Ru    string
+}
+
+// RegionRepository reads and writes regions and districts
+type RegionRepository struct {
+	db Querier
+}
+
+// NewRegionRepository creates a region repository
+func NewRegionRepository(db Querier) *RegionRepository {
+	return &RegionRepository{db: db}
+}
+
+// ListRegions returns all regions ordered by name
+func (r *RegionRepository) ListRegions() ([]models.Region, error) {
+	return query(r.db, scanRegion, `SELECT `+regionColumns+` FROM regions ORDER BY name_uz_lat`)
+}
+
+// GetRegion returns a region by ID
+func (r *RegionRepository) GetRegion(id int64) (*models.Region, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+regionColumns+` FROM regions WHERE id = $1`, id), scanRegion)
+}
+
+// CreateRegion inserts a region
+func (r *RegionRepository) CreateRegion(names Names) (*models.Region, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO regions (name_uz_lat, name_uz_cyr, name_ru)
+		VALUES ($1, $2, $3)
+		RETURNING `+regionColumns,
+		names.UzLat, names.UzCyr, names.Ru,
+	), scanRegion)
+}
+
+// UpdateRegion renames a region
+func (r *RegionRepository) UpdateRegion(id int64, names Names) (*models.Region, error) {
+	return updateNames(r.db, "regions", regionColumns, id, names, scanRegion)
+}
+
+// DeleteRegion removes a region
+func (r *RegionRepository) DeleteRegion(id int64) error {
+	return affected(r.db.Exec(`DELETE FROM regions WHERE id = $1`, id))
+}
+
+// ListDistricts returns the districts of a region ordered by name
+func (r *RegionRepository) ListDistricts(regionID int64) ([]models.District, error) {
+	return query(r.db, scanDistrict, `SELECT `+districtColumns+` FROM districts WHERE region_id = $1 ORDER BY name_uz_lat`, regionID)
+}
+
+// GetDistrict returns a district by ID
+func (r *RegionRepository) GetDistrict(id int64) (*models.District, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+districtColumns+` FROM districts WHERE id = $1`, id), scanDistrict)
+}
+
+// CreateDistrict inserts a district into a region
+func (r *RegionRepository) CreateDistrict(regionID int64, names Names) (*models.District, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO districts (region_id, name_uz_lat, name_uz_cyr, name_ru)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+districtColumns,
+		regionID, names.UzLat, names.UzCyr, names.Ru,
+	), scanDistrict)
+}
+
+// UpdateDistrict renames a district
+func (r *RegionRepository) UpdateDistrict(id int64, names Names) (*models.District, error) {
+	return updateNames(r.db, "districts", districtColumns, id, names, scanDistrict)
+}
+
+// DeleteDistrict removes a district
+func (r *RegionRepository) DeleteDistrict(id int64) error {
+	return affected(r.db.Exec(`DELETE FROM districts WHERE id = $1`, id))
+}
+
+// updateNames updates the non-empty names of a region or district and returns
+// the updated row; with nothing to change it returns the row as is
+func updateNames[T any](db Querier, table, columns string, id int64, names Names, scan func(rowScanner) (*T, error)) (*T, error) {
+	sets := []string{}
+	args := []interface{}{}
+	argCount := 0
+
+	for _, field := range []struct {
+		column string
+		value  string
+	}{
+		{"name_uz_lat", names.UzLat},
+		{"name_uz_cyr", names.UzCyr},
+		{"name_ru", names.Ru},
+	} {
+		if field.value == "" {
+			continue
+		}
+		argCount++
+		sets = append(sets, fmt.Sprintf("%s = $%d", field.column, argCount))
+		args = append(args, field.value)
+	}
+
+	if len(sets) == 0 {
+		return scanOne(db.QueryRow(`SELECT `+columns+` FROM `+table+` WHERE id = $1`, id), scan)
+	}
+
+	argCount++
+	q := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d RETURNING %s", table, strings.Join(sets, ", "), argCount, columns)
+	args = append(args, id)
+	return scanOne(db.QueryRow(q, args...), scan)
+}
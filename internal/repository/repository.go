@@ -0,0 +1,95 @@
+// Package repository maps database rows to models. Every model has exactly one
+// column list and one row mapper; queries select those columns explicitly so a
+// schema change cannot silently shift positional scans.
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned when a lookup matches no row
+var ErrNotFound = errors.New("record not found")
+
+// Querier is satisfied by both *sql.DB and *sql.Tx, so repositories can run
+// inside a caller's transaction
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanOne maps a single-row result, translating sql.ErrNoRows to ErrNotFound
+func scanOne[T any](row *sql.Row, scan func(rowScanner) (*T, error)) (*T, error) {
+	item, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return item, err
+}
+
+// scanAll maps every row of a result set; the first scan error aborts the
+// whole read rather than dropping the row
+func scanAll[T any](rows *sql.Rows, scan func(rowScanner) (*T, error)) ([]T, error) {
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return items, nil
+}
+
+// query runs a select and maps all returned rows
+func query[T any](db Querier, scan func(rowScanner) (*T, error), q string, args ...interface{}) ([]T, error) {
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanAll(rows, scan)
+}
+
+// scanIDs reads a single-column result of IDs
+func scanIDs(rows *sql.Rows) ([]int64, error) {
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return ids, nil
+}
+
+// affected turns a zero-row UPDATE or DELETE into ErrNotFound
+func affected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+const topUpColumns = `
+	id, driver_id, order_id, provider, amount, status, provider_ref, provider_created_at, failure_reason,
+	ledger_transaction_id, confirmed_at, failed_at, created_at, updated_at`
+
+func scanTopUp(row rowScanner) (*models.TopUp, error) {
+	var t models.TopUp
+	err := row.Scan(
+		&t.ID, &t.DriverID, &t.OrderID, &t.Provider, &t.Amount, &t.Status, &t.ProviderRef, &t.ProviderCreatedAt, &t.FailureReason,
+		&t.LedgerTransactionID, &t.ConfirmedAt, &t.FailedAt, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// TopUpRepository reads and writes driver top-ups
+type TopUpRepository struct {
+	db Querier
+}
+
+// NewTopUpRepository creates a top-up repository
+func NewTopUpRepository(db Querier) *TopUpRepository {
+	return &TopUpRepository{db: db}
+}
+
+// Create inserts a pending top-up of a driver's wallet
+func (r *TopUpRepository) Create(driverID int64, provider string, amount float64) (*models.TopUp, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO top_ups (driver_id, provider, amount, status)
+		VALUES ($1, $2, $3, 'pending')
+		RETURNING `+topUpColumns,
+		driverID, provider, amount,
+	), scanTopUp)
+}
+
+// CreateForOrder inserts a pending card payment of an order's fare
+func (r *TopUpRepository) CreateForOrder(orderID int64, provider string, amount float64) (*models.TopUp, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO top_ups (order_id, provider, amount, status)
+		VALUES ($1, $2, $3, 'pending')
+		RETURNING `+topUpColumns,
+		orderID, provider, amount,
+	), scanTopUp)
+}
+
+// Get returns a top-up by ID
+func (r *TopUpRepository) Get(id int64) (*models.TopUp, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+topUpColumns+` FROM top_ups WHERE id = $1`, id), scanTopUp)
+}
+
+// GetForUpdate returns a top-up and locks its row until the transaction ends
+func (r *TopUpRepository) GetForUpdate(id int64) (*models.TopUp, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+topUpColumns+` FROM top_ups WHERE id = $1 FOR UPDATE`, id), scanTopUp)
+}
+
+// GetByRef returns the top-up a provider transaction is attached to
+func (r *TopUpRepository) GetByRef(provider, ref string) (*models.TopUp, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+topUpColumns+` FROM top_ups WHERE provider = $1 AND provider_ref = $2`, provider, ref), scanTopUp)
+}
+
+// ListForDriver returns a driver's top-ups, newest first
+func (r *TopUpRepository) ListForDriver(driverID int64) ([]models.TopUp, error) {
+	return query(r.db, scanTopUp, `SELECT `+topUpColumns+` FROM top_ups WHERE driver_id = $1 ORDER BY created_at DESC, id DESC`, driverID)
+}
+
+// ListAttached returns a provider's top-ups whose provider transaction was
+// attached in [from, to), oldest first
+func (r *TopUpRepository) ListAttached(provider string, from, to time.Time) ([]models.TopUp, error) {
+	return query(r.db, scanTopUp, `
+		SELECT `+topUpColumns+` FROM top_ups
+		WHERE provider = $1 AND provider_created_at >= $2 AND provider_created_at < $3
+		ORDER BY provider_created_at, id
+	`, provider, from, to)
+}
+
+// ListStaleForUpdate locks up to limit pending top-ups created before the
+// cutoff, skipping rows another sweep or callback already holds
+func (r *TopUpRepository) ListStaleForUpdate(before time.Time, limit int) ([]models.TopUp, error) {
+	return query(r.db, scanTopUp, `
+		SELECT `+topUpColumns+` FROM top_ups
+		WHERE status = 'pending' AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, before, limit)
+}
+
+// Attach records the provider transaction paying for a pending top-up.
+// Attaching the same reference again is a no-op; ErrNotFound means the
+// top-up is no longer pending or another transaction is already attached.
+func (r *TopUpRepository) Attach(id int64, ref string) error {
+	return affected(r.db.Exec(`
+		UPDATE top_ups SET
+			provider_ref = $2,
+			provider_created_at = COALESCE(provider_created_at, CURRENT_TIMESTAMP),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending' AND (provider_ref IS NULL OR provider_ref = $2)
+	`, id, ref))
+}
+
+// Confirm moves a pending top-up to confirmed with the ledger credit that paid it out
+func (r *TopUpRepository) Confirm(id, ledgerTransactionID int64) error {
+	return affected(r.db.Exec(`
+		UPDATE top_ups SET status = 'confirmed', ledger_transaction_id = $2,
+			confirmed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+	`, id, ledgerTransactionID))
+}
+
+// Fail moves a pending top-up to failed
+func (r *TopUpRepository) Fail(id int64, reason string) error {
+	return affected(r.db.Exec(`
+		UPDATE top_ups SET status = 'failed', failure_reason = $2,
+			failed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+	`, id, reason))
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+const tripColumns = `
+	id, driver_id, from_region_id, to_region_id, scheduled_date, departure_start, departure_end,
+	seats_total, seats_booked, price_per_seat, status, notes, cancellation_reason, created_at, updated_at`
+
+func scanTrip(row rowScanner) (*models.Trip, error) {
+	var t models.Trip
+	err := row.Scan(
+		&t.ID, &t.DriverID, &t.FromRegionID, &t.ToRegionID, &t.ScheduledDate, &t.DepartureStart, &t.DepartureEnd,
+		&t.SeatsTotal, &t.SeatsBooked, &t.PricePerSeat, &t.Status, &t.Notes, &t.CancellationReason, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// TripRepository reads and writes the trips drivers publish and their seat
+// inventory
+type TripRepository struct {
+	db Querier
+}
+
+// NewTripRepository creates a trip repository
+func NewTripRepository(db Querier) *TripRepository {
+	return &TripRepository{db: db}
+}
+
+// Get returns a trip by ID
+func (r *TripRepository) Get(id int64) (*models.Trip, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+tripColumns+` FROM trips WHERE id = $1`, id), scanTrip)
+}
+
+// GetForUpdate returns a trip by ID and locks its row until the transaction
+// ends, so seats are counted by one booking at a time
+func (r *TripRepository) GetForUpdate(id int64) (*models.Trip, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+tripColumns+` FROM trips WHERE id = $1 FOR UPDATE`, id), scanTrip)
+}
+
+// ListForDriver returns a driver's trips, optionally only those with the
+// given status, soonest first
+func (r *TripRepository) ListForDriver(driverID int64, status string) ([]models.Trip, error) {
+	if status != "" {
+		return query(r.db, scanTrip, `
+			SELECT `+tripColumns+` FROM trips WHERE driver_id = $1 AND status = $2
+			ORDER BY scheduled_date DESC, departure_start
+		`, driverID, status)
+	}
+	return query(r.db, scanTrip, `
+		SELECT `+tripColumns+` FROM trips WHERE driver_id = $1
+		ORDER BY scheduled_date DESC, departure_start
+	`, driverID)
+}
+
+// SearchOpen returns the open trips on a region pair and date with at least
+// seats seats left, earliest departure first
+func (r *TripRepository) SearchOpen(fromRegionID, toRegionID int64, date time.Time, seats int) ([]models.Trip, error) {
+	return query(r.db, scanTrip, `
+		SELECT `+tripColumns+` FROM trips
+		WHERE status = $1 AND from_region_id = $2 AND to_region_id = $3 AND scheduled_date = $4
+		  AND seats_total - seats_booked >= $5
+		ORDER BY departure_start, price_per_seat
+	`, models.TripOpen, fromRegionID, toRegionID, date, seats)
+}
+
+// Create inserts a new trip and fills in its ID, status and timestamps
+func (r *TripRepository) Create(t *models.Trip) error {
+	return r.db.QueryRow(`
+		INSERT INTO trips (
+			driver_id, from_region_id, to_region_id, scheduled_date, departure_start, departure_end,
+			seats_total, price_per_seat, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, status, created_at, updated_at
+	`, t.DriverID, t.FromRegionID, t.ToRegionID, t.ScheduledDate, t.DepartureStart, t.DepartureEnd,
+		t.SeatsTotal, t.PricePerSeat, t.Notes,
+	).Scan(&t.ID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// ReserveSeats books seats on an open trip. It returns ErrNotFound when the
+// trip is not open or has fewer seats left.
+func (r *TripRepository) ReserveSeats(id int64, seats int) error {
+	return affected(r.db.Exec(`
+		UPDATE trips SET seats_booked = seats_booked + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3 AND seats_booked + $1 <= seats_total
+	`, seats, id, models.TripOpen))
+}
+
+// FreeSeats gives seats of a trip back, e.g. when one of its orders is
+// cancelled or released
+func (r *TripRepository) FreeSeats(id int64, seats int) error {
+	return affected(r.db.Exec(`
+		UPDATE trips SET seats_booked = GREATEST(seats_booked - $1, 0), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, seats, id))
+}
+
+// SetStatus moves a trip to a new status; reason is stored when cancelling
+func (r *TripRepository) SetStatus(id int64, status models.TripStatus, reason *string) error {
+	return affected(r.db.Exec(`
+		UPDATE trips SET status = $1, cancellation_reason = COALESCE($2, cancellation_reason), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, reason, id))
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/models"
+)
+
+// userColumns never includes the password hash; see GetPasswordHash
+const userColumns = `id, phone_number, name, role, language, avatar, is_blocked, created_at, updated_at`
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID, &user.PhoneNumber, &user.Name, &user.Role, &user.Language,
+		&user.Avatar, &user.IsBlocked, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UserRepository reads and writes user accounts
+type UserRepository struct {
+	db Querier
+}
+
+// NewUserRepository creates a user repository
+func NewUserRepository(db Querier) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Get returns a user by ID
+func (r *UserRepository) Get(id int64) (*models.User, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1`, id), scanUser)
+}
+
+// GetByPhone returns a user by phone number
+func (r *UserRepository) GetByPhone(phoneNumber string) (*models.User, error) {
+	return scanOne(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE phone_number = $1`, phoneNumber), scanUser)
+}
+
+// GetPasswordHash returns the password hash of a user
+func (r *UserRepository) GetPasswordHash(id int64) (string, error) {
+	var hash string
+	err := r.db.QueryRow(`SELECT password FROM users WHERE id = $1`, id).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return hash, err
+}
+
+// GetAvatarForUpdate returns the user's avatar path and locks the row until the transaction ends
+func (r *UserRepository) GetAvatarForUpdate(id int64) (*string, error) {
+	user, err := scanOne(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1 FOR UPDATE`, id), scanUser)
+	if err != nil {
+		return nil, err
+	}
+	return user.Avatar, nil
+}
+
+// Create inserts a new user
+func (r *UserRepository) Create(phoneNumber, name, passwordHash string, role models.UserRole, language models.Language) (*models.User, error) {
+	return scanOne(r.db.QueryRow(`
+		INSERT INTO users (phone_number, name, password, role, language)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+userColumns,
+		phoneNumber, name, passwordHash, role, language,
+	), scanUser)
+}
+
+// UpdateProfile changes the user's name and language; empty values are left as is
+func (r *UserRepository) UpdateProfile(id int64, name string, language models.Language) (*models.User, error) {
+	return scanOne(r.db.QueryRow(`
+		UPDATE users SET
+			name = COALESCE(NULLIF($1, ''), name),
+			language = COALESCE(NULLIF($2, ''), language),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING `+userColumns,
+		name, language, id,
+	), scanUser)
+}
+
+// SetPassword stores a new password hash
+func (r *UserRepository) SetPassword(id int64, passwordHash string) error {
+	return affected(r.db.Exec(`UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, passwordHash, id))
+}
+
+// SetAvatar stores a new avatar path
+func (r *UserRepository) SetAvatar(id int64, avatarPath string) error {
+	return affected(r.db.Exec(`UPDATE users SET avatar = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, avatarPath, id))
+}
+
+// SetRole changes the user's role
+func (r *UserRepository) SetRole(id int64, role models.UserRole) error {
+	return affected(r.db.Exec(`UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, role, id))
+}
+
+// SetBlocked blocks or unblocks a user
+func (r *UserRepository) SetBlocked(id int64, blocked bool) error {
+	return affected(r.db.Exec(`UPDATE users SET is_blocked = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, blocked, id))
+}
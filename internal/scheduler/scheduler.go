@@ -0,0 +1,73 @@
+// Package scheduler runs periodic background jobs such as the overdue order
+// sweep. Each job runs in its own goroutine; a run never overlaps with the
+// previous run of the same job.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler owns a set of periodic jobs and stops them together
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler with no jobs
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel}
+}
+
+// Every starts running job at the given interval until Stop is called. Errors
+// are logged and the job keeps its schedule. The context passed to job is
+// cancelled by Stop, so a run in progress can stop early.
+func (s *Scheduler) Every(name string, interval time.Duration, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		log.Printf("Scheduler: job %s disabled (interval %s)", name, interval)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(s.ctx); err != nil {
+					log.Printf("Scheduler: job %s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop cancels all jobs and waits for running ones to finish, or until ctx is
+// done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("running jobs abandoned: %w", ctx.Err())
+	}
+}
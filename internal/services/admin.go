@@ -0,0 +1,664 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+	"taxi-service/internal/utils"
+)
+
+// AdminService implements the admin console operations
+type AdminService struct {
+	db           *sql.DB
+	users        *repository.UserRepository
+	drivers      *repository.DriverRepository
+	orders       *repository.OrderRepository
+	applications *repository.ApplicationRepository
+	pricing      *repository.PricingRepository
+	regions      *repository.RegionRepository
+	feedback     *repository.FeedbackRepository
+	policies     *repository.CancellationPolicyRepository
+	ledger       *repository.LedgerRepository
+	events       Events
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(db *sql.DB, events Events) *AdminService {
+	return &AdminService{
+		db:           db,
+		users:        repository.NewUserRepository(db),
+		drivers:      repository.NewDriverRepository(db),
+		orders:       repository.NewOrderRepository(db),
+		applications: repository.NewApplicationRepository(db),
+		pricing:      repository.NewPricingRepository(db),
+		regions:      repository.NewRegionRepository(db),
+		feedback:     repository.NewFeedbackRepository(db),
+		policies:     repository.NewCancellationPolicyRepository(db),
+		ledger:       repository.NewLedgerRepository(db),
+		events:       events,
+	}
+}
+
+// ReviewApplicationInput holds an admin's decision on a driver application
+type ReviewApplicationInput struct {
+	Status          string // approved or rejected
+	RejectionReason string
+}
+
+// SetPricingInput holds the pricing of a route
+type SetPricingInput struct {
+	FromRegionID   int64
+	ToRegionID     int64
+	Strategy       string // flat or distance; empty means flat
+	BasePrice      float64
+	PricePerPerson float64
+	PricePerKm     float64
+	ServiceFee     float64
+}
+
+// DistrictPricingInput holds the rates of a district pair
+type DistrictPricingInput struct {
+	FromDistrictID int64
+	ToDistrictID   int64
+	BasePrice      float64
+	PricePerPerson float64
+	PricePerKm     float64
+}
+
+// SurchargeInput holds a pricing surcharge. Night surcharges need StartTime
+// and EndTime (HH:MM), holiday surcharges HolidayDate (YYYY-MM-DD).
+type SurchargeInput struct {
+	Kind        string
+	StartTime   string
+	EndTime     string
+	HolidayDate string
+	Percent     float64
+	Description string
+}
+
+// AdminOrderFilter narrows down the admin order listing; dates are YYYY-MM-DD
+type AdminOrderFilter struct {
+	Status        string
+	Type          string
+	FromDate      string
+	ToDate        string
+	PaymentStatus string
+	PaymentMethod string
+}
+
+// CancellationPolicyInput holds a cancellation policy rule; an empty OrderType
+// applies the rule to every order type
+type CancellationPolicyInput struct {
+	OrderType            string
+	OrderStatus          string
+	MinHoursBeforePickup float64
+	DriverRefundPercent  float64
+	CustomerFeePercent   float64
+	Description          string
+}
+
+// CreateAdminInput holds the data needed to create an admin account
+type CreateAdminInput struct {
+	PhoneNumber string
+	Name        string
+	Password    string
+}
+
+// AdminStatistics represents admin statistics
+type AdminStatistics struct {
+	TotalUsers      int     `json:"total_users"`
+	TotalDrivers    int     `json:"total_drivers"`
+	ActiveDrivers   int     `json:"active_drivers"`
+	TotalOrders     int     `json:"total_orders"`
+	CompletedOrders int     `json:"completed_orders"`
+	TotalRevenue    float64 `json:"total_revenue"`
+	TodayOrders     int     `json:"today_orders"`
+	TodayRevenue    float64 `json:"today_revenue"`
+}
+
+// ListDriverApplications returns driver applications, optionally filtered by status
+func (s *AdminService) ListDriverApplications(status string) ([]models.DriverApplication, error) {
+	applications, err := s.applications.List(status)
+	if err != nil {
+		return nil, internal("Failed to fetch applications", err)
+	}
+	return applications, nil
+}
+
+// ReviewDriverApplication approves or rejects a pending application in one transaction;
+// approval promotes the user to driver and creates the driver profile
+func (s *AdminService) ReviewDriverApplication(adminID, applicationID int64, in ReviewApplicationInput) error {
+	if in.Status != "approved" && in.Status != "rejected" {
+		return invalid("Status must be approved or rejected")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the application so two admins cannot review it at once
+	applications := repository.NewApplicationRepository(tx)
+	application, err := applications.GetPendingForUpdate(applicationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Application not found or already reviewed")
+	}
+	if err != nil {
+		return internal("Database error", err)
+	}
+
+	var rejectionReason *string
+	if in.Status == "rejected" && in.RejectionReason != "" {
+		rejectionReason = &in.RejectionReason
+	}
+
+	if err := applications.Review(application.ID, adminID, in.Status, rejectionReason); err != nil {
+		return internal("Failed to update application", err)
+	}
+
+	if in.Status == "approved" {
+		if err := repository.NewUserRepository(tx).SetRole(application.UserID, models.RoleDriver); err != nil {
+			return internal("Failed to update user role", err)
+		}
+
+		_, err = repository.NewDriverRepository(tx).Create(application.UserID, application.FullName, application.CarModel, application.CarNumber, &application.LicenseImage)
+		if err != nil {
+			return internal("Failed to create driver profile", err)
+		}
+	}
+
+	notifMessage := "Your driver application has been approved!"
+	if in.Status == "rejected" {
+		notifMessage = "Your driver application has been rejected."
+		if in.RejectionReason != "" {
+			notifMessage += " Reason: " + in.RejectionReason
+		}
+	}
+	notification, err := repository.NewNotificationRepository(tx).Create(application.UserID, "Driver Application Status", notifMessage, "application_review", application.ID)
+	if err != nil {
+		return internal("Failed to create notification", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal("Failed to commit transaction", err)
+	}
+
+	// Push only once the notification is visible to other connections
+	s.events.NotificationCreated(notification)
+	return nil
+}
+
+// ListDrivers returns all drivers, optionally filtered by status
+func (s *AdminService) ListDrivers(status string) ([]models.Driver, error) {
+	drivers, err := s.drivers.List(status)
+	if err != nil {
+		return nil, internal("Failed to fetch drivers", err)
+	}
+	return drivers, nil
+}
+
+// AddDriverBalance tops up a driver's wallet through the ledger
+func (s *AdminService) AddDriverBalance(adminID, driverID int64, amount float64) error {
+	if amount <= 0 {
+		return invalid("Amount must be greater than zero")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	_, err = postToWallet(tx, walletPosting{
+		Kind:        models.LedgerTopUp,
+		DriverID:    driverID,
+		Amount:      amount,
+		Counterpart: models.AccountDriverFunding,
+		Description: "Balance added by admin",
+		CreatedBy:   &adminID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal("Failed to commit transaction", err)
+	}
+	return nil
+}
+
+// DriverTransactions returns a page of a driver's wallet history, newest first
+func (s *AdminService) DriverTransactions(driverID int64, q WalletQuery) (*WalletPage, error) {
+	_, err := s.drivers.Get(driverID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return listWallet(s.ledger, driverID, q)
+}
+
+// SetUserBlocked blocks or unblocks a user
+func (s *AdminService) SetUserBlocked(userID int64, blocked bool) error {
+	err := s.users.SetBlocked(userID, blocked)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("User not found")
+	}
+	if err != nil {
+		return internal("Failed to update user", err)
+	}
+	return nil
+}
+
+// SetPricing creates or updates the pricing of a route
+func (s *AdminService) SetPricing(in SetPricingInput) (*models.Pricing, error) {
+	if in.FromRegionID == in.ToRegionID {
+		return nil, invalid("From and To regions must be different")
+	}
+	strategy := models.PricingStrategy(in.Strategy)
+	if strategy == "" {
+		strategy = models.PricingFlat
+	}
+	if _, ok := pricingStrategies[strategy]; !ok {
+		return nil, invalid("Unknown pricing strategy")
+	}
+
+	pricing, err := s.pricing.Upsert(&models.Pricing{
+		FromRegionID:   in.FromRegionID,
+		ToRegionID:     in.ToRegionID,
+		Strategy:       strategy,
+		BasePrice:      in.BasePrice,
+		PricePerPerson: in.PricePerPerson,
+		PricePerKm:     in.PricePerKm,
+		ServiceFee:     in.ServiceFee,
+	})
+	if err != nil {
+		return nil, internal("Failed to set pricing", err)
+	}
+	return pricing, nil
+}
+
+// ListPricing returns all configured routes
+func (s *AdminService) ListPricing() ([]models.Pricing, error) {
+	pricings, err := s.pricing.List()
+	if err != nil {
+		return nil, internal("Failed to fetch pricing", err)
+	}
+	return pricings, nil
+}
+
+// ListDistrictPricing returns every district override
+func (s *AdminService) ListDistrictPricing() ([]models.DistrictPricing, error) {
+	overrides, err := s.pricing.ListDistrictOverrides()
+	if err != nil {
+		return nil, internal("Failed to fetch district pricing", err)
+	}
+	return overrides, nil
+}
+
+// SetDistrictPricing creates or updates the rates of a district pair. They
+// replace the rates of the region route between the districts' regions,
+// which keeps its strategy and service fee.
+func (s *AdminService) SetDistrictPricing(in DistrictPricingInput) (*models.DistrictPricing, error) {
+	from, err := s.regions.GetDistrict(in.FromDistrictID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("From district not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	to, err := s.regions.GetDistrict(in.ToDistrictID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("To district not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if from.RegionID == to.RegionID {
+		return nil, invalid("Districts must be in different regions")
+	}
+
+	override, err := s.pricing.UpsertDistrictOverride(&models.DistrictPricing{
+		FromDistrictID: in.FromDistrictID,
+		ToDistrictID:   in.ToDistrictID,
+		BasePrice:      in.BasePrice,
+		PricePerPerson: in.PricePerPerson,
+		PricePerKm:     in.PricePerKm,
+	})
+	if err != nil {
+		return nil, internal("Failed to set district pricing", err)
+	}
+	return override, nil
+}
+
+// DeleteDistrictPricing removes a district override
+func (s *AdminService) DeleteDistrictPricing(id int64) error {
+	err := s.pricing.DeleteDistrictOverride(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("District pricing not found")
+	}
+	if err != nil {
+		return internal("Failed to delete district pricing", err)
+	}
+	return nil
+}
+
+// ListSurcharges returns every pricing surcharge
+func (s *AdminService) ListSurcharges() ([]models.PricingSurcharge, error) {
+	surcharges, err := s.pricing.ListSurcharges()
+	if err != nil {
+		return nil, internal("Failed to fetch surcharges", err)
+	}
+	return surcharges, nil
+}
+
+// CreateSurcharge adds a pricing surcharge
+func (s *AdminService) CreateSurcharge(in SurchargeInput) (*models.PricingSurcharge, error) {
+	surcharge, err := in.toModel()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.pricing.CreateSurcharge(surcharge)
+	if err != nil {
+		return nil, internal("Failed to create surcharge", err)
+	}
+	return created, nil
+}
+
+// UpdateSurcharge replaces a pricing surcharge
+func (s *AdminService) UpdateSurcharge(id int64, in SurchargeInput) (*models.PricingSurcharge, error) {
+	surcharge, err := in.toModel()
+	if err != nil {
+		return nil, err
+	}
+	surcharge.ID = id
+
+	updated, err := s.pricing.UpdateSurcharge(surcharge)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Surcharge not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to update surcharge", err)
+	}
+	return updated, nil
+}
+
+// DeleteSurcharge removes a pricing surcharge
+func (s *AdminService) DeleteSurcharge(id int64) error {
+	err := s.pricing.DeleteSurcharge(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Surcharge not found")
+	}
+	if err != nil {
+		return internal("Failed to delete surcharge", err)
+	}
+	return nil
+}
+
+func (in SurchargeInput) toModel() (*models.PricingSurcharge, error) {
+	if in.Percent <= 0 || in.Percent > 100 {
+		return nil, invalid("Percent must be greater than 0 and at most 100")
+	}
+
+	surcharge := &models.PricingSurcharge{Kind: models.SurchargeKind(in.Kind), Percent: in.Percent}
+	switch surcharge.Kind {
+	case models.SurchargeNight:
+		start, okStart := minuteOfDay(in.StartTime)
+		end, okEnd := minuteOfDay(in.EndTime)
+		if !okStart || !okEnd {
+			return nil, invalid("Night surcharges need start_time and end_time as HH:MM")
+		}
+		if start == end {
+			return nil, invalid("Start and end time must be different")
+		}
+		startTime, endTime := formatMinuteOfDay(start), formatMinuteOfDay(end)
+		surcharge.StartTime, surcharge.EndTime = &startTime, &endTime
+	case models.SurchargeHoliday:
+		date, err := time.Parse("2006-01-02", in.HolidayDate)
+		if err != nil {
+			return nil, invalid("Holiday surcharges need holiday_date as YYYY-MM-DD")
+		}
+		surcharge.HolidayDate = &date
+	default:
+		return nil, invalid("Kind must be night or holiday")
+	}
+	if in.Description != "" {
+		surcharge.Description = &in.Description
+	}
+	return surcharge, nil
+}
+
+// ListOrders returns all orders matching the filter, newest first
+func (s *AdminService) ListOrders(filter AdminOrderFilter) ([]models.Order, error) {
+	orders, err := s.orders.List(repository.OrderFilter{
+		Status:        filter.Status,
+		Type:          filter.Type,
+		FromDate:      filter.FromDate,
+		ToDate:        filter.ToDate,
+		PaymentStatus: filter.PaymentStatus,
+		PaymentMethod: filter.PaymentMethod,
+	})
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+	return orders, nil
+}
+
+// SetOrderStatus lets an admin move an order through the state machine, for
+// example to cancel a stuck trip. Admin cancellations bypass the cancellation
+// policy: the driver gets the full service fee back and the customer owes
+// nothing.
+func (s *AdminService) SetOrderStatus(adminID, orderID int64, status models.OrderStatus, reason string) (*models.Order, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrderAndDriver(tx, orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if KindOf(err) == KindConflict {
+		return nil, err
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	change := orderChange{
+		To:      status,
+		Actor:   models.ActorAdmin,
+		ActorID: adminID,
+		Reason:  reason,
+	}
+	if err := transitionOrder(tx, order, change); err != nil {
+		return nil, err
+	}
+
+	if status == models.OrderStatusCancelled {
+		outcome := CancellationOutcome{}
+		if order.DriverID != nil {
+			outcome.DriverRefund = order.ServiceFee
+		}
+		if err := settleCancellation(tx, order, change, outcome); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	updated, err := s.orders.Get(orderID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	publishOrderStatus(s.drivers, s.events, updated)
+
+	return updated, nil
+}
+
+// ListCancellationPolicies returns every cancellation policy rule
+func (s *AdminService) ListCancellationPolicies() ([]models.CancellationPolicy, error) {
+	policies, err := s.policies.List()
+	if err != nil {
+		return nil, internal("Failed to fetch cancellation policy", err)
+	}
+	return policies, nil
+}
+
+// CreateCancellationPolicy adds a cancellation policy rule
+func (s *AdminService) CreateCancellationPolicy(in CancellationPolicyInput) (*models.CancellationPolicy, error) {
+	policy, err := in.toModel()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.policies.Create(policy)
+	if err != nil {
+		return nil, internal("Failed to create cancellation policy", err)
+	}
+	return created, nil
+}
+
+// UpdateCancellationPolicy replaces a cancellation policy rule
+func (s *AdminService) UpdateCancellationPolicy(id int64, in CancellationPolicyInput) (*models.CancellationPolicy, error) {
+	policy, err := in.toModel()
+	if err != nil {
+		return nil, err
+	}
+	policy.ID = id
+
+	updated, err := s.policies.Update(policy)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Cancellation policy not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to update cancellation policy", err)
+	}
+	return updated, nil
+}
+
+// DeleteCancellationPolicy removes a cancellation policy rule
+func (s *AdminService) DeleteCancellationPolicy(id int64) error {
+	err := s.policies.Delete(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Cancellation policy not found")
+	}
+	if err != nil {
+		return internal("Failed to delete cancellation policy", err)
+	}
+	return nil
+}
+
+func (in CancellationPolicyInput) toModel() (*models.CancellationPolicy, error) {
+	status := models.OrderStatus(in.OrderStatus)
+	if !CanTransitionOrder(status, models.OrderStatusCancelled, models.ActorCustomer) {
+		return nil, invalid(fmt.Sprintf("Customers cannot cancel %s orders", in.OrderStatus))
+	}
+	if in.MinHoursBeforePickup < 0 {
+		return nil, invalid("Minimum hours before pickup cannot be negative")
+	}
+	if in.DriverRefundPercent < 0 || in.DriverRefundPercent > 100 || in.CustomerFeePercent < 0 || in.CustomerFeePercent > 100 {
+		return nil, invalid("Percentages must be between 0 and 100")
+	}
+
+	policy := &models.CancellationPolicy{
+		OrderStatus:          status,
+		MinHoursBeforePickup: in.MinHoursBeforePickup,
+		DriverRefundPercent:  in.DriverRefundPercent,
+		CustomerFeePercent:   in.CustomerFeePercent,
+	}
+	switch models.OrderType(in.OrderType) {
+	case "":
+	case models.OrderTypeTaxi, models.OrderTypeDelivery:
+		orderType := models.OrderType(in.OrderType)
+		policy.OrderType = &orderType
+	default:
+		return nil, invalid("Order type must be taxi or delivery")
+	}
+	if in.Description != "" {
+		policy.Description = &in.Description
+	}
+	return policy, nil
+}
+
+// Statistics returns platform-wide counters
+func (s *AdminService) Statistics() (*AdminStatistics, error) {
+	var stats AdminStatistics
+	err := s.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE role = $1),
+			(SELECT COUNT(*) FROM drivers),
+			(SELECT COUNT(*) FROM drivers WHERE is_active = true AND status = 'approved'),
+			(SELECT COUNT(*) FROM orders),
+			(SELECT COUNT(*) FROM orders WHERE status = $2),
+			(SELECT COALESCE(SUM(service_fee), 0) FROM orders WHERE status = $2),
+			(SELECT COUNT(*) FROM orders WHERE DATE(created_at) = CURRENT_DATE),
+			(SELECT COALESCE(SUM(service_fee), 0) FROM orders WHERE DATE(created_at) = CURRENT_DATE AND status = $2)
+	`, models.RoleUser, models.OrderStatusCompleted).Scan(
+		&stats.TotalUsers, &stats.TotalDrivers, &stats.ActiveDrivers,
+		&stats.TotalOrders, &stats.CompletedOrders, &stats.TotalRevenue,
+		&stats.TodayOrders, &stats.TodayRevenue,
+	)
+	if err != nil {
+		return nil, internal("Failed to fetch statistics", err)
+	}
+	return &stats, nil
+}
+
+// ListFeedback returns all user feedback, newest first
+func (s *AdminService) ListFeedback() ([]models.Feedback, error) {
+	feedbacks, err := s.feedback.List()
+	if err != nil {
+		return nil, internal("Failed to fetch feedback", err)
+	}
+	return feedbacks, nil
+}
+
+// CreateAdmin creates a new admin account
+func (s *AdminService) CreateAdmin(in CreateAdminInput) (*models.User, error) {
+	_, err := s.users.GetByPhone(in.PhoneNumber)
+	if err == nil {
+		return nil, conflict("Phone number already registered")
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, internal("Database error", err)
+	}
+
+	hashedPassword, err := utils.HashPassword(in.Password)
+	if err != nil {
+		return nil, internal("Failed to process password", err)
+	}
+
+	user, err := s.users.Create(in.PhoneNumber, in.Name, hashedPassword, models.RoleAdmin, models.LangUzLatin)
+	if err != nil {
+		return nil, internal("Failed to create admin", err)
+	}
+	return user, nil
+}
+
+// ResetUserPassword sets a new password for any user
+func (s *AdminService) ResetUserPassword(userID int64, newPassword string) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return internal("Failed to process password", err)
+	}
+
+	err = s.users.SetPassword(userID, hashedPassword)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("User not found")
+	}
+	if err != nil {
+		return internal("Failed to reset password", err)
+	}
+	return nil
+}
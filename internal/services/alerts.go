@@ -0,0 +1,30 @@
+package services
+
+import (
+	"log"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// AdminAlerts is told about events the admin group should see; the Telegram
+// notifier implements it. Calls must not block: the notifier only queues the
+// alert, so services call it inline rather than from a goroutine that
+// shutdown would not wait for.
+type AdminAlerts interface {
+	NewOrder(order *models.Order, lang models.Language)
+	OrderCancelled(order *models.Order, reason string, lang models.Language)
+	DriverApplication(app *models.DriverApplication, lang models.Language)
+	Feedback(feedback *models.Feedback, user *models.User)
+}
+
+// userLanguage returns the user's language, falling back to the default one
+// when the user cannot be loaded so an alert is never lost over it
+func userLanguage(users *repository.UserRepository, userID int64) models.Language {
+	user, err := users.Get(userID)
+	if err != nil {
+		log.Printf("Failed to load language of user %d: %v", userID, err)
+		return models.LangUzLatin
+	}
+	return user.Language
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+	"taxi-service/internal/utils"
+)
+
+// AuthService handles registration, login and profile management
+type AuthService struct {
+	db    *sql.DB
+	users *repository.UserRepository
+	jwt   *config.JWTConfig
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(db *sql.DB, jwt *config.JWTConfig) *AuthService {
+	return &AuthService{db: db, users: repository.NewUserRepository(db), jwt: jwt}
+}
+
+// RegisterInput holds the data needed to create a user account
+type RegisterInput struct {
+	PhoneNumber     string
+	Name            string
+	Password        string
+	ConfirmPassword string
+}
+
+// AuthResult is returned after a successful registration or login
+type AuthResult struct {
+	Token string
+	User  *models.User
+}
+
+// UpdateProfileInput holds profile fields a user may change
+type UpdateProfileInput struct {
+	Name     string
+	Language models.Language
+}
+
+// ChangePasswordInput holds the data needed to change a password
+type ChangePasswordInput struct {
+	OldPassword        string
+	NewPassword        string
+	ConfirmNewPassword string
+}
+
+// Register creates a new user and issues a token
+func (s *AuthService) Register(in RegisterInput) (*AuthResult, error) {
+	if in.Password != in.ConfirmPassword {
+		return nil, invalid("Passwords do not match")
+	}
+
+	// Check if user already exists
+	_, err := s.users.GetByPhone(in.PhoneNumber)
+	if err == nil {
+		return nil, conflict("Phone number already registered")
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, internal("Database error", err)
+	}
+
+	hashedPassword, err := utils.HashPassword(in.Password)
+	if err != nil {
+		return nil, internal("Failed to process password", err)
+	}
+
+	user, err := s.users.Create(in.PhoneNumber, in.Name, hashedPassword, models.RoleUser, models.LangUzLatin)
+	if err != nil {
+		return nil, internal("Failed to create user", err)
+	}
+
+	return s.issueToken(user)
+}
+
+// Login verifies credentials and issues a token
+func (s *AuthService) Login(phoneNumber, password string) (*AuthResult, error) {
+	user, err := s.users.GetByPhone(phoneNumber)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, unauthorized("Invalid credentials")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	if user.IsBlocked {
+		return nil, forbidden("Account is blocked")
+	}
+
+	passwordHash, err := s.users.GetPasswordHash(user.ID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if err := utils.CheckPassword(passwordHash, password); err != nil {
+		return nil, unauthorized("Invalid credentials")
+	}
+
+	return s.issueToken(user)
+}
+
+// GetProfile returns the user with the given ID
+func (s *AuthService) GetProfile(userID int64) (*models.User, error) {
+	user, err := s.users.Get(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("User not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to get profile", err)
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the user's name and language; empty fields are left as is
+func (s *AuthService) UpdateProfile(userID int64, in UpdateProfileInput) (*models.User, error) {
+	if in.Language != "" && !isSupportedLanguage(in.Language) {
+		return nil, invalid("Unsupported language")
+	}
+
+	user, err := s.users.UpdateProfile(userID, in.Name, in.Language)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("User not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to update profile", err)
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the user's password after verifying the old one
+func (s *AuthService) ChangePassword(userID int64, in ChangePasswordInput) error {
+	if in.NewPassword != in.ConfirmNewPassword {
+		return invalid("New passwords do not match")
+	}
+
+	currentPassword, err := s.users.GetPasswordHash(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("User not found")
+	}
+	if err != nil {
+		return internal("Database error", err)
+	}
+
+	if err := utils.CheckPassword(currentPassword, in.OldPassword); err != nil {
+		return invalid("Invalid old password")
+	}
+
+	hashedPassword, err := utils.HashPassword(in.NewPassword)
+	if err != nil {
+		return internal("Failed to process password", err)
+	}
+
+	if err := s.users.SetPassword(userID, hashedPassword); err != nil {
+		return internal("Failed to update password", err)
+	}
+	return nil
+}
+
+// SetAvatar stores a new avatar path and returns the previous one, if any
+func (s *AuthService) SetAvatar(userID int64, avatarPath string) (*string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	users := repository.NewUserRepository(tx)
+	oldAvatar, err := users.GetAvatarForUpdate(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("User not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	if err := users.SetAvatar(userID, avatarPath); err != nil {
+		return nil, internal("Failed to update avatar", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to update avatar", err)
+	}
+	return oldAvatar, nil
+}
+
+func (s *AuthService) issueToken(user *models.User) (*AuthResult, error) {
+	token, err := utils.GenerateToken(user.ID, user.Role, s.jwt.Secret, s.jwt.ExpirationHours)
+	if err != nil {
+		return nil, internal("Failed to generate token", err)
+	}
+	return &AuthResult{Token: token, User: user}, nil
+}
+
+func isSupportedLanguage(lang models.Language) bool {
+	switch lang {
+	case models.LangUzLatin, models.LangUzCyrillic, models.LangRussian:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// CancellationOutcome is what cancelling an order costs each side
+type CancellationOutcome struct {
+	PolicyID     *int64  `json:"policy_id,omitempty"` // Rule that decided it; nil for admin cancellations and the built-in default
+	DriverRefund float64 `json:"driver_refund"`       // Service fee returned to the driver
+	CustomerFee  float64 `json:"cancellation_fee"`    // Owed by the customer
+}
+
+// decideCancellation applies the cancellation policy to an order cancelled
+// untilPickup before pickup. Among the rules for the order's current status
+// whose cutoff has not passed, one for the order's type beats one for any
+// type, then the latest cutoff wins. Without a matching rule the driver gets
+// the full service fee back and the customer pays nothing.
+func decideCancellation(policies []models.CancellationPolicy, order *models.Order, untilPickup time.Duration) CancellationOutcome {
+	hours := untilPickup.Hours()
+
+	var best *models.CancellationPolicy
+	for i := range policies {
+		p := &policies[i]
+		if p.OrderStatus != order.Status || hours < p.MinHoursBeforePickup {
+			continue
+		}
+		if p.OrderType != nil && *p.OrderType != order.OrderType {
+			continue
+		}
+		if best == nil || moreSpecificPolicy(p, best) {
+			best = p
+		}
+	}
+
+	refundPercent, feePercent := 100.0, 0.0
+	var policyID *int64
+	if best != nil {
+		refundPercent, feePercent = best.DriverRefundPercent, best.CustomerFeePercent
+		policyID = &best.ID
+	}
+
+	outcome := CancellationOutcome{
+		PolicyID:    policyID,
+		CustomerFee: roundAmount(order.FinalPrice * feePercent / 100),
+	}
+	if order.DriverID != nil {
+		outcome.DriverRefund = roundAmount(order.ServiceFee * refundPercent / 100)
+	}
+	return outcome
+}
+
+func moreSpecificPolicy(a, b *models.CancellationPolicy) bool {
+	if (a.OrderType != nil) != (b.OrderType != nil) {
+		return a.OrderType != nil
+	}
+	return a.MinHoursBeforePickup > b.MinHoursBeforePickup
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// settleCancellation refunds the driver of a cancelled order as decided,
+// stores the outcome on the order and settles the customer's fare
+func settleCancellation(tx repository.Querier, order *models.Order, cancel orderChange, outcome CancellationOutcome) error {
+	if order.DriverID != nil {
+		if err := refundServiceFee(tx, order, cancel, outcome.DriverRefund); err != nil {
+			return err
+		}
+	}
+
+	err := repository.NewOrderRepository(tx).SetCancellationOutcome(order.ID, outcome.PolicyID, outcome.DriverRefund, outcome.CustomerFee)
+	if err != nil {
+		return internal("Failed to record cancellation outcome", err)
+	}
+	return settleFare(tx, order, cancel, outcome.CustomerFee)
+}
+
+// refundServiceFee returns part or all of the service fee charged on
+// acceptance to the driver of a cancelled order and records the refund against
+// whoever cancelled it
+func refundServiceFee(tx repository.Querier, order *models.Order, cancel orderChange, amount float64) error {
+	description := "Refund for cancelled order"
+	if amount < order.ServiceFee {
+		description = fmt.Sprintf("Partial refund for cancelled order (%.2f of %.2f)", amount, order.ServiceFee)
+	}
+
+	_, err := postToWallet(tx, walletPosting{
+		Kind:        models.LedgerFeeRefund,
+		DriverID:    *order.DriverID,
+		Amount:      amount,
+		Counterpart: models.AccountPlatformRevenue,
+		OrderID:     &order.ID,
+		Description: description,
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordOrderEvent(tx, order.ID, models.OrderEventFeeRefunded, nil, nil, orderChange{
+		Actor:   cancel.Actor,
+		ActorID: cancel.ActorID,
+		Details: map[string]interface{}{"driver_id": *order.DriverID, "amount": amount},
+	})
+}
+
+// notifyDriverOfCancellation tells the driver of an order its customer
+// cancelled how much of the service fee came back and which rule decided it
+func (s *OrderService) notifyDriverOfCancellation(order *models.Order, outcome CancellationOutcome, policies []models.CancellationPolicy) {
+	driver, err := s.drivers.Get(*order.DriverID)
+	if err != nil {
+		log.Printf("Failed to load driver %d of cancelled order %d: %v", *order.DriverID, order.ID, err)
+		return
+	}
+
+	message := fmt.Sprintf("The customer cancelled order #%d. %.2f of the %.2f service fee was returned to your balance (%s).",
+		order.ID, outcome.DriverRefund, order.ServiceFee, policyLabel(policies, outcome.PolicyID))
+	if err := pushNotification(s.notifications, s.events, driver.UserID, "Order Cancelled", message, "order_cancelled", order.ID); err != nil {
+		log.Printf("Failed to notify driver user %d about cancelled order %d: %v", driver.UserID, order.ID, err)
+	}
+}
+
+// policyLabel describes the cancellation rule with the given ID for people
+func policyLabel(policies []models.CancellationPolicy, id *int64) string {
+	if id == nil {
+		return "full refund, no cancellation rule applied"
+	}
+	for _, p := range policies {
+		if p.ID != *id {
+			continue
+		}
+		if p.Description != nil && *p.Description != "" {
+			return *p.Description
+		}
+		return fmt.Sprintf("cancellation rule #%d: %g%% refund", p.ID, p.DriverRefundPercent)
+	}
+	return fmt.Sprintf("cancellation rule #%d", *id)
+}
@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+	"taxi-service/internal/services"
+)
+
+// TestCancelUnpaidOrderOwesFee cancels a cash order and a card order that was
+// never paid under a policy charging a fee: both must be left owing it, be
+// listed as outstanding and be resolvable by an admin
+func TestCancelUnpaidOrderOwesFee(t *testing.T) {
+	db := openTestDB(t)
+
+	f := createAcceptFixtures(t, db, 0, 2, 5000, 0)
+	customerID := f.userIDs[0]
+	cash, card := f.orderIDs[0], f.orderIDs[1]
+	for id, method := range map[int64]models.PaymentMethod{cash: models.PaymentCash, card: models.PaymentCard} {
+		if _, err := db.Exec(`UPDATE orders SET payment_method = $1, payment_status = 'unpaid' WHERE id = $2`, method, id); err != nil {
+			t.Fatalf("set payment method: %v", err)
+		}
+	}
+
+	taxi := models.OrderTypeTaxi
+	policy, err := repository.NewCancellationPolicyRepository(db).Create(&models.CancellationPolicy{
+		OrderType:          &taxi,
+		OrderStatus:        models.OrderStatusPending,
+		CustomerFeePercent: 10,
+	})
+	if err != nil {
+		t.Fatalf("create cancellation policy: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM cancellation_policies WHERE id = $1`, policy.ID); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+	})
+
+	admin, err := repository.NewUserRepository(db).Create(fmt.Sprintf("+78%09d", time.Now().UnixNano()%1_000_000_000), "fee test admin", "-", models.RoleAdmin, models.LangUzLatin)
+	if err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	f.userIDs = append(f.userIDs, admin.ID)
+
+	orderSvc := services.NewOrderService(db, &config.DispatchConfig{}, &config.PricingConfig{}, noAlerts{}, noEvents{}, noJobs{})
+	paymentSvc := services.NewPaymentService(db, noEvents{})
+	orders := repository.NewOrderRepository(db)
+
+	for _, id := range []int64{cash, card} {
+		outcome, err := orderSvc.CancelOrder(customerID, id, "plans changed")
+		if err != nil {
+			t.Fatalf("cancel order %d: %v", id, err)
+		}
+		if outcome.CustomerFee <= 0 {
+			t.Fatalf("order %d cancelled without a fee", id)
+		}
+		order, err := orders.Get(id)
+		if err != nil {
+			t.Fatalf("load order %d: %v", id, err)
+		}
+		if order.PaymentStatus != models.PaymentOwed {
+			t.Errorf("%s order %d payment is %s, want %s", order.PaymentMethod, id, order.PaymentStatus, models.PaymentOwed)
+		}
+		if order.CancellationFee == nil || *order.CancellationFee != outcome.CustomerFee {
+			t.Errorf("order %d cancellation fee %v, want %.2f", id, order.CancellationFee, outcome.CustomerFee)
+		}
+	}
+
+	for id, method := range map[int64]string{cash: "cash", card: "card"} {
+		outstanding, err := paymentSvc.ListOutstanding(method)
+		if err != nil {
+			t.Fatalf("list outstanding %s payments: %v", method, err)
+		}
+		listed := false
+		for _, order := range outstanding {
+			listed = listed || order.ID == id
+		}
+		if !listed {
+			t.Errorf("owed %s order %d is not listed as outstanding", method, id)
+		}
+	}
+
+	for id, status := range map[int64]models.PaymentStatus{cash: models.PaymentPaid, card: models.PaymentWaived} {
+		order, err := paymentSvc.Resolve(admin.ID, id, status, "settled in test")
+		if err != nil {
+			t.Fatalf("resolve order %d as %s: %v", id, status, err)
+		}
+		if order.PaymentStatus != status {
+			t.Errorf("order %d payment is %s, want %s", id, order.PaymentStatus, status)
+		}
+	}
+
+	outstanding, err := paymentSvc.ListOutstanding("")
+	if err != nil {
+		t.Fatalf("list outstanding payments: %v", err)
+	}
+	for _, order := range outstanding {
+		if order.ID == cash || order.ID == card {
+			t.Errorf("resolved order %d is still listed as outstanding", order.ID)
+		}
+	}
+}
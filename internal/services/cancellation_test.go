@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+func TestDecideCancellation(t *testing.T) {
+	taxi, delivery := models.OrderTypeTaxi, models.OrderTypeDelivery
+	policies := []models.CancellationPolicy{
+		{ID: 1, OrderStatus: models.OrderStatusAccepted, MinHoursBeforePickup: 0, DriverRefundPercent: 100, CustomerFeePercent: 20},
+		{ID: 2, OrderStatus: models.OrderStatusAccepted, MinHoursBeforePickup: 24, DriverRefundPercent: 100, CustomerFeePercent: 0},
+		{ID: 3, OrderStatus: models.OrderStatusAccepted, OrderType: &delivery, MinHoursBeforePickup: 0, DriverRefundPercent: 50, CustomerFeePercent: 10},
+		{ID: 4, OrderStatus: models.OrderStatusAccepted, OrderType: &taxi, MinHoursBeforePickup: 2, DriverRefundPercent: 75, CustomerFeePercent: 5},
+		{ID: 5, OrderStatus: models.OrderStatusPending, MinHoursBeforePickup: 0, DriverRefundPercent: 0, CustomerFeePercent: 30},
+		{ID: 6, OrderStatus: models.OrderStatusPending, MinHoursBeforePickup: 12, DriverRefundPercent: 0, CustomerFeePercent: 0},
+	}
+	driverID := int64(9)
+
+	tests := []struct {
+		name        string
+		orderType   models.OrderType
+		status      models.OrderStatus
+		driver      *int64
+		untilPickup time.Duration
+		want        CancellationOutcome
+	}{
+		{"type rule beats a later any-type cutoff", models.OrderTypeTaxi, models.OrderStatusAccepted, &driverID, 48 * time.Hour,
+			CancellationOutcome{PolicyID: ptr(int64(4)), DriverRefund: 7.5, CustomerFee: 5}},
+		{"type rule before its cutoff falls back to any type", models.OrderTypeTaxi, models.OrderStatusAccepted, &driverID, time.Hour,
+			CancellationOutcome{PolicyID: ptr(int64(1)), DriverRefund: 10, CustomerFee: 20}},
+		{"type rule for another type is skipped", models.OrderTypeDelivery, models.OrderStatusAccepted, &driverID, time.Hour,
+			CancellationOutcome{PolicyID: ptr(int64(3)), DriverRefund: 5, CustomerFee: 10}},
+		{"latest passed cutoff wins", models.OrderTypeTaxi, models.OrderStatusPending, nil, 30 * time.Hour,
+			CancellationOutcome{PolicyID: ptr(int64(6))}},
+		{"no driver, no refund", models.OrderTypeTaxi, models.OrderStatusPending, nil, time.Hour,
+			CancellationOutcome{PolicyID: ptr(int64(5)), CustomerFee: 30}},
+		{"no matching rule refunds in full for free", models.OrderTypeTaxi, models.OrderStatusInProgress, &driverID, time.Hour,
+			CancellationOutcome{DriverRefund: 10}},
+		{"pickup already passed matches no cutoff", models.OrderTypeTaxi, models.OrderStatusAccepted, &driverID, -time.Hour,
+			CancellationOutcome{DriverRefund: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{OrderType: tt.orderType, Status: tt.status, DriverID: tt.driver, FinalPrice: 100, ServiceFee: 10}
+			got := decideCancellation(policies, order, tt.untilPickup)
+			if !samePolicy(got.PolicyID, tt.want.PolicyID) || got.DriverRefund != tt.want.DriverRefund || got.CustomerFee != tt.want.CustomerFee {
+				t.Errorf("got policy %v refund %.2f fee %.2f, want policy %v refund %.2f fee %.2f",
+					deref(got.PolicyID), got.DriverRefund, got.CustomerFee, deref(tt.want.PolicyID), tt.want.DriverRefund, tt.want.CustomerFee)
+			}
+		})
+	}
+}
+
+func TestDecideCancellationRoundsToCents(t *testing.T) {
+	policies := []models.CancellationPolicy{
+		{ID: 1, OrderStatus: models.OrderStatusAccepted, DriverRefundPercent: 33.333, CustomerFeePercent: 12.345},
+	}
+	driverID := int64(1)
+	order := &models.Order{Status: models.OrderStatusAccepted, DriverID: &driverID, FinalPrice: 1000.01, ServiceFee: 10.01}
+
+	got := decideCancellation(policies, order, time.Hour)
+	if got.DriverRefund != 3.34 || got.CustomerFee != 123.45 {
+		t.Errorf("refund %v fee %v, want 3.34 and 123.45", got.DriverRefund, got.CustomerFee)
+	}
+}
+
+func TestMoreSpecificPolicy(t *testing.T) {
+	taxi := models.OrderTypeTaxi
+	tests := []struct {
+		name string
+		a, b models.CancellationPolicy
+		want bool
+	}{
+		{"typed beats any type", models.CancellationPolicy{OrderType: &taxi}, models.CancellationPolicy{MinHoursBeforePickup: 24}, true},
+		{"any type loses to typed", models.CancellationPolicy{MinHoursBeforePickup: 24}, models.CancellationPolicy{OrderType: &taxi}, false},
+		{"later cutoff wins", models.CancellationPolicy{MinHoursBeforePickup: 12}, models.CancellationPolicy{MinHoursBeforePickup: 2}, true},
+		{"earlier cutoff loses", models.CancellationPolicy{OrderType: &taxi, MinHoursBeforePickup: 2}, models.CancellationPolicy{OrderType: &taxi, MinHoursBeforePickup: 12}, false},
+		{"equal cutoffs keep the first found", models.CancellationPolicy{MinHoursBeforePickup: 2}, models.CancellationPolicy{MinHoursBeforePickup: 2}, false},
+	}
+	for _, tt := range tests {
+		if got := moreSpecificPolicy(&tt.a, &tt.b); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClosedPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     models.PaymentMethod
+		status     models.PaymentStatus
+		fee        float64
+		wantStatus models.PaymentStatus
+		wantRefund float64
+	}{
+		{"cash with a fee is owed", models.PaymentCash, models.PaymentUnpaid, 20, models.PaymentOwed, 0},
+		{"cash without a fee is void", models.PaymentCash, models.PaymentUnpaid, 0, models.PaymentVoid, 0},
+		{"unpaid card with a fee is owed", models.PaymentCard, models.PaymentUnpaid, 20, models.PaymentOwed, 0},
+		{"unpaid card without a fee is void", models.PaymentCard, models.PaymentUnpaid, 0, models.PaymentVoid, 0},
+		{"fee below a cent is void", models.PaymentCash, models.PaymentUnpaid, 0.001, models.PaymentVoid, 0},
+		{"paid card is refunded less the fee", models.PaymentCard, models.PaymentPaid, 20, models.PaymentRefunded, 80},
+		{"paid wallet is refunded in full", models.PaymentWallet, models.PaymentPaid, 0, models.PaymentRefunded, 100},
+		{"fee taking the whole fare leaves it paid", models.PaymentWallet, models.PaymentPaid, 100, "", 0},
+		{"disputed is left alone", models.PaymentCash, models.PaymentDisputed, 20, "", 0},
+	}
+	for _, tt := range tests {
+		order := &models.Order{PaymentMethod: tt.method, PaymentStatus: tt.status, FinalPrice: 100}
+		status, refund := closedPayment(order, tt.fee)
+		if status != tt.wantStatus || refund != tt.wantRefund {
+			t.Errorf("%s: got %q refund %.2f, want %q refund %.2f", tt.name, status, refund, tt.wantStatus, tt.wantRefund)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref(id *int64) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+func samePolicy(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
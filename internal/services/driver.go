@@ -0,0 +1,620 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+	"taxi-service/internal/statement"
+)
+
+// DriverService implements the driver application and order workflow
+type DriverService struct {
+	db            *sql.DB
+	drivers       *repository.DriverRepository
+	orders        *repository.OrderRepository
+	users         *repository.UserRepository
+	applications  *repository.ApplicationRepository
+	regions       *repository.RegionRepository
+	notifications *repository.NotificationRepository
+	ledger        *repository.LedgerRepository
+	alerts        AdminAlerts
+	events        Events
+	jobs          Jobs
+	dispatch      *config.DispatchConfig
+}
+
+// NewDriverService creates a new driver service
+func NewDriverService(db *sql.DB, dispatch *config.DispatchConfig, alerts AdminAlerts, events Events, jobs Jobs) *DriverService {
+	return &DriverService{
+		db:            db,
+		dispatch:      dispatch,
+		drivers:       repository.NewDriverRepository(db),
+		orders:        repository.NewOrderRepository(db),
+		users:         repository.NewUserRepository(db),
+		applications:  repository.NewApplicationRepository(db),
+		regions:       repository.NewRegionRepository(db),
+		notifications: repository.NewNotificationRepository(db),
+		ledger:        repository.NewLedgerRepository(db),
+		alerts:        alerts,
+		events:        events,
+		jobs:          jobs,
+	}
+}
+
+// ApplyInput holds a driver application; LicenseImage is a path relative to the upload directory
+type ApplyInput struct {
+	FullName     string
+	CarModel     string
+	CarNumber    string
+	LicenseImage string
+}
+
+// UpdateDriverProfileInput holds driver fields that may be changed; empty fields are left as is
+type UpdateDriverProfileInput struct {
+	FullName      string
+	CarModel      string
+	CarNumber     string
+	SeatCapacity  int
+	BaseLatitude  *float64
+	BaseLongitude *float64
+}
+
+// NewOrdersFilter narrows down the list of orders open for acceptance
+type NewOrdersFilter struct {
+	Type         string
+	FromRegionID int64
+	ToRegionID   int64
+}
+
+// ReleaseResult is the outcome of a driver giving an accepted order back
+type ReleaseResult struct {
+	Order   *models.Order `json:"order"`
+	Refund  float64       `json:"refund"`  // Part of the service fee returned to the driver
+	Penalty float64       `json:"penalty"` // Part of the service fee kept
+}
+
+// DriverStatistics represents driver statistics
+type DriverStatistics struct {
+	TotalOrders     int     `json:"total_orders"`
+	CompletedOrders int     `json:"completed_orders"`
+	TotalEarnings   float64 `json:"total_earnings"`
+	CurrentBalance  float64 `json:"current_balance"`
+	AverageRating   float64 `json:"average_rating"`
+	TotalRatings    int     `json:"total_ratings"`
+}
+
+// Apply submits a driver application for review
+func (s *DriverService) Apply(userID int64, in ApplyInput) (*models.DriverApplication, error) {
+	if in.FullName == "" || in.CarModel == "" || in.CarNumber == "" {
+		return nil, invalid("All fields are required")
+	}
+	if in.LicenseImage == "" {
+		return nil, invalid("License image is required")
+	}
+
+	user, err := s.users.Get(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("User not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if user.Role == models.RoleDriver {
+		return nil, invalid("You are already a driver")
+	}
+
+	pending, err := s.applications.HasPending(userID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if pending {
+		return nil, conflict("Application already submitted and pending review")
+	}
+
+	application, err := s.applications.Create(&models.DriverApplication{
+		UserID:       userID,
+		FullName:     in.FullName,
+		PhoneNumber:  user.PhoneNumber,
+		CarModel:     in.CarModel,
+		CarNumber:    in.CarNumber,
+		LicenseImage: in.LicenseImage,
+	})
+	if err != nil {
+		return nil, internal("Failed to create application", err)
+	}
+
+	s.alerts.DriverApplication(application, user.Language)
+
+	return application, nil
+}
+
+// GetProfile returns the driver profile of a user
+func (s *DriverService) GetProfile(userID int64) (*models.Driver, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return driver, nil
+}
+
+// UpdateProfile changes the driver's name, car details and base point
+func (s *DriverService) UpdateProfile(userID int64, in UpdateDriverProfileInput) (*models.Driver, error) {
+	if in.SeatCapacity < 0 || in.SeatCapacity > maxTripSeats {
+		return nil, invalid("Seat capacity must be between 1 and 8")
+	}
+	if (in.BaseLatitude == nil) != (in.BaseLongitude == nil) {
+		return nil, invalid("Base latitude and longitude must be set together")
+	}
+
+	driver, err := s.drivers.UpdateProfile(userID, repository.DriverProfileUpdate{
+		FullName:      in.FullName,
+		CarModel:      in.CarModel,
+		CarNumber:     in.CarNumber,
+		SeatCapacity:  in.SeatCapacity,
+		BaseLatitude:  in.BaseLatitude,
+		BaseLongitude: in.BaseLongitude,
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to update profile", err)
+	}
+	return driver, nil
+}
+
+// ListNewOrders returns the pending orders whose accept deadline has not
+// passed and that suit the driver: on the driver's routes, due inside the
+// driver's availability and fitting the car. The nearest pickups come first.
+func (s *DriverService) ListNewOrders(userID int64, filter NewOrdersFilter) ([]models.Order, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	orders, err := s.orders.List(repository.OrderFilter{
+		Status:            string(models.OrderStatusPending),
+		Type:              filter.Type,
+		FromRegionID:      filter.FromRegionID,
+		ToRegionID:        filter.ToRegionID,
+		OpenForAcceptance: true,
+		MatchDriverID:     driver.ID,
+	})
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+
+	windows, err := s.drivers.ListAvailability(driver.ID, today())
+	if err != nil {
+		return nil, internal("Failed to fetch availability", err)
+	}
+	matched := make([]models.Order, 0, len(orders))
+	for _, order := range orders {
+		if availableFor(windows, &order) {
+			matched = append(matched, order)
+		}
+	}
+	rankOrders(matched, driver)
+	return matched, nil
+}
+
+// AcceptOrder assigns a pending order to the driver and charges the service fee.
+// The driver and order rows are locked for the whole check-and-charge, so of
+// several drivers accepting at once exactly one wins; the others get a
+// conflict and are not charged.
+func (s *DriverService) AcceptOrder(userID, orderID int64) (*models.Order, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	driver, err := repository.NewDriverRepository(tx).GetByUserIDForUpdate(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	if !driver.IsActive {
+		return nil, forbidden("Driver account is not active")
+	}
+
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	if order.OrderType == models.OrderTypeTaxi && order.PassengerCount != nil && *order.PassengerCount > int64(driver.SeatCapacity) {
+		return nil, invalid("Order has more passengers than your car has seats")
+	}
+
+	if err := assignOrder(tx, driver, order, orderChange{Actor: models.ActorDriver, ActorID: userID}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	accepted, err := s.orders.Get(order.ID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	// Notify customer
+	publishOrderStatus(s.drivers, s.events, accepted)
+	if err := pushNotification(s.notifications, s.events, accepted.UserID, "Order Accepted", "A driver has accepted your order.", "order_accepted", accepted.ID); err != nil {
+		log.Printf("Failed to notify user %d about accepted order %d: %v", accepted.UserID, accepted.ID, err)
+	}
+
+	return accepted, nil
+}
+
+// assignOrder gives a pending order to a driver and charges the service fee,
+// recording the change against by: the driver accepting it or the customer
+// booking it onto the driver's trip. It must run in the transaction that
+// locked the driver and order rows.
+func assignOrder(tx repository.Querier, driver *models.Driver, order *models.Order, by orderChange) error {
+	if order.Status != models.OrderStatusPending {
+		return conflict("Order has already been taken")
+	}
+
+	if order.AcceptDeadline != nil && order.AcceptDeadline.Before(time.Now()) {
+		return invalid("Order acceptance deadline has passed")
+	}
+
+	if driver.Balance < order.ServiceFee {
+		return invalid("Insufficient balance to accept order")
+	}
+
+	err := transitionOrder(tx, order, orderChange{
+		To:       models.OrderStatusAccepted,
+		Actor:    by.Actor,
+		ActorID:  by.ActorID,
+		DriverID: driver.ID,
+		Details:  map[string]interface{}{"driver_id": driver.ID, "service_fee": order.ServiceFee},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = postToWallet(tx, walletPosting{
+		Kind:        models.LedgerServiceFee,
+		DriverID:    driver.ID,
+		Amount:      -order.ServiceFee,
+		Counterpart: models.AccountPlatformRevenue,
+		OrderID:     &order.ID,
+		Description: "Service fee for accepting order",
+	})
+	if KindOf(err) == KindInvalid {
+		return invalid("Insufficient balance to accept order")
+	}
+	if err != nil {
+		return err
+	}
+	driver.Balance -= order.ServiceFee
+	return nil
+}
+
+// StartOrder confirms pickup on an accepted order of the driver and moves it to in progress
+func (s *DriverService) StartOrder(userID, orderID int64) (*models.Order, error) {
+	order, err := s.transitionAssignedOrder(userID, orderID, models.OrderStatusInProgress, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if started, err := s.orders.Get(orderID); err != nil {
+		log.Printf("Failed to load order %d for status push: %v", orderID, err)
+	} else {
+		order = started
+		publishOrderStatus(s.drivers, s.events, order)
+	}
+
+	if err := pushNotification(s.notifications, s.events, order.UserID, "Trip Started", "Your driver has confirmed pickup.", "order_started", orderID); err != nil {
+		log.Printf("Failed to notify user %d about started order %d: %v", order.UserID, orderID, err)
+	}
+
+	return order, nil
+}
+
+// CompleteOrder marks an in-progress order of the driver as completed. For a
+// cash order the driver also confirms whether the fare was collected; a fare
+// left uncollected stays unpaid and shows up for admins.
+func (s *DriverService) CompleteOrder(userID, orderID int64, cashCollected bool) error {
+	var collect func(tx *sql.Tx, order *models.Order) error
+	if cashCollected {
+		collect = func(tx *sql.Tx, order *models.Order) error {
+			if order.PaymentMethod != models.PaymentCash {
+				return invalid("Order is not paid in cash")
+			}
+			if order.PaymentStatus != models.PaymentUnpaid {
+				return nil
+			}
+			return setOrderPayment(tx, order, models.PaymentPaid, orderChange{Actor: models.ActorDriver, ActorID: userID})
+		}
+	}
+
+	order, err := s.transitionAssignedOrder(userID, orderID, models.OrderStatusCompleted, collect)
+	if err != nil {
+		return err
+	}
+	customerID := order.UserID
+
+	if completed, err := s.orders.Get(orderID); err != nil {
+		log.Printf("Failed to load order %d for status push: %v", orderID, err)
+	} else {
+		publishOrderStatus(s.drivers, s.events, completed)
+	}
+
+	// Ask customer to rate the trip
+	if err := pushNotification(s.notifications, s.events, customerID, "Order Completed", "Your order has been completed. Please rate your driver.", "order_completed", orderID); err != nil {
+		log.Printf("Failed to notify user %d about completed order %d: %v", customerID, orderID, err)
+	}
+
+	return nil
+}
+
+// ReleaseOrder gives an accepted order back: it returns to pending with a fresh
+// accept deadline and is offered to other drivers. How much of the service fee
+// the driver gets back depends on how close pickup is.
+func (s *DriverService) ReleaseOrder(userID, orderID int64, reason string) (*ReleaseResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	// Driver before order, as postToWallet requires
+	driverID, err := lockDriver(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(orderID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (order.DriverID == nil || *order.DriverID != driverID)) {
+		return nil, invalid("Order not found or not assigned to you")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	refund, penalty, err := releaseOrder(tx, s.dispatch, s.jobs, order, orderChange{Actor: models.ActorDriver, ActorID: userID, DriverID: driverID, Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	released, err := s.orders.Get(orderID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	announceRelease(s.notifications, s.events, s.jobs, released)
+
+	return &ReleaseResult{Order: released, Refund: refund, Penalty: penalty}, nil
+}
+
+// releaseOrder moves an accepted order of change.DriverID back to pending with
+// a fresh accept deadline, returns the service fee to the driver, less the
+// penalty for how close pickup is, and queues the order's offer to other
+// drivers. It must run in the transaction that locked the order.
+func releaseOrder(tx repository.Querier, dispatch *config.DispatchConfig, jobs Jobs, order *models.Order, change orderChange) (refund, penalty float64, err error) {
+	refund, penalty = releaseRefund(dispatch, order.ServiceFee, time.Until(pickupTime(order)))
+
+	change.To = models.OrderStatusPending
+	change.Deadline = time.Now().Add(dispatch.AcceptWindow())
+	change.Details = map[string]interface{}{"driver_id": change.DriverID, "refund": refund, "penalty": penalty}
+	if err := transitionOrder(tx, order, change); err != nil {
+		return 0, 0, err
+	}
+
+	description := "Refund for released order"
+	if penalty > 0 {
+		description = fmt.Sprintf("Partial refund for released order (penalty %.2f)", penalty)
+	}
+	_, err = postToWallet(tx, walletPosting{
+		Kind:        models.LedgerFeeRefund,
+		DriverID:    change.DriverID,
+		Amount:      refund,
+		Counterpart: models.AccountPlatformRevenue,
+		OrderID:     &order.ID,
+		Description: description,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := offerToDrivers(tx, jobs, order.ID); err != nil {
+		return 0, 0, err
+	}
+	return refund, penalty, nil
+}
+
+// announceRelease tells the customer of a released order that a new driver is
+// being looked for and wakes the workers for its queued offer
+func announceRelease(notifications *repository.NotificationRepository, events Events, jobs Jobs, released *models.Order) {
+	events.OrderStatusChanged(released.UserID, released)
+	if err := pushNotification(notifications, events, released.UserID, "Driver Released Order", "Your driver can no longer take this order. We are looking for another driver.", "order_released", released.ID); err != nil {
+		log.Printf("Failed to notify user %d about released order %d: %v", released.UserID, released.ID, err)
+	}
+	jobs.Wake()
+}
+
+// releaseRefund splits the service fee of a released order into the part
+// returned to the driver and the penalty, given the time left until pickup
+func releaseRefund(dispatch *config.DispatchConfig, fee float64, untilPickup time.Duration) (refund, penalty float64) {
+	switch {
+	case untilPickup >= time.Duration(dispatch.ReleaseFullRefundHours)*time.Hour:
+		return fee, 0
+	case untilPickup < time.Duration(dispatch.ReleaseNoRefundHours)*time.Hour:
+		return 0, fee
+	}
+	penalty = fee * dispatch.ReleasePenaltyPercent / 100
+	return fee - penalty, penalty
+}
+
+// ListOrders returns the orders assigned to the driver, newest first
+func (s *DriverService) ListOrders(userID int64, status string) ([]models.Order, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orders.List(repository.OrderFilter{DriverID: driverID, Status: status})
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+	return orders, nil
+}
+
+// Statistics returns order and earnings figures for the driver over a period (daily/monthly/yearly)
+func (s *DriverService) Statistics(userID int64, period string) (*DriverStatistics, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	var timeFilter string
+	switch period {
+	case "daily":
+		timeFilter = "AND DATE(o.created_at) = CURRENT_DATE"
+	case "monthly":
+		timeFilter = "AND DATE_TRUNC('month', o.created_at) = DATE_TRUNC('month', CURRENT_DATE)"
+	case "yearly":
+		timeFilter = "AND DATE_TRUNC('year', o.created_at) = DATE_TRUNC('year', CURRENT_DATE)"
+	default:
+		timeFilter = ""
+	}
+
+	var stats DriverStatistics
+	err = s.db.QueryRow(fmt.Sprintf(`
+		SELECT
+			COUNT(o.id) as total_orders,
+			COUNT(CASE WHEN o.status = 'completed' THEN 1 END) as completed_orders,
+			COALESCE(SUM(CASE WHEN o.status = 'completed' THEN o.service_fee ELSE 0 END), 0) as total_earnings
+		FROM orders o
+		WHERE o.driver_id = $1 %s
+	`, timeFilter), driver.ID).Scan(&stats.TotalOrders, &stats.CompletedOrders, &stats.TotalEarnings)
+	if err != nil {
+		return nil, internal("Failed to fetch statistics", err)
+	}
+
+	stats.CurrentBalance = driver.Balance
+	stats.AverageRating = driver.Rating
+	stats.TotalRatings = driver.TotalRatings
+
+	return &stats, nil
+}
+
+// transitionAssignedOrder moves an order assigned to the driver to a new
+// status. then, when set, runs in the same transaction after the move.
+func (s *DriverService) transitionAssignedOrder(userID, orderID int64, to models.OrderStatus, then func(tx *sql.Tx, order *models.Order) error) (*models.Order, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	// Driver before order, as postToWallet requires
+	driverID, err := lockDriver(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(orderID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (order.DriverID == nil || *order.DriverID != driverID)) {
+		return nil, invalid("Order not found or not assigned to you")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	err = transitionOrder(tx, order, orderChange{To: to, Actor: models.ActorDriver, ActorID: userID})
+	if err != nil {
+		return nil, err
+	}
+	if then != nil {
+		if err := then(tx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+	return order, nil
+}
+
+// WalletTransactions returns a page of the driver's wallet history, newest first
+func (s *DriverService) WalletTransactions(userID int64, q WalletQuery) (*WalletPage, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return listWallet(s.ledger, driverID, q)
+}
+
+// WalletStatement returns the driver's wallet statement for a month given as
+// YYYY-MM; an empty month means the current one
+func (s *DriverService) WalletStatement(userID int64, month string) (*statement.Statement, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	start := time.Now()
+	if month != "" {
+		start, err = time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return nil, invalid("Invalid month format, use YYYY-MM")
+		}
+	}
+	return walletStatement(s.ledger, driver, start)
+}
+
+func (s *DriverService) driverIDForUser(userID int64) (int64, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return 0, internal("Database error", err)
+	}
+	return driver.ID, nil
+}
+
+// lockDriver locks the driver profile of a user in tx and returns its ID
+func lockDriver(tx repository.Querier, userID int64) (int64, error) {
+	driver, err := repository.NewDriverRepository(tx).GetByUserIDForUpdate(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return 0, internal("Database error", err)
+	}
+	return driver.ID, nil
+}
@@ -0,0 +1,374 @@
+package services_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/database"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+	"taxi-service/internal/services"
+)
+
+// testDSNEnv names the variable holding the connection string of a
+// disposable Postgres database for the tests that need one. The fixtures are
+// real rows; they are removed again when the test ends.
+const testDSNEnv = "TEST_DATABASE_URL"
+
+// openTestDB connects to the test database and brings its schema up to date,
+// or skips the test when none is configured
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set: this test needs a disposable Postgres database, see make test-db", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("connect to database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// The migration runner works on the global pool
+	database.DB = db
+	if _, err := database.MigrateUp(); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return db
+}
+
+type acceptFixtures struct {
+	db           *sql.DB
+	regionIDs    []int64
+	userIDs      []int64
+	driverUsers  []int64
+	driverIDs    []int64
+	orderIDs     []int64
+	startBalance float64
+}
+
+// createAcceptFixtures creates drivers holding balance each and pending orders
+// with the given service fee, all removed again when the test ends
+func createAcceptFixtures(t *testing.T, db *sql.DB, driverCount, orderCount int, fee, balance float64) *acceptFixtures {
+	t.Helper()
+
+	f := &acceptFixtures{db: db, startBalance: balance}
+	t.Cleanup(func() { f.cleanup(t) })
+	run := time.Now().UnixNano() % 1_000_000
+
+	regions := repository.NewRegionRepository(db)
+	var districtIDs []int64
+	for _, name := range []string{"accept test from", "accept test to"} {
+		region, err := regions.CreateRegion(repository.Names{UzLat: name, UzCyr: name, Ru: name})
+		if err != nil {
+			t.Fatalf("create region: %v", err)
+		}
+		f.regionIDs = append(f.regionIDs, region.ID)
+		district, err := regions.CreateDistrict(region.ID, repository.Names{UzLat: name, UzCyr: name, Ru: name})
+		if err != nil {
+			t.Fatalf("create district: %v", err)
+		}
+		districtIDs = append(districtIDs, district.ID)
+	}
+
+	users := repository.NewUserRepository(db)
+	customer, err := users.Create(fmt.Sprintf("+77%06d0000", run), "accept test customer", "-", models.RoleUser, models.LangUzLatin)
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	f.userIDs = append(f.userIDs, customer.ID)
+
+	drivers := repository.NewDriverRepository(db)
+	for i := 1; i <= driverCount; i++ {
+		user, err := users.Create(fmt.Sprintf("+77%06d%04d", run, i), fmt.Sprintf("accept test driver %d", i), "-", models.RoleDriver, models.LangUzLatin)
+		if err != nil {
+			t.Fatalf("create driver user: %v", err)
+		}
+		f.userIDs = append(f.userIDs, user.ID)
+
+		driver, err := drivers.Create(user.ID, user.Name, "Cobalt", fmt.Sprintf("01T%03d", i), nil)
+		if err != nil {
+			t.Fatalf("create driver: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE drivers SET status = 'approved', is_active = TRUE, balance = $1 WHERE id = $2`, balance, driver.ID); err != nil {
+			t.Fatalf("activate driver: %v", err)
+		}
+		f.driverUsers = append(f.driverUsers, user.ID)
+		f.driverIDs = append(f.driverIDs, driver.ID)
+	}
+
+	orders := repository.NewOrderRepository(db)
+	deadline := time.Now().Add(time.Hour)
+	passengers := int64(1)
+	for i := 0; i < orderCount; i++ {
+		order := &models.Order{
+			UserID:         customer.ID,
+			OrderType:      models.OrderTypeTaxi,
+			Status:         models.OrderStatusPending,
+			CustomerName:   customer.Name,
+			CustomerPhone:  customer.PhoneNumber,
+			FromRegionID:   f.regionIDs[0],
+			FromDistrictID: districtIDs[0],
+			ToRegionID:     f.regionIDs[1],
+			ToDistrictID:   districtIDs[1],
+			PassengerCount: &passengers,
+			ScheduledDate:  time.Now().AddDate(0, 0, 1),
+			TimeRangeStart: "09:00",
+			TimeRangeEnd:   "10:00",
+			Price:          fee * 10,
+			ServiceFee:     fee,
+			FinalPrice:     fee * 11,
+			AcceptDeadline: &deadline,
+		}
+		if err := orders.Create(order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+		f.orderIDs = append(f.orderIDs, order.ID)
+	}
+
+	return f
+}
+
+func (f *acceptFixtures) cleanup(t *testing.T) {
+	tx, err := f.db.Begin()
+	if err != nil {
+		t.Errorf("cleanup: %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var postings []int64
+	rows, err := tx.Query(`
+		SELECT DISTINCT e.transaction_id FROM ledger_entries e
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE a.driver_id = ANY($1)
+	`, pq.Array(f.driverIDs))
+	if err != nil {
+		t.Errorf("cleanup: %v", err)
+		return
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			t.Errorf("cleanup: %v", err)
+			return
+		}
+		postings = append(postings, id)
+	}
+	rows.Close()
+
+	// The ledger is append-only; this transaction may remove its own postings
+	if _, err := tx.Exec(`SET LOCAL ledger.allow_purge = 'on'`); err != nil {
+		t.Errorf("cleanup: %v", err)
+		return
+	}
+
+	steps := []struct {
+		query string
+		ids   []int64
+	}{
+		{`DELETE FROM ledger_entries WHERE transaction_id = ANY($1)`, postings},
+		{`DELETE FROM ledger_transactions WHERE id = ANY($1)`, postings},
+		{`DELETE FROM ledger_accounts WHERE driver_id = ANY($1)`, f.driverIDs},
+		{`DELETE FROM orders WHERE id = ANY($1)`, f.orderIDs},
+		{`DELETE FROM users WHERE id = ANY($1)`, f.userIDs}, // drivers and notifications cascade
+		{`DELETE FROM regions WHERE id = ANY($1)`, f.regionIDs},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step.query, pq.Array(step.ids)); err != nil {
+			t.Errorf("cleanup (%s): %v", step.query, err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("cleanup: %v", err)
+	}
+}
+
+// raceResult is how the accepts of one order ended
+type raceResult struct {
+	winners []int64 // Driver user IDs
+	losers  []error
+}
+
+// raceAccept has every driver accept the order at the same moment
+func raceAccept(svc *services.DriverService, driverUsers []int64, orderID int64) raceResult {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		result raceResult
+		ready  = make(chan struct{})
+	)
+
+	for _, userID := range driverUsers {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			<-ready
+			_, err := svc.AcceptOrder(userID, orderID)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				result.winners = append(result.winners, userID)
+			} else {
+				result.losers = append(result.losers, err)
+			}
+		}(userID)
+	}
+
+	close(ready)
+	wg.Wait()
+	return result
+}
+
+// checkLedger verifies assignments, balances and service fee postings after
+// the races: every assigned order is accepted and charged exactly once, and
+// every balance is the start balance less the fees of the orders won, never
+// below zero
+func (f *acceptFixtures) checkLedger(t *testing.T, fee float64) {
+	t.Helper()
+
+	orders := repository.NewOrderRepository(f.db)
+	drivers := repository.NewDriverRepository(f.db)
+	ledger := repository.NewLedgerRepository(f.db)
+
+	wins := map[int64]int{}
+	assigned := map[int64]bool{}
+	for _, id := range f.orderIDs {
+		order, err := orders.Get(id)
+		if err != nil {
+			t.Fatalf("load order %d: %v", id, err)
+		}
+		if (order.Status == models.OrderStatusAccepted) != (order.DriverID != nil) {
+			t.Errorf("order %d is %s with driver %v", id, order.Status, order.DriverID)
+		}
+		if order.DriverID != nil {
+			wins[*order.DriverID]++
+			assigned[id] = true
+		}
+	}
+
+	charges := map[int64]int{}
+	for _, id := range f.driverIDs {
+		driver, err := drivers.Get(id)
+		if err != nil {
+			t.Fatalf("load driver %d: %v", id, err)
+		}
+		if driver.Balance < 0 {
+			t.Errorf("driver %d balance is negative: %.2f", id, driver.Balance)
+		}
+		if want := f.startBalance - fee*float64(wins[id]); driver.Balance != want {
+			t.Errorf("driver %d balance %.2f, want %.2f for %d orders", id, driver.Balance, want, wins[id])
+		}
+
+		history, err := ledger.ListForDriver(id)
+		if err != nil {
+			t.Fatalf("load ledger of driver %d: %v", id, err)
+		}
+		for _, posting := range history {
+			if posting.Kind != models.LedgerServiceFee || posting.OrderID == nil {
+				t.Errorf("driver %d has an unexpected %s posting", id, posting.Kind)
+				continue
+			}
+			charges[*posting.OrderID]++
+		}
+	}
+
+	for _, id := range f.orderIDs {
+		want := 0
+		if assigned[id] {
+			want = 1
+		}
+		if charges[id] != want {
+			t.Errorf("order %d has %d service fee postings, want %d", id, charges[id], want)
+		}
+	}
+}
+
+func newAcceptService(db *sql.DB) *services.DriverService {
+	return services.NewDriverService(db, &config.DispatchConfig{}, noAlerts{}, noEvents{}, noJobs{})
+}
+
+// TestAcceptOrderConcurrently races drivers who can all afford every order:
+// each order must go to exactly one of them and the rest must get a conflict
+func TestAcceptOrderConcurrently(t *testing.T) {
+	db := openTestDB(t)
+
+	const drivers, orders, fee = 10, 20, 5000.0
+	f := createAcceptFixtures(t, db, drivers, orders, fee, fee*orders)
+	svc := newAcceptService(db)
+
+	for _, orderID := range f.orderIDs {
+		result := raceAccept(svc, f.driverUsers, orderID)
+		if len(result.winners) != 1 {
+			t.Errorf("order %d accepted by %d drivers, want exactly 1", orderID, len(result.winners))
+		}
+		for _, err := range result.losers {
+			if services.KindOf(err) != services.KindConflict {
+				t.Errorf("order %d: losing accept failed with %v, want a conflict", orderID, err)
+			}
+		}
+	}
+
+	f.checkLedger(t, fee)
+}
+
+// TestAcceptOrderConcurrentlyScarceBalance races drivers who can each afford a
+// single order: nobody may be charged past their balance
+func TestAcceptOrderConcurrentlyScarceBalance(t *testing.T) {
+	db := openTestDB(t)
+
+	const drivers, orders, fee = 5, 10, 5000.0
+	f := createAcceptFixtures(t, db, drivers, orders, fee, fee)
+	svc := newAcceptService(db)
+
+	won := 0
+	for _, orderID := range f.orderIDs {
+		result := raceAccept(svc, f.driverUsers, orderID)
+		if len(result.winners) > 1 {
+			t.Errorf("order %d accepted by %d drivers", orderID, len(result.winners))
+		}
+		won += len(result.winners)
+		for _, err := range result.losers {
+			if kind := services.KindOf(err); kind != services.KindConflict && kind != services.KindInvalid {
+				t.Errorf("order %d: losing accept failed with %v, want a conflict or insufficient balance", orderID, err)
+			}
+		}
+	}
+	if won != drivers {
+		t.Errorf("%d orders accepted, want one per driver (%d)", won, drivers)
+	}
+
+	f.checkLedger(t, fee)
+}
+
+type noAlerts struct{}
+
+func (noAlerts) NewOrder(*models.Order, models.Language)                      {}
+func (noAlerts) OrderCancelled(*models.Order, string, models.Language)        {}
+func (noAlerts) DriverApplication(*models.DriverApplication, models.Language) {}
+func (noAlerts) Feedback(*models.Feedback, *models.User)                      {}
+
+type noEvents struct{}
+
+func (noEvents) OrderCreated([]int64, *models.Order)      {}
+func (noEvents) OrderStatusChanged(int64, *models.Order)  {}
+func (noEvents) NotificationCreated(*models.Notification) {}
+
+type noJobs struct{}
+
+func (noJobs) EnqueueTx(repository.Querier, string, interface{}) error { return nil }
+func (noJobs) Wake()                                                   {}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"taxi-service/internal/config"
+)
+
+func TestReleaseRefund(t *testing.T) {
+	dispatch := &config.DispatchConfig{ReleaseFullRefundHours: 24, ReleaseNoRefundHours: 2, ReleasePenaltyPercent: 50}
+	tests := []struct {
+		name                    string
+		untilPickup             time.Duration
+		wantRefund, wantPenalty float64
+	}{
+		{"well ahead", 48 * time.Hour, 1000, 0},
+		{"exactly the full refund cutoff", 24 * time.Hour, 1000, 0},
+		{"just inside the full refund cutoff", 24*time.Hour - time.Minute, 500, 500},
+		{"exactly the no refund cutoff", 2 * time.Hour, 500, 500},
+		{"inside the no refund cutoff", 2*time.Hour - time.Minute, 0, 1000},
+		{"pickup passed", -time.Hour, 0, 1000},
+	}
+	for _, tt := range tests {
+		refund, penalty := releaseRefund(dispatch, 1000, tt.untilPickup)
+		if refund != tt.wantRefund || penalty != tt.wantPenalty {
+			t.Errorf("%s: refund %v penalty %v, want %v and %v", tt.name, refund, penalty, tt.wantRefund, tt.wantPenalty)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import "errors"
+
+// ErrorKind classifies a service failure so transports can map it to a status code
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+)
+
+// Error is a failure with a message that is safe to show to API clients
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of a service error, or KindInternal for anything else
+func KindOf(err error) ErrorKind {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Kind
+	}
+	return KindInternal
+}
+
+func invalid(message string) error {
+	return &Error{Kind: KindInvalid, Message: message}
+}
+
+func unauthorized(message string) error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+func forbidden(message string) error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+func notFound(message string) error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func conflict(message string) error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func internal(message string, err error) error {
+	return &Error{Kind: KindInternal, Message: message, Err: err}
+}
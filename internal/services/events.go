@@ -0,0 +1,41 @@
+package services
+
+import (
+	"log"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// Events pushes real-time updates to connected users; the WebSocket hub
+// implements it
+type Events interface {
+	OrderCreated(driverUserIDs []int64, order *models.Order)
+	OrderStatusChanged(userID int64, order *models.Order)
+	NotificationCreated(notification *models.Notification)
+}
+
+// pushNotification stores an in-app notification and pushes it to its owner
+func pushNotification(notifications *repository.NotificationRepository, events Events, userID int64, title, message, notifType string, relatedID int64) error {
+	notification, err := notifications.Create(userID, title, message, notifType, relatedID)
+	if err != nil {
+		return err
+	}
+	events.NotificationCreated(notification)
+	return nil
+}
+
+// publishOrderStatus pushes the current state of an order to its customer
+// and, when one is assigned, to its driver
+func publishOrderStatus(drivers *repository.DriverRepository, events Events, order *models.Order) {
+	events.OrderStatusChanged(order.UserID, order)
+	if order.DriverID == nil {
+		return
+	}
+	driver, err := drivers.Get(*order.DriverID)
+	if err != nil {
+		log.Printf("Failed to load driver %d for status push of order %d: %v", *order.DriverID, order.ID, err)
+		return
+	}
+	events.OrderStatusChanged(driver.UserID, order)
+}
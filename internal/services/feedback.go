@@ -0,0 +1,42 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// FeedbackService stores user feedback
+type FeedbackService struct {
+	feedback *repository.FeedbackRepository
+	users    *repository.UserRepository
+	alerts   AdminAlerts
+}
+
+// NewFeedbackService creates a new feedback service
+func NewFeedbackService(db *sql.DB, alerts AdminAlerts) *FeedbackService {
+	return &FeedbackService{
+		feedback: repository.NewFeedbackRepository(db),
+		users:    repository.NewUserRepository(db),
+		alerts:   alerts,
+	}
+}
+
+// Submit stores a feedback message from a user and forwards it to the admins
+func (s *FeedbackService) Submit(userID int64, message string) (*models.Feedback, error) {
+	feedback, err := s.feedback.Create(userID, message)
+	if err != nil {
+		return nil, internal("Failed to submit feedback", err)
+	}
+
+	user, err := s.users.Get(userID)
+	if err != nil {
+		log.Printf("Failed to load user %d for feedback alert: %v", userID, err)
+		return feedback, nil
+	}
+	s.alerts.Feedback(feedback, user)
+
+	return feedback, nil
+}
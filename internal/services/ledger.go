@@ -0,0 +1,179 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// walletPosting is a change to one driver's or customer's wallet, balanced
+// against a platform account
+type walletPosting struct {
+	Kind        models.LedgerKind
+	DriverID    int64   // Driver whose wallet changes, for postToWallet
+	CustomerID  int64   // Customer whose wallet changes, for postToCustomerWallet
+	Amount      float64 // Added to the wallet balance; negative for charges
+	Counterpart string  // Platform account code, see models.Account*
+	OrderID     *int64
+	Description string
+	CreatedBy   *int64 // Admin making a manual posting
+}
+
+// postToWallet is the only way driver money moves. It updates the cached
+// balance and writes the matching ledger transaction in tx, so either both
+// happen or neither does. Charges the balance does not cover are rejected;
+// zero amounts are not posted and return a nil transaction.
+//
+// Lock order: a transaction that posts for an order must lock the driver row
+// before the order (and before any trip), as AcceptOrder and TripService.Attach
+// do; locking the order first and reaching the driver here can deadlock with
+// them. lockOrderAndDriver does this when the driver is not known up front.
+func postToWallet(tx repository.Querier, p walletPosting) (*models.LedgerTransaction, error) {
+	amount := roundAmount(p.Amount)
+	if amount == 0 {
+		return nil, nil
+	}
+
+	drivers := repository.NewDriverRepository(tx)
+	if amount < 0 {
+		err := drivers.Debit(p.DriverID, -amount)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, invalid("Insufficient balance")
+		}
+		if err != nil {
+			return nil, internal("Failed to update balance", err)
+		}
+	} else {
+		err := drivers.AddBalance(p.DriverID, amount)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, notFound("Driver not found")
+		}
+		if err != nil {
+			return nil, internal("Failed to update balance", err)
+		}
+	}
+
+	ledger := repository.NewLedgerRepository(tx)
+	wallet, err := ledger.DriverAccount(p.DriverID)
+	if err != nil {
+		return nil, internal("Failed to open driver wallet", err)
+	}
+	return writePosting(ledger, wallet, amount, p)
+}
+
+// lockOrderAndDriver locks an order and, when it has one, its driver, driver
+// first as postToWallet requires. An order that changed hands between reading
+// it and locking it is reported as a conflict.
+func lockOrderAndDriver(tx repository.Querier, orderID int64) (*models.Order, error) {
+	orders := repository.NewOrderRepository(tx)
+	current, err := orders.Get(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if current.DriverID != nil {
+		if _, err := repository.NewDriverRepository(tx).GetForUpdate(*current.DriverID); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := orders.GetForUpdate(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.DriverID != nil && (current.DriverID == nil || *order.DriverID != *current.DriverID) {
+		return nil, conflict("Order changed hands while cancelling, please try again")
+	}
+	return order, nil
+}
+
+// postToCustomerWallet moves money in or out of a customer's wallet. Customer
+// wallets have no cached balance; the account row is locked so that two
+// charges cannot both spend the same money, and charges the wallet does not
+// cover are rejected. Zero amounts are not posted.
+func postToCustomerWallet(tx repository.Querier, p walletPosting) (*models.LedgerTransaction, error) {
+	amount := roundAmount(p.Amount)
+	if amount == 0 {
+		return nil, nil
+	}
+
+	ledger := repository.NewLedgerRepository(tx)
+	wallet, err := ledger.CustomerAccount(p.CustomerID)
+	if err != nil {
+		return nil, internal("Failed to open customer wallet", err)
+	}
+	if amount < 0 {
+		if err := ledger.LockAccount(wallet); err != nil {
+			return nil, internal("Failed to lock customer wallet", err)
+		}
+		balance, err := ledger.AccountBalance(wallet)
+		if err != nil {
+			return nil, internal("Failed to read wallet balance", err)
+		}
+		if balance+amount < 0 {
+			return nil, invalid("Insufficient wallet balance")
+		}
+	}
+	return writePosting(ledger, wallet, amount, p)
+}
+
+// writePosting records amount on account, balanced against the posting's
+// platform counterpart
+func writePosting(ledger *repository.LedgerRepository, account int64, amount float64, p walletPosting) (*models.LedgerTransaction, error) {
+	counterpart, err := ledger.PlatformAccount(p.Counterpart)
+	if err != nil {
+		return nil, internal("Failed to open ledger account", err)
+	}
+
+	posted, err := ledger.CreateTransaction(&models.LedgerTransaction{
+		Kind:        p.Kind,
+		OrderID:     p.OrderID,
+		Description: p.Description,
+		CreatedBy:   p.CreatedBy,
+		Entries: []models.LedgerEntry{
+			{AccountID: account, Amount: amount},
+			{AccountID: counterpart, Amount: -amount},
+		},
+	})
+	if err != nil {
+		return nil, internal("Failed to record ledger transaction", err)
+	}
+	return posted, nil
+}
+
+// LedgerService checks driver balances against the ledger
+type LedgerService struct {
+	ledger *repository.LedgerRepository
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(db *sql.DB) *LedgerService {
+	return &LedgerService{ledger: repository.NewLedgerRepository(db)}
+}
+
+// ReconcileReport lists everything in the ledger that does not add up
+type ReconcileReport struct {
+	Drift      []repository.LedgerDrift
+	Imbalances []repository.LedgerImbalance
+}
+
+// Clean reports whether the ledger and the cached balances agree
+func (r *ReconcileReport) Clean() bool {
+	return len(r.Drift) == 0 && len(r.Imbalances) == 0
+}
+
+// Reconcile compares every driver's cached balance with the sum of their
+// wallet entries and checks that every ledger transaction balances. It only
+// reports; fixing drift takes a deliberate correcting posting.
+func (s *LedgerService) Reconcile() (*ReconcileReport, error) {
+	drift, err := s.ledger.ListDrift()
+	if err != nil {
+		return nil, internal("Failed to compare balances", err)
+	}
+	imbalances, err := s.ledger.ListImbalances()
+	if err != nil {
+		return nil, internal("Failed to check ledger transactions", err)
+	}
+	return &ReconcileReport{Drift: drift, Imbalances: imbalances}, nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// ServiceRoute is a region pair a driver serves
+type ServiceRoute struct {
+	FromRegionID int64
+	ToRegionID   int64
+}
+
+// AvailabilityWindow is a time window on a date when a driver can drive
+type AvailabilityWindow struct {
+	Date      time.Time
+	StartTime string // HH:MM
+	EndTime   string // HH:MM
+}
+
+// Routes returns the region pairs the driver serves
+func (s *DriverService) Routes(userID int64) ([]models.DriverRoute, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := s.drivers.ListRoutes(driverID)
+	if err != nil {
+		return nil, internal("Failed to fetch routes", err)
+	}
+	return routes, nil
+}
+
+// SetRoutes replaces the region pairs the driver serves. With none the driver
+// is offered orders on every route.
+func (s *DriverService) SetRoutes(userID int64, routes []ServiceRoute) ([]models.DriverRoute, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]models.DriverRoute, 0, len(routes))
+	for _, route := range routes {
+		if route.FromRegionID == route.ToRegionID {
+			return nil, invalid("From and To regions must be different")
+		}
+		for _, id := range []int64{route.FromRegionID, route.ToRegionID} {
+			_, err := s.regions.GetRegion(id)
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, notFound(fmt.Sprintf("Region %d not found", id))
+			}
+			if err != nil {
+				return nil, internal("Database error", err)
+			}
+		}
+		rows = append(rows, models.DriverRoute{FromRegionID: route.FromRegionID, ToRegionID: route.ToRegionID})
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	if err := repository.NewDriverRepository(tx).ReplaceRoutes(driverID, rows); err != nil {
+		return nil, internal("Failed to save routes", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+	return s.Routes(userID)
+}
+
+// Availability returns the driver's availability windows from today on
+func (s *DriverService) Availability(userID int64) ([]models.DriverAvailability, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	windows, err := s.drivers.ListAvailability(driverID, today())
+	if err != nil {
+		return nil, internal("Failed to fetch availability", err)
+	}
+	return windows, nil
+}
+
+// SetAvailability replaces the driver's availability windows. With none the
+// driver is offered orders on every date.
+func (s *DriverService) SetAvailability(userID int64, windows []AvailabilityWindow) ([]models.DriverAvailability, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]models.DriverAvailability, 0, len(windows))
+	for _, window := range windows {
+		start, okStart := minuteOfDay(window.StartTime)
+		end, okEnd := minuteOfDay(window.EndTime)
+		if !okStart || !okEnd {
+			return nil, invalid("Availability times must be HH:MM")
+		}
+		if end <= start {
+			return nil, invalid("Availability window must end after it starts")
+		}
+		if dateOf(window.Date).Before(today()) {
+			return nil, invalid("Availability date is in the past")
+		}
+		rows = append(rows, models.DriverAvailability{
+			Date:      window.Date,
+			StartTime: formatMinuteOfDay(start),
+			EndTime:   formatMinuteOfDay(end),
+		})
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	if err := repository.NewDriverRepository(tx).ReplaceAvailability(driverID, rows); err != nil {
+		return nil, internal("Failed to save availability", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+	return s.Availability(userID)
+}
+
+// matchDrivers returns the drivers an order can be offered to, best ranked
+// first: those whose car fits it, who serve its route and who are available
+// when it is due
+func matchDrivers(drivers *repository.DriverRepository, order *models.Order, nearbyKm float64) ([]models.Driver, error) {
+	candidates, err := drivers.ListMatching(order, today())
+	if err != nil {
+		return nil, err
+	}
+	windows, err := drivers.ListAvailabilityOn(order.ScheduledDate)
+	if err != nil {
+		return nil, err
+	}
+	byDriver := map[int64][]models.DriverAvailability{}
+	for _, window := range windows {
+		byDriver[window.DriverID] = append(byDriver[window.DriverID], window)
+	}
+
+	matched := make([]models.Driver, 0, len(candidates))
+	for _, driver := range candidates {
+		if availableFor(byDriver[driver.ID], order) {
+			matched = append(matched, driver)
+		}
+	}
+	rankDrivers(matched, order, nearbyKm)
+	return matched, nil
+}
+
+// availableFor reports whether an order is due inside one of a driver's
+// windows: on a window's date with its time range overlapping the window.
+// Both ranges are half-open, [start, end), as in inTimeWindow, so a range
+// that only touches a window does not overlap it; an order without an end
+// is the single minute it starts. A driver without windows is always
+// available.
+func availableFor(windows []models.DriverAvailability, order *models.Order) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	start, hasStart := minuteOfDay(order.TimeRangeStart)
+	end, hasEnd := minuteOfDay(order.TimeRangeEnd)
+	if !hasEnd || end < start {
+		end = start
+	}
+
+	for _, window := range windows {
+		if !sameDay(window.Date, order.ScheduledDate) {
+			continue
+		}
+		if !hasStart {
+			return true
+		}
+		from, _ := minuteOfDay(window.StartTime)
+		to, _ := minuteOfDay(window.EndTime)
+		if end == start && inTimeWindow(start, from, to) {
+			return true
+		}
+		if end > start && start < to && end > from {
+			return true
+		}
+	}
+	return false
+}
+
+// rankDrivers sorts drivers for an order: those based within nearbyKm of the
+// pickup first, then by rating, then by distance, unknown distances last
+func rankDrivers(drivers []models.Driver, order *models.Order, nearbyKm float64) {
+	type ranked struct {
+		km     float64
+		known  bool
+		nearby bool
+	}
+	ranks := make(map[int64]ranked, len(drivers))
+	for _, driver := range drivers {
+		km, known := distanceKm(driver.BaseLatitude, driver.BaseLongitude, order.FromLatitude, order.FromLongitude)
+		ranks[driver.ID] = ranked{km: km, known: known, nearby: known && km <= nearbyKm}
+	}
+
+	sort.SliceStable(drivers, func(i, j int) bool {
+		a, b := ranks[drivers[i].ID], ranks[drivers[j].ID]
+		if a.nearby != b.nearby {
+			return a.nearby
+		}
+		if drivers[i].Rating != drivers[j].Rating {
+			return drivers[i].Rating > drivers[j].Rating
+		}
+		if a.known != b.known {
+			return a.known
+		}
+		return a.km < b.km
+	})
+}
+
+// rankOrders sorts orders by how close their pickup is to the driver's base,
+// keeping the given order among those at an unknown distance, which go last
+func rankOrders(orders []models.Order, driver *models.Driver) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, knownA := distanceKm(driver.BaseLatitude, driver.BaseLongitude, orders[i].FromLatitude, orders[i].FromLongitude)
+		b, knownB := distanceKm(driver.BaseLatitude, driver.BaseLongitude, orders[j].FromLatitude, orders[j].FromLongitude)
+		if knownA != knownB {
+			return knownA
+		}
+		return knownA && a < b
+	})
+}
+
+// distanceKm returns the distance between two points when both are known
+func distanceKm(lat1, lon1, lat2, lon2 *float64) (float64, bool) {
+	if lat1 == nil || lon1 == nil || lat2 == nil || lon2 == nil {
+		return 0, false
+	}
+	return haversineKm(*lat1, *lon1, *lat2, *lon2), true
+}
+
+// today returns midnight at the start of the current day in server local
+// time, the zone pickup times are reckoned in
+func today() time.Time {
+	return dateOf(time.Now().In(time.Local))
+}
+
+// dateOf returns local midnight on t's calendar date, so dates parsed from
+// requests compare with today regardless of the zone they were parsed in
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
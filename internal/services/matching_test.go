@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+func TestAvailableFor(t *testing.T) {
+	day := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	window := func(date time.Time, from, to string) models.DriverAvailability {
+		return models.DriverAvailability{Date: date, StartTime: from, EndTime: to}
+	}
+	order := func(start, end string) *models.Order {
+		return &models.Order{ScheduledDate: day, TimeRangeStart: start, TimeRangeEnd: end}
+	}
+
+	tests := []struct {
+		name    string
+		windows []models.DriverAvailability
+		order   *models.Order
+		want    bool
+	}{
+		{"no windows", nil, order("09:00", "10:00"), true},
+		{"inside a window", []models.DriverAvailability{window(day, "08:00", "12:00")}, order("09:00", "10:00"), true},
+		{"overlapping the start of a window", []models.DriverAvailability{window(day, "09:30", "12:00")}, order("09:00", "10:00"), true},
+		{"overlapping the end of a window", []models.DriverAvailability{window(day, "08:00", "09:30")}, order("09:00", "10:00"), true},
+		{"starting as the window ends", []models.DriverAvailability{window(day, "08:00", "09:00")}, order("09:00", "10:00"), false},
+		{"ending as the window starts", []models.DriverAvailability{window(day, "10:00", "12:00")}, order("09:00", "10:00"), false},
+		{"exactly the window", []models.DriverAvailability{window(day, "09:00", "10:00")}, order("09:00", "10:00"), true},
+		{"last minute of the window", []models.DriverAvailability{window(day, "08:00", "09:01")}, order("09:00", "10:00"), true},
+		{"first minute of the window", []models.DriverAvailability{window(day, "09:59", "12:00")}, order("09:00", "10:00"), true},
+		{"no end as the window starts", []models.DriverAvailability{window(day, "11:00", "12:00")}, order("11:00", ""), true},
+		{"no end as the window ends", []models.DriverAvailability{window(day, "08:00", "11:00")}, order("11:00", ""), false},
+		{"after every window", []models.DriverAvailability{window(day, "06:00", "08:00"), window(day, "12:00", "14:00")}, order("09:00", "10:00"), false},
+		{"in the second window", []models.DriverAvailability{window(day, "06:00", "08:00"), window(day, "09:00", "11:00")}, order("09:00", "10:00"), true},
+		{"window on another day", []models.DriverAvailability{window(day.AddDate(0, 0, 1), "00:00", "23:59")}, order("09:00", "10:00"), false},
+		{"no end takes the start", []models.DriverAvailability{window(day, "08:00", "12:00")}, order("11:00", ""), true},
+		{"no time on the window's day", []models.DriverAvailability{window(day, "08:00", "09:00")}, order("", ""), true},
+		{"no time on another day", []models.DriverAvailability{window(day.AddDate(0, 0, -1), "08:00", "09:00")}, order("", ""), false},
+	}
+	for _, tt := range tests {
+		if got := availableFor(tt.windows, tt.order); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRankDrivers(t *testing.T) {
+	// Pickup in central Tashkent
+	order := &models.Order{FromLatitude: ptr(41.3111), FromLongitude: ptr(69.2797)}
+	driver := func(id int64, rating float64, lat, lon *float64) models.Driver {
+		return models.Driver{ID: id, Rating: rating, BaseLatitude: lat, BaseLongitude: lon}
+	}
+	drivers := []models.Driver{
+		driver(1, 5.0, nil, nil),                   // unknown base
+		driver(2, 4.0, ptr(41.3200), ptr(69.2800)), // about 1 km away
+		driver(3, 4.8, ptr(41.3500), ptr(69.3000)), // about 5 km away
+		driver(4, 4.8, ptr(39.6542), ptr(66.9597)), // Samarkand
+		driver(5, 4.8, nil, nil),                   // unknown base
+		driver(6, 4.0, ptr(41.3111), ptr(69.2797)), // at the pickup
+		driver(7, 3.0, ptr(40.3834), ptr(71.7870)), // Fergana
+	}
+
+	rankDrivers(drivers, order, 10)
+
+	// Nearby drivers first by rating then distance, then the rest the same
+	// way with unknown distances after known ones of the same rating
+	want := []int64{3, 6, 2, 1, 4, 5, 7}
+	for i, d := range drivers {
+		if d.ID != want[i] {
+			got := make([]int64, len(drivers))
+			for j := range drivers {
+				got[j] = drivers[j].ID
+			}
+			t.Fatalf("ranked %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRankDriversWithoutPickupPoint(t *testing.T) {
+	drivers := []models.Driver{
+		{ID: 1, Rating: 4.0, BaseLatitude: ptr(41.3), BaseLongitude: ptr(69.3)},
+		{ID: 2, Rating: 4.9},
+		{ID: 3, Rating: 4.0},
+	}
+	rankDrivers(drivers, &models.Order{}, 10)
+
+	// With no pickup point every distance is unknown: by rating, otherwise as given
+	for i, want := range []int64{2, 1, 3} {
+		if drivers[i].ID != want {
+			t.Fatalf("driver %d is %d, want %d", i, drivers[i].ID, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// NotificationService serves in-app notifications
+type NotificationService struct {
+	notifications *repository.NotificationRepository
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *sql.DB) *NotificationService {
+	return &NotificationService{notifications: repository.NewNotificationRepository(db)}
+}
+
+// List returns the user's notifications, newest first
+func (s *NotificationService) List(userID int64, unreadOnly bool) ([]models.Notification, error) {
+	notifications, err := s.notifications.ListForUser(userID, unreadOnly)
+	if err != nil {
+		return nil, internal("Failed to fetch notifications", err)
+	}
+	return notifications, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(userID, notificationID int64) error {
+	err := s.notifications.MarkRead(userID, notificationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Notification not found")
+	}
+	if err != nil {
+		return internal("Failed to update notification", err)
+	}
+	return nil
+}
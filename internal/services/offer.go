@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// JobOfferOrder is the kind of the background job that offers an order to
+// the drivers it suits
+const JobOfferOrder = "order.offer"
+
+// Jobs queues work to run in the background; the job queue implements it.
+// Jobs are enqueued in the transaction of the change that needs them, so a
+// committed change never lacks its job; Wake after the commit gets an idle
+// worker onto them without waiting for its next poll.
+type Jobs interface {
+	EnqueueTx(tx repository.Querier, kind string, payload interface{}) error
+	Wake()
+}
+
+type offerOrderPayload struct {
+	OrderID int64 `json:"order_id"`
+}
+
+// offerToDrivers queues, in tx, the offer of an order that is open for
+// acceptance
+func offerToDrivers(tx repository.Querier, jobs Jobs, orderID int64) error {
+	if err := jobs.EnqueueTx(tx, JobOfferOrder, offerOrderPayload{OrderID: orderID}); err != nil {
+		return internal("Failed to queue order offer", err)
+	}
+	return nil
+}
+
+// OfferOrder runs an order offer job: it notifies the drivers the order
+// suits, best ranked first. Their notifications are stored in batches in one
+// transaction, so a failed run leaves nothing behind for its retry to repeat.
+// An order that was taken, cancelled or expired while the job waited is
+// skipped.
+func (s *OrderService) OfferOrder(ctx context.Context, payload json.RawMessage) error {
+	var p offerOrderPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	order, err := s.orders.Get(p.OrderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load order %d: %w", p.OrderID, err)
+	}
+	if order.Status != models.OrderStatusPending {
+		return nil
+	}
+
+	matched, err := matchDrivers(s.drivers, order, s.dispatch.NearbyRadiusKm)
+	if err != nil {
+		return fmt.Errorf("match drivers for order %d: %w", order.ID, err)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	driverUserIDs := make([]int64, 0, len(matched))
+	for _, driver := range matched {
+		driverUserIDs = append(driverUserIDs, driver.UserID)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	title := "New Order Available"
+	message := fmt.Sprintf("A new %s order is available. Check your orders page.", order.OrderType)
+	notifications, err := repository.NewNotificationRepository(tx).CreateBatch(driverUserIDs, title, message, "new_order", order.ID)
+	if err != nil {
+		return fmt.Errorf("notify drivers about order %d: %w", order.ID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.events.OrderCreated(driverUserIDs, order)
+	for i := range notifications {
+		s.events.NotificationCreated(&notifications[i])
+	}
+	return nil
+}
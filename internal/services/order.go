@@ -0,0 +1,461 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// OrderService implements order creation, lookup and cancellation for customers
+type OrderService struct {
+	db            *sql.DB
+	orders        *repository.OrderRepository
+	orderEvents   *repository.OrderEventRepository
+	pricing       *repository.PricingRepository
+	trips         *repository.TripRepository
+	drivers       *repository.DriverRepository
+	users         *repository.UserRepository
+	notifications *repository.NotificationRepository
+	alerts        AdminAlerts
+	events        Events
+	jobs          Jobs
+	dispatch      *config.DispatchConfig
+	quotes        *config.PricingConfig
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(db *sql.DB, dispatch *config.DispatchConfig, quotes *config.PricingConfig, alerts AdminAlerts, events Events, jobs Jobs) *OrderService {
+	return &OrderService{
+		db:            db,
+		dispatch:      dispatch,
+		quotes:        quotes,
+		orders:        repository.NewOrderRepository(db),
+		orderEvents:   repository.NewOrderEventRepository(db),
+		pricing:       repository.NewPricingRepository(db),
+		trips:         repository.NewTripRepository(db),
+		drivers:       repository.NewDriverRepository(db),
+		users:         repository.NewUserRepository(db),
+		notifications: repository.NewNotificationRepository(db),
+		alerts:        alerts,
+		events:        events,
+		jobs:          jobs,
+	}
+}
+
+// Route describes the pickup and drop-off points of an order
+type Route struct {
+	FromRegionID   int64
+	FromDistrictID int64
+	FromLatitude   *float64
+	FromLongitude  *float64
+	FromAddress    *string
+	ToRegionID     int64
+	ToDistrictID   int64
+	ToLatitude     *float64
+	ToLongitude    *float64
+	ToAddress      *string
+}
+
+// Schedule describes when an order should be carried out
+type Schedule struct {
+	Date           time.Time
+	TimeRangeStart string
+	TimeRangeEnd   string
+}
+
+// CreateTaxiOrderInput holds the data needed to book a taxi
+type CreateTaxiOrderInput struct {
+	UserID         int64
+	CustomerName   string
+	CustomerPhone  string
+	Route          Route
+	PassengerCount int
+	Schedule       Schedule
+	Notes          string
+	PaymentMethod  string // cash, card or wallet; empty means cash
+	QuoteToken     string // Books the order at a quoted price
+}
+
+// CreateDeliveryOrderInput holds the data needed to book a delivery
+type CreateDeliveryOrderInput struct {
+	UserID         int64
+	CustomerName   string
+	CustomerPhone  string
+	RecipientPhone string
+	Route          Route
+	DeliveryType   string
+	Schedule       Schedule
+	Notes          string
+	PaymentMethod  string // cash, card or wallet; empty means cash
+	QuoteToken     string // Books the order at a quoted price
+}
+
+// OrderFilter narrows down order listings; empty fields match everything
+type OrderFilter struct {
+	Status string
+	Type   string
+}
+
+// CreateTaxiOrder prices and stores a new taxi order, then notifies drivers
+func (s *OrderService) CreateTaxiOrder(in CreateTaxiOrderInput) (*models.Order, error) {
+	paymentMethod, err := parsePaymentMethod(in.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	req := PriceRequest{OrderType: models.OrderTypeTaxi, Route: in.Route, PassengerCount: in.PassengerCount, Schedule: in.Schedule}
+	price, err := s.priceOrder(in.UserID, req, in.QuoteToken)
+	if err != nil {
+		return nil, err
+	}
+
+	passengerCount := int64(in.PassengerCount)
+	order := newOrder(in.UserID, models.OrderTypeTaxi, in.CustomerName, in.CustomerPhone, in.Route, in.Schedule, in.Notes, price, s.dispatch.AcceptWindow())
+	order.PassengerCount = &passengerCount
+	order.PaymentMethod = paymentMethod
+
+	if err := s.insertOrder(order, price, s.offerNewOrder); err != nil {
+		return nil, err
+	}
+
+	s.jobs.Wake()
+	s.alerts.NewOrder(order, userLanguage(s.users, order.UserID))
+
+	return order, nil
+}
+
+// CreateDeliveryOrder prices and stores a new delivery order, then notifies drivers
+func (s *OrderService) CreateDeliveryOrder(in CreateDeliveryOrderInput) (*models.Order, error) {
+	paymentMethod, err := parsePaymentMethod(in.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	req := PriceRequest{OrderType: models.OrderTypeDelivery, Route: in.Route, Schedule: in.Schedule}
+	price, err := s.priceOrder(in.UserID, req, in.QuoteToken)
+	if err != nil {
+		return nil, err
+	}
+
+	order := newOrder(in.UserID, models.OrderTypeDelivery, in.CustomerName, in.CustomerPhone, in.Route, in.Schedule, in.Notes, price, s.dispatch.AcceptWindow())
+	order.RecipientPhone = &in.RecipientPhone
+	order.DeliveryType = &in.DeliveryType
+	order.PaymentMethod = paymentMethod
+
+	if err := s.insertOrder(order, price, s.offerNewOrder); err != nil {
+		return nil, err
+	}
+
+	s.jobs.Wake()
+	s.alerts.NewOrder(order, userLanguage(s.users, order.UserID))
+
+	return order, nil
+}
+
+// ListUserOrders returns the orders placed by a user, newest first
+func (s *OrderService) ListUserOrders(userID int64, filter OrderFilter) ([]models.Order, error) {
+	orders, err := s.orders.List(repository.OrderFilter{
+		UserID: userID,
+		Status: filter.Status,
+		Type:   filter.Type,
+	})
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+	return orders, nil
+}
+
+// GetOrder returns an order; regular users may only see their own orders
+func (s *OrderService) GetOrder(userID int64, role models.UserRole, orderID int64) (*models.Order, error) {
+	var order *models.Order
+	var err error
+	if role == models.RoleUser {
+		order, err = s.orders.GetForUser(orderID, userID)
+	} else {
+		order, err = s.orders.Get(orderID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return order, nil
+}
+
+// CancelOrder cancels a pending or accepted order. The cancellation policy
+// decides how much of the service fee the driver gets back and what the
+// customer owes; the outcome is stored on the order.
+func (s *OrderService) CancelOrder(userID, orderID int64, reason string) (*CancellationOutcome, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrderAndDriver(tx, orderID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && order.UserID != userID) {
+		return nil, notFound("Order not found")
+	}
+	if KindOf(err) == KindConflict {
+		return nil, err
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	policies, err := repository.NewCancellationPolicyRepository(tx).ListForStatus(order.Status)
+	if err != nil {
+		return nil, internal("Failed to load cancellation policy", err)
+	}
+	outcome := decideCancellation(policies, order, time.Until(pickupTime(order)))
+
+	change := orderChange{
+		To:      models.OrderStatusCancelled,
+		Actor:   models.ActorCustomer,
+		ActorID: userID,
+		Reason:  reason,
+		Details: map[string]interface{}{"driver_refund": outcome.DriverRefund, "cancellation_fee": outcome.CustomerFee},
+	}
+	if err := transitionOrder(tx, order, change); err != nil {
+		return nil, err
+	}
+
+	if err := settleCancellation(tx, order, change, outcome); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	s.alerts.OrderCancelled(order, reason, userLanguage(s.users, userID))
+	s.publishStatusChange(order.ID)
+	if order.DriverID != nil {
+		s.notifyDriverOfCancellation(order, outcome, policies)
+	}
+
+	return &outcome, nil
+}
+
+// ProcessOverdueOrders sweeps pending orders whose accept deadline has passed.
+// An order that has been re-dispatched fewer than the configured number of
+// times gets a new deadline and is offered to drivers again; otherwise it
+// expires and the customer is told no driver was found. Cancelling ctx rolls
+// the sweep back.
+func (s *OrderService) ProcessOverdueOrders(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	orders := repository.NewOrderRepository(tx)
+	overdue, err := orders.ListOverdueForUpdate(s.dispatch.ExpiryBatchSize)
+	if err != nil {
+		return internal("Failed to fetch overdue orders", err)
+	}
+	if len(overdue) == 0 {
+		return nil
+	}
+
+	var redispatched, expired []int64
+	deadline := time.Now().Add(s.dispatch.RedispatchWindow())
+	for i := range overdue {
+		order := &overdue[i]
+		if order.DispatchAttempts < s.dispatch.MaxRedispatches {
+			if err := orders.ExtendDeadline(order.ID, deadline); err != nil {
+				return internal("Failed to re-dispatch order", err)
+			}
+			err := recordOrderEvent(tx, order.ID, models.OrderEventRedispatched, nil, nil, orderChange{
+				Actor:   models.ActorSystem,
+				Details: map[string]interface{}{"attempt": order.DispatchAttempts + 1, "accept_deadline": deadline},
+			})
+			if err != nil {
+				return err
+			}
+			if err := offerToDrivers(tx, s.jobs, order.ID); err != nil {
+				return err
+			}
+			redispatched = append(redispatched, order.ID)
+			continue
+		}
+		change := orderChange{To: models.OrderStatusExpired, Actor: models.ActorSystem}
+		if err := transitionOrder(tx, order, change); err != nil {
+			return err
+		}
+		if err := settleFare(tx, order, change, 0); err != nil {
+			return err
+		}
+		expired = append(expired, order.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal("Failed to commit transaction", err)
+	}
+
+	log.Printf("Overdue orders: %d re-dispatched, %d expired", len(redispatched), len(expired))
+
+	if len(redispatched) > 0 {
+		s.jobs.Wake()
+	}
+
+	for _, id := range expired {
+		order, err := s.orders.Get(id)
+		if err != nil {
+			log.Printf("Failed to load expired order %d: %v", id, err)
+			continue
+		}
+		publishOrderStatus(s.drivers, s.events, order)
+		if err := pushNotification(s.notifications, s.events, order.UserID, "Order Expired", "No driver accepted your order. Please place a new order.", "order_expired", order.ID); err != nil {
+			log.Printf("Failed to notify user %d about expired order %d: %v", order.UserID, order.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Timeline returns the audit trail of an order, oldest first. Admins may see
+// any order; everyone else only the orders they placed.
+func (s *OrderService) Timeline(userID int64, role models.UserRole, orderID int64) ([]models.OrderEvent, error) {
+	var err error
+	if role == models.RoleAdmin || role == models.RoleSuperAdmin {
+		_, err = s.orders.Get(orderID)
+	} else {
+		_, err = s.orders.GetForUser(orderID, userID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	events, err := s.orderEvents.ListForOrder(orderID)
+	if err != nil {
+		return nil, internal("Failed to fetch order timeline", err)
+	}
+	return events, nil
+}
+
+func newOrder(userID int64, orderType models.OrderType, customerName, customerPhone string, route Route, schedule Schedule, notes string, price PriceBreakdown, acceptWindow time.Duration) *models.Order {
+	acceptDeadline := time.Now().Add(acceptWindow)
+
+	order := &models.Order{
+		UserID:             userID,
+		OrderType:          orderType,
+		Status:             models.OrderStatusPending,
+		CustomerName:       customerName,
+		CustomerPhone:      customerPhone,
+		FromRegionID:       route.FromRegionID,
+		FromDistrictID:     route.FromDistrictID,
+		FromLatitude:       route.FromLatitude,
+		FromLongitude:      route.FromLongitude,
+		FromAddress:        route.FromAddress,
+		ToRegionID:         route.ToRegionID,
+		ToDistrictID:       route.ToDistrictID,
+		ToLatitude:         route.ToLatitude,
+		ToLongitude:        route.ToLongitude,
+		ToAddress:          route.ToAddress,
+		ScheduledDate:      schedule.Date,
+		TimeRangeStart:     schedule.TimeRangeStart,
+		TimeRangeEnd:       schedule.TimeRangeEnd,
+		Price:              price.Price,
+		ServiceFee:         price.ServiceFee,
+		DiscountPercentage: price.DiscountPercentage,
+		FinalPrice:         price.FinalPrice,
+		AcceptDeadline:     &acceptDeadline,
+		PaymentMethod:      models.PaymentCash,
+		PaymentStatus:      models.PaymentUnpaid,
+	}
+	if notes != "" {
+		order.Notes = &notes
+	}
+	return order
+}
+
+// pickupTime returns when an order is due to be picked up: the start of its
+// time range on the scheduled date, in server local time
+func pickupTime(order *models.Order) time.Time {
+	y, m, d := order.ScheduledDate.Date()
+	pickup := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	if start, err := time.Parse("15:04", order.TimeRangeStart); err == nil {
+		pickup = pickup.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	}
+	return pickup
+}
+
+// publishStatusChange pushes the current state of an order to its customer
+// and its driver
+func (s *OrderService) publishStatusChange(orderID int64) {
+	order, err := s.orders.Get(orderID)
+	if err != nil {
+		log.Printf("Failed to load order %d for status push: %v", orderID, err)
+		return
+	}
+	publishOrderStatus(s.drivers, s.events, order)
+}
+
+// offerNewOrder queues the offer of a new order in the transaction storing it
+func (s *OrderService) offerNewOrder(tx *sql.Tx, order *models.Order) error {
+	return offerToDrivers(tx, s.jobs, order.ID)
+}
+
+// insertOrder stores a new order with its created event and charges a wallet
+// fare. then, when set, runs in the same transaction before the charge.
+func (s *OrderService) insertOrder(order *models.Order, price PriceBreakdown, then func(tx *sql.Tx, order *models.Order) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	if err := repository.NewOrderRepository(tx).Create(order); err != nil {
+		return internal("Failed to create order", err)
+	}
+
+	details := map[string]interface{}{
+		"final_price":          order.FinalPrice,
+		"service_fee":          order.ServiceFee,
+		"payment_method":       order.PaymentMethod,
+		"pricing_strategy":     price.Strategy,
+		"surcharge_percentage": price.SurchargePercentage,
+	}
+	if price.DistrictOverrideID != nil {
+		details["district_override_id"] = *price.DistrictOverrideID
+	}
+	if price.DistanceKm != nil {
+		details["distance_km"] = *price.DistanceKm
+	}
+	err = recordOrderEvent(tx, order.ID, models.OrderEventCreated, nil, &order.Status, orderChange{
+		Actor:   models.ActorCustomer,
+		ActorID: order.UserID,
+		Details: details,
+	})
+	if err != nil {
+		return err
+	}
+
+	if then != nil {
+		if err := then(tx, order); err != nil {
+			return err
+		}
+	}
+
+	if order.PaymentMethod == models.PaymentWallet {
+		if err := payFromWallet(tx, order); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal("Failed to commit transaction", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// orderTransitions is the order state machine: for each status, the statuses
+// it may move to and who may move it there. Anything not listed is rejected.
+var orderTransitions = map[models.OrderStatus]map[models.OrderStatus][]models.OrderActor{
+	models.OrderStatusPending: {
+		models.OrderStatusAccepted:  {models.ActorDriver, models.ActorCustomer}, // customer: booking seats on a driver's trip
+		models.OrderStatusCancelled: {models.ActorCustomer, models.ActorAdmin},
+		models.OrderStatusExpired:   {models.ActorSystem},
+	},
+	models.OrderStatusAccepted: {
+		models.OrderStatusPending:    {models.ActorDriver},
+		models.OrderStatusInProgress: {models.ActorDriver, models.ActorAdmin},
+		models.OrderStatusCancelled:  {models.ActorCustomer, models.ActorAdmin},
+	},
+	models.OrderStatusInProgress: {
+		models.OrderStatusCompleted: {models.ActorDriver, models.ActorAdmin},
+		models.OrderStatusCancelled: {models.ActorAdmin},
+	},
+}
+
+// CanTransitionOrder reports whether actor may move an order from one status to another
+func CanTransitionOrder(from, to models.OrderStatus, actor models.OrderActor) bool {
+	for _, allowed := range orderTransitions[from][to] {
+		if allowed == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// orderChange is a status change requested for an order
+type orderChange struct {
+	To       models.OrderStatus
+	Actor    models.OrderActor
+	ActorID  int64     // User making the change; 0 for the system
+	DriverID int64     // Driver taking the order when moving to accepted
+	Deadline time.Time // New accept deadline when moving back to pending
+	Reason   string
+	Details  map[string]interface{} // Stored with the order event
+}
+
+// transitionOrder validates a status change against the state machine, applies
+// it and records it in the order's audit trail. It must run in the transaction
+// that loaded the order; on success order.Status holds the new status.
+func transitionOrder(tx repository.Querier, order *models.Order, change orderChange) error {
+	if !CanTransitionOrder(order.Status, change.To, change.Actor) {
+		return invalid(fmt.Sprintf("Order cannot move from %s to %s", order.Status, change.To))
+	}
+
+	orders := repository.NewOrderRepository(tx)
+
+	var err error
+	switch change.To {
+	case models.OrderStatusPending:
+		err = orders.Release(order.ID, change.Deadline)
+	case models.OrderStatusAccepted:
+		err = orders.Assign(order.ID, change.DriverID)
+	case models.OrderStatusInProgress:
+		err = orders.Start(order.ID)
+	case models.OrderStatusCompleted:
+		err = orders.Complete(order.ID)
+	case models.OrderStatusCancelled:
+		err = orders.Cancel(order.ID, change.Reason)
+	case models.OrderStatusExpired:
+		err = orders.Expire(order.ID)
+	default:
+		return invalid(fmt.Sprintf("Unknown order status %s", change.To))
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		// The guarded update found the order in another status
+		return invalid("Order is no longer available")
+	}
+	if err != nil {
+		return internal("Failed to update order status", err)
+	}
+
+	from, to := order.Status, change.To
+	if err := recordOrderEvent(tx, order.ID, models.OrderEventStatusChanged, &from, &to, change); err != nil {
+		return err
+	}
+
+	// A cancelled or released order leaves its shared ride and frees its seats
+	if order.TripID != nil && (change.To == models.OrderStatusCancelled || change.To == models.OrderStatusPending) {
+		if err := leaveTrip(tx, order, change); err != nil {
+			return err
+		}
+	}
+
+	order.Status = change.To
+	switch change.To {
+	case models.OrderStatusAccepted:
+		order.DriverID = &change.DriverID
+	case models.OrderStatusPending:
+		order.DriverID = nil
+	}
+	return nil
+}
+
+// recordOrderEvent appends an entry to an order's audit trail. The actor,
+// reason and details come from change; from and to are nil for events that do
+// not involve a status.
+func recordOrderEvent(tx repository.Querier, orderID int64, eventType models.OrderEventType, from, to *models.OrderStatus, change orderChange) error {
+	event := &models.OrderEvent{
+		OrderID:    orderID,
+		Type:       eventType,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  change.Actor,
+	}
+	if change.ActorID != 0 {
+		event.ActorID = &change.ActorID
+	}
+	if change.Reason != "" {
+		event.Reason = &change.Reason
+	}
+	if change.Details != nil {
+		details, err := json.Marshal(change.Details)
+		if err != nil {
+			return internal("Failed to encode order event", err)
+		}
+		event.Details = details
+	}
+
+	if _, err := repository.NewOrderEventRepository(tx).Create(event); err != nil {
+		return internal("Failed to record order event", err)
+	}
+	return nil
+}
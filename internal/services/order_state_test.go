@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"taxi-service/internal/models"
+)
+
+func TestCanTransitionOrder(t *testing.T) {
+	tests := []struct {
+		from  models.OrderStatus
+		to    models.OrderStatus
+		actor models.OrderActor
+		want  bool
+	}{
+		{models.OrderStatusPending, models.OrderStatusAccepted, models.ActorDriver, true},
+		{models.OrderStatusPending, models.OrderStatusAccepted, models.ActorCustomer, true},
+		{models.OrderStatusPending, models.OrderStatusAccepted, models.ActorAdmin, false},
+		{models.OrderStatusPending, models.OrderStatusCancelled, models.ActorCustomer, true},
+		{models.OrderStatusPending, models.OrderStatusCancelled, models.ActorDriver, false},
+		{models.OrderStatusPending, models.OrderStatusExpired, models.ActorSystem, true},
+		{models.OrderStatusPending, models.OrderStatusExpired, models.ActorAdmin, false},
+		{models.OrderStatusPending, models.OrderStatusInProgress, models.ActorDriver, false},
+		{models.OrderStatusAccepted, models.OrderStatusPending, models.ActorDriver, true},
+		{models.OrderStatusAccepted, models.OrderStatusPending, models.ActorCustomer, false},
+		{models.OrderStatusAccepted, models.OrderStatusInProgress, models.ActorDriver, true},
+		{models.OrderStatusAccepted, models.OrderStatusInProgress, models.ActorAdmin, true},
+		{models.OrderStatusAccepted, models.OrderStatusCancelled, models.ActorCustomer, true},
+		{models.OrderStatusAccepted, models.OrderStatusCompleted, models.ActorDriver, false},
+		{models.OrderStatusInProgress, models.OrderStatusCompleted, models.ActorDriver, true},
+		{models.OrderStatusInProgress, models.OrderStatusCancelled, models.ActorAdmin, true},
+		{models.OrderStatusInProgress, models.OrderStatusCancelled, models.ActorCustomer, false},
+		{models.OrderStatusInProgress, models.OrderStatusPending, models.ActorDriver, false},
+		{models.OrderStatusCompleted, models.OrderStatusCancelled, models.ActorAdmin, false},
+		{models.OrderStatusCancelled, models.OrderStatusPending, models.ActorAdmin, false},
+		{models.OrderStatusExpired, models.OrderStatusPending, models.ActorSystem, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitionOrder(tt.from, tt.to, tt.actor); got != tt.want {
+			t.Errorf("CanTransitionOrder(%s, %s, %s) = %v, want %v", tt.from, tt.to, tt.actor, got, tt.want)
+		}
+	}
+}
+
+func TestOrderTransitionsEndInFinalStatuses(t *testing.T) {
+	for _, final := range []models.OrderStatus{models.OrderStatusCompleted, models.OrderStatusCancelled, models.OrderStatusExpired} {
+		if next := orderTransitions[final]; len(next) != 0 {
+			t.Errorf("%s is final but may move to %v", final, next)
+		}
+	}
+	for from, next := range orderTransitions {
+		for to, actors := range next {
+			if len(actors) == 0 {
+				t.Errorf("%s → %s is listed without anyone allowed to make it", from, to)
+			}
+		}
+	}
+}
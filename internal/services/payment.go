@@ -0,0 +1,280 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// PaymentService tracks how customers pay order fares: customer wallets,
+// disputes and the admin queue of orders whose fare is still outstanding
+type PaymentService struct {
+	db            *sql.DB
+	orders        *repository.OrderRepository
+	ledger        *repository.LedgerRepository
+	notifications *repository.NotificationRepository
+	events        Events
+}
+
+// NewPaymentService creates a new payment service
+func NewPaymentService(db *sql.DB, events Events) *PaymentService {
+	return &PaymentService{
+		db:            db,
+		orders:        repository.NewOrderRepository(db),
+		ledger:        repository.NewLedgerRepository(db),
+		notifications: repository.NewNotificationRepository(db),
+		events:        events,
+	}
+}
+
+// CustomerWallet is the balance a customer can pay fares from
+type CustomerWallet struct {
+	Balance float64 `json:"balance"`
+}
+
+// parsePaymentMethod checks the payment method of a new order; empty means cash
+func parsePaymentMethod(method string) (models.PaymentMethod, error) {
+	switch m := models.PaymentMethod(method); m {
+	case "":
+		return models.PaymentCash, nil
+	case models.PaymentCash, models.PaymentCard, models.PaymentWallet:
+		return m, nil
+	}
+	return "", invalid("Invalid payment method")
+}
+
+// setOrderPayment moves an order to a new payment status and records the
+// change in its audit trail. change.Reason, when set, becomes the payment note.
+func setOrderPayment(tx repository.Querier, order *models.Order, status models.PaymentStatus, change orderChange) error {
+	var note *string
+	if change.Reason != "" {
+		note = &change.Reason
+	}
+	if err := repository.NewOrderRepository(tx).SetPaymentStatus(order.ID, status, note); err != nil {
+		return internal("Failed to update payment status", err)
+	}
+
+	details := map[string]interface{}{
+		"from":   order.PaymentStatus,
+		"to":     status,
+		"method": order.PaymentMethod,
+		"amount": order.FinalPrice,
+	}
+	for k, v := range change.Details {
+		details[k] = v
+	}
+	change.Details = details
+	if err := recordOrderEvent(tx, order.ID, models.OrderEventPayment, nil, nil, change); err != nil {
+		return err
+	}
+	order.PaymentStatus = status
+	return nil
+}
+
+// payFromWallet charges the fare of a new wallet order to the customer's
+// wallet. It fails when the wallet does not cover the fare.
+func payFromWallet(tx repository.Querier, order *models.Order) error {
+	_, err := postToCustomerWallet(tx, walletPosting{
+		Kind:        models.LedgerFarePayment,
+		CustomerID:  order.UserID,
+		Amount:      -order.FinalPrice,
+		Counterpart: models.AccountFareReceipts,
+		OrderID:     &order.ID,
+		Description: "Fare paid from wallet",
+	})
+	if err != nil {
+		return err
+	}
+	return setOrderPayment(tx, order, models.PaymentPaid, orderChange{Actor: models.ActorCustomer, ActorID: order.UserID})
+}
+
+// refundFare credits amount of an order's fare back to the customer's wallet
+// and marks the payment refunded
+func refundFare(tx repository.Querier, order *models.Order, change orderChange, amount float64) error {
+	_, err := postToCustomerWallet(tx, walletPosting{
+		Kind:        models.LedgerFareRefund,
+		CustomerID:  order.UserID,
+		Amount:      amount,
+		Counterpart: models.AccountFareReceipts,
+		OrderID:     &order.ID,
+		Description: "Fare refund",
+	})
+	if err != nil {
+		return err
+	}
+	change.Details = map[string]interface{}{"refund": roundAmount(amount)}
+	return setOrderPayment(tx, order, models.PaymentRefunded, change)
+}
+
+// closedPayment decides what happens to the payment of an order that will not
+// be completed and owes fee: the status it moves to, empty to leave it as it
+// is, and how much of a fare paid up front goes back to the customer's wallet.
+// A paid fare covers the fee; an unpaid one (cash, or card not yet paid) leaves
+// the fee owed, or is void when there is none.
+func closedPayment(order *models.Order, fee float64) (models.PaymentStatus, float64) {
+	switch order.PaymentStatus {
+	case models.PaymentPaid:
+		refund := roundAmount(order.FinalPrice - fee)
+		if refund <= 0 {
+			return "", 0
+		}
+		return models.PaymentRefunded, refund
+	case models.PaymentUnpaid:
+		if roundAmount(fee) > 0 {
+			return models.PaymentOwed, 0
+		}
+		return models.PaymentVoid, 0
+	}
+	return "", 0
+}
+
+// settleFare closes the payment of an order that will not be completed, as
+// closedPayment decides, in the transaction that closes the order. An owed
+// fee is listed with the outstanding payments until an admin resolves it.
+func settleFare(tx repository.Querier, order *models.Order, closed orderChange, fee float64) error {
+	change := orderChange{Actor: closed.Actor, ActorID: closed.ActorID}
+	status, refund := closedPayment(order, fee)
+	switch status {
+	case models.PaymentRefunded:
+		return refundFare(tx, order, change, refund)
+	case models.PaymentOwed:
+		change.Details = map[string]interface{}{"amount": roundAmount(fee)}
+		return setOrderPayment(tx, order, status, change)
+	case models.PaymentVoid:
+		return setOrderPayment(tx, order, status, change)
+	}
+	return nil
+}
+
+// Wallet returns the customer's wallet balance
+func (s *PaymentService) Wallet(userID int64) (*CustomerWallet, error) {
+	account, err := s.ledger.CustomerAccount(userID)
+	if err != nil {
+		return nil, internal("Failed to open customer wallet", err)
+	}
+	balance, err := s.ledger.AccountBalance(account)
+	if err != nil {
+		return nil, internal("Failed to read wallet balance", err)
+	}
+	return &CustomerWallet{Balance: balance}, nil
+}
+
+// Dispute lets a customer contest the fare of a completed order; an admin
+// then resolves it
+func (s *PaymentService) Dispute(userID, orderID int64, reason string) (*models.Order, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(orderID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && order.UserID != userID) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if order.Status != models.OrderStatusCompleted {
+		return nil, invalid("Only completed orders can be disputed")
+	}
+	if order.PaymentStatus != models.PaymentPaid && order.PaymentStatus != models.PaymentUnpaid {
+		return nil, invalid(fmt.Sprintf("A %s payment cannot be disputed", order.PaymentStatus))
+	}
+
+	change := orderChange{Actor: models.ActorCustomer, ActorID: userID, Reason: reason}
+	if err := setOrderPayment(tx, order, models.PaymentDisputed, change); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	return s.reload(orderID)
+}
+
+// ListOutstanding returns completed orders whose fare is unpaid or disputed
+// and cancelled orders whose customer owes the cancellation fee, optionally
+// only those with the given payment method
+func (s *PaymentService) ListOutstanding(method string) ([]models.Order, error) {
+	if method != "" {
+		if _, err := parsePaymentMethod(method); err != nil {
+			return nil, err
+		}
+	}
+	orders, err := s.orders.List(repository.OrderFilter{PaymentMethod: method, OutstandingPayment: true})
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+	return orders, nil
+}
+
+// Resolve lets an admin settle an outstanding payment: record an unpaid or
+// disputed fare or an owed cancellation fee as paid, waive it, or refund a
+// disputed or paid fare that went through the platform to the customer's
+// wallet
+func (s *PaymentService) Resolve(adminID, orderID int64, status models.PaymentStatus, note string) (*models.Order, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if order.Status != models.OrderStatusCompleted && order.PaymentStatus != models.PaymentOwed {
+		return nil, invalid("Only payments of completed orders and owed cancellation fees can be resolved")
+	}
+
+	change := orderChange{Actor: models.ActorAdmin, ActorID: adminID, Reason: note}
+	switch {
+	case status == models.PaymentRefunded && (order.PaymentStatus == models.PaymentPaid || order.PaymentStatus == models.PaymentDisputed):
+		if order.PaidAt == nil || order.PaymentMethod == models.PaymentCash {
+			return nil, invalid("Only fares paid by card or wallet can be refunded")
+		}
+		err = refundFare(tx, order, change, order.FinalPrice)
+	case (status == models.PaymentPaid || status == models.PaymentWaived) &&
+		(order.PaymentStatus == models.PaymentUnpaid || order.PaymentStatus == models.PaymentDisputed):
+		err = setOrderPayment(tx, order, status, change)
+	case (status == models.PaymentPaid || status == models.PaymentWaived) && order.PaymentStatus == models.PaymentOwed:
+		if order.CancellationFee != nil {
+			change.Details = map[string]interface{}{"amount": *order.CancellationFee}
+		}
+		err = setOrderPayment(tx, order, status, change)
+	default:
+		return nil, invalid(fmt.Sprintf("Payment cannot move from %s to %s", order.PaymentStatus, status))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	updated, err := s.reload(orderID)
+	if err != nil {
+		return nil, err
+	}
+	message := fmt.Sprintf("The payment for order #%d is now %s.", updated.ID, updated.PaymentStatus)
+	if err := pushNotification(s.notifications, s.events, updated.UserID, "Payment Updated", message, "payment_updated", updated.ID); err != nil {
+		log.Printf("Failed to notify user %d about payment of order %d: %v", updated.UserID, updated.ID, err)
+	}
+	return updated, nil
+}
+
+func (s *PaymentService) reload(orderID int64) (*models.Order, error) {
+	order, err := s.orders.Get(orderID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return order, nil
+}
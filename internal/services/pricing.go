@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+const earthRadiusKm = 6371.0
+
+// PriceRequest describes a trip to price. Deliveries are priced like a
+// single-passenger taxi without discount.
+type PriceRequest struct {
+	OrderType      models.OrderType
+	Route          Route
+	PassengerCount int // Taxi only
+	Schedule       Schedule
+}
+
+// PriceBreakdown is the result of a price calculation
+type PriceBreakdown struct {
+	Strategy models.PricingStrategy `json:"strategy"`
+	// Rates the trip was priced with: the route's or a district override's
+	BasePrice           float64  `json:"base_price"`
+	PricePerPerson      float64  `json:"price_per_person"`
+	PricePerKm          float64  `json:"price_per_km"`
+	DistrictOverrideID  *int64   `json:"district_override_id,omitempty"` // District override whose rates were used
+	DistanceKm          *float64 `json:"distance_km,omitempty"`          // Straight-line trip distance, for the distance strategy
+	SurchargePercentage float64  `json:"surcharge_percentage"`           // Night and holiday surcharges included in Price
+	Price               float64  `json:"price"`
+	DiscountPercentage  float64  `json:"discount_percentage"`
+	DiscountAmount      float64  `json:"discount_amount"`
+	ServiceFee          float64  `json:"service_fee"`
+	FinalPrice          float64  `json:"final_price"`
+}
+
+// pricingRates are the rates a strategy prices a trip with: a route's, or a
+// district override's in its place
+type pricingRates struct {
+	BasePrice      float64
+	PricePerPerson float64
+	PricePerKm     float64
+}
+
+// tripPrice is a trip's price before surcharges, discounts and the service fee
+type tripPrice struct {
+	Amount     float64
+	DistanceKm *float64
+}
+
+// pricingStrategy prices a trip from the rates of its route
+type pricingStrategy interface {
+	price(rates pricingRates, trip PriceRequest) (tripPrice, error)
+}
+
+// pricingStrategies are the strategies admins can pick per route
+var pricingStrategies = map[models.PricingStrategy]pricingStrategy{
+	models.PricingFlat:     flatPricing{},
+	models.PricingDistance: distancePricing{},
+}
+
+// flatPricing charges the base price plus a price per passenger
+type flatPricing struct{}
+
+func (flatPricing) price(rates pricingRates, trip PriceRequest) (tripPrice, error) {
+	return tripPrice{Amount: rates.BasePrice + rates.PricePerPerson*float64(trip.PassengerCount)}, nil
+}
+
+// distancePricing adds a price per km of straight-line distance between the
+// pickup and drop-off coordinates to the flat price
+type distancePricing struct{}
+
+func (distancePricing) price(rates pricingRates, trip PriceRequest) (tripPrice, error) {
+	r := trip.Route
+	if r.FromLatitude == nil || r.FromLongitude == nil || r.ToLatitude == nil || r.ToLongitude == nil {
+		return tripPrice{}, invalid("Pickup and drop-off coordinates are required to price this route")
+	}
+	km := math.Round(haversineKm(*r.FromLatitude, *r.FromLongitude, *r.ToLatitude, *r.ToLongitude)*10) / 10
+
+	flat, _ := flatPricing{}.price(rates, trip)
+	return tripPrice{Amount: flat.Amount + rates.PricePerKm*km, DistanceKm: &km}, nil
+}
+
+// haversineKm returns the great-circle distance between two points
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// surchargePercent adds up the surcharges that apply to a trip: night
+// surcharges by the start of its time range, holiday surcharges by its date
+func surchargePercent(surcharges []models.PricingSurcharge, schedule Schedule) float64 {
+	start, hasStart := minuteOfDay(schedule.TimeRangeStart)
+	date := schedule.Date.Format("2006-01-02")
+
+	var total float64
+	for _, s := range surcharges {
+		switch s.Kind {
+		case models.SurchargeNight:
+			if !hasStart || s.StartTime == nil || s.EndTime == nil {
+				continue
+			}
+			from, _ := minuteOfDay(*s.StartTime)
+			to, _ := minuteOfDay(*s.EndTime)
+			if inTimeWindow(start, from, to) {
+				total += s.Percent
+			}
+		case models.SurchargeHoliday:
+			if s.HolidayDate != nil && s.HolidayDate.Format("2006-01-02") == date {
+				total += s.Percent
+			}
+		}
+	}
+	return total
+}
+
+// inTimeWindow reports whether minute falls in [from, to), wrapping past
+// midnight when from is after to
+func inTimeWindow(minute, from, to int) bool {
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// minuteOfDay parses an H:MM or HH:MM time into minutes after midnight
+func minuteOfDay(value string) (int, bool) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return 0, false
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 23 {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || len(minutes) != 2 || m < 0 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func formatMinuteOfDay(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// CalculatePrice prices a trip with its route's strategy: the rates of a
+// district override for the trip's districts replace the route's, matching
+// surcharges are added, then the passenger discount and service fee apply
+func (s *OrderService) CalculatePrice(req PriceRequest) (PriceBreakdown, error) {
+	switch req.OrderType {
+	case models.OrderTypeTaxi:
+		if req.PassengerCount < int(models.OnePassenger) || req.PassengerCount > int(models.FullCar) {
+			return PriceBreakdown{}, invalid("Passenger count must be between 1 and 4")
+		}
+	case models.OrderTypeDelivery:
+		req.PassengerCount = 1
+	default:
+		return PriceBreakdown{}, invalid("Invalid order type")
+	}
+	if req.Route.FromRegionID == req.Route.ToRegionID {
+		return PriceBreakdown{}, invalid("From and To regions must be different")
+	}
+
+	pricing, err := s.pricing.GetRoute(req.Route.FromRegionID, req.Route.ToRegionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return PriceBreakdown{}, invalid("pricing not configured for this route")
+	}
+	if err != nil {
+		return PriceBreakdown{}, internal("database error", err)
+	}
+
+	strategy, ok := pricingStrategies[pricing.Strategy]
+	if !ok {
+		return PriceBreakdown{}, internal("unknown pricing strategy", errors.New(string(pricing.Strategy)))
+	}
+
+	rates := pricingRates{BasePrice: pricing.BasePrice, PricePerPerson: pricing.PricePerPerson, PricePerKm: pricing.PricePerKm}
+	var overrideID *int64
+	override, err := s.pricing.GetDistrictOverride(req.Route.FromDistrictID, req.Route.ToDistrictID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return PriceBreakdown{}, internal("database error", err)
+	}
+	if err == nil {
+		rates = pricingRates{BasePrice: override.BasePrice, PricePerPerson: override.PricePerPerson, PricePerKm: override.PricePerKm}
+		overrideID = &override.ID
+	}
+
+	trip, err := strategy.price(rates, req)
+	if err != nil {
+		return PriceBreakdown{}, err
+	}
+
+	surcharges, err := s.pricing.ListSurcharges()
+	if err != nil {
+		return PriceBreakdown{}, internal("database error", err)
+	}
+	surcharge := surchargePercent(surcharges, req.Schedule)
+
+	var discount float64
+	if req.OrderType == models.OrderTypeTaxi {
+		discount, err = s.pricing.DiscountFor(req.PassengerCount)
+		if err != nil {
+			return PriceBreakdown{}, internal("database error", err)
+		}
+	}
+
+	price := ComputeTaxiPrice(trip.Amount*(1+surcharge/100), discount, pricing.ServiceFee)
+	price.Strategy = pricing.Strategy
+	price.BasePrice = rates.BasePrice
+	price.PricePerPerson = rates.PricePerPerson
+	price.PricePerKm = rates.PricePerKm
+	price.DistrictOverrideID = overrideID
+	price.DistanceKm = trip.DistanceKm
+	price.SurchargePercentage = surcharge
+	return price, nil
+}
+
+// ComputeTaxiPrice applies the passenger discount and service fee percentage
+// to a trip price
+func ComputeTaxiPrice(basePrice, discountPercentage, serviceFeePercentage float64) PriceBreakdown {
+	discountAmount := basePrice * (discountPercentage / 100)
+	priceAfterDiscount := basePrice - discountAmount
+
+	serviceFee := priceAfterDiscount * (serviceFeePercentage / 100)
+
+	return PriceBreakdown{
+		Price:              basePrice,
+		ServiceFee:         serviceFee,
+		DiscountPercentage: discountPercentage,
+		DiscountAmount:     discountAmount,
+		FinalPrice:         priceAfterDiscount + serviceFee,
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+func TestComputeTaxiPrice(t *testing.T) {
+	tests := []struct {
+		name                   string
+		base, discount, feePct float64
+		wantDiscount, wantFee  float64
+		wantFinal              float64
+	}{
+		{"no discount or fee", 100000, 0, 0, 0, 0, 100000},
+		{"fee only", 100000, 0, 10, 0, 10000, 110000},
+		{"fee on the discounted price", 100000, 20, 10, 20000, 8000, 88000},
+		{"full discount", 50000, 100, 10, 50000, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeTaxiPrice(tt.base, tt.discount, tt.feePct)
+			if got.Price != tt.base || got.DiscountPercentage != tt.discount {
+				t.Errorf("price %v discount %v%%, want %v and %v%%", got.Price, got.DiscountPercentage, tt.base, tt.discount)
+			}
+			if !closeTo(got.DiscountAmount, tt.wantDiscount) || !closeTo(got.ServiceFee, tt.wantFee) || !closeTo(got.FinalPrice, tt.wantFinal) {
+				t.Errorf("discount %v fee %v final %v, want %v, %v and %v",
+					got.DiscountAmount, got.ServiceFee, got.FinalPrice, tt.wantDiscount, tt.wantFee, tt.wantFinal)
+			}
+		})
+	}
+}
+
+func TestSurchargePercent(t *testing.T) {
+	night := func(from, to string, percent float64) models.PricingSurcharge {
+		return models.PricingSurcharge{Kind: models.SurchargeNight, StartTime: &from, EndTime: &to, Percent: percent}
+	}
+	holiday := func(date time.Time, percent float64) models.PricingSurcharge {
+		return models.PricingSurcharge{Kind: models.SurchargeHoliday, HolidayDate: &date, Percent: percent}
+	}
+	newYear := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	surcharges := []models.PricingSurcharge{
+		night("22:00", "06:00", 20),
+		holiday(newYear, 30),
+		{Kind: models.SurchargeNight, Percent: 50}, // no window, never applies
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     float64
+	}{
+		{"day trip", Schedule{Date: newYear.AddDate(0, 0, 1), TimeRangeStart: "12:00"}, 0},
+		{"night before midnight", Schedule{Date: newYear.AddDate(0, 0, 1), TimeRangeStart: "23:30"}, 20},
+		{"night after midnight", Schedule{Date: newYear.AddDate(0, 0, 1), TimeRangeStart: "05:59"}, 20},
+		{"window end is exclusive", Schedule{Date: newYear.AddDate(0, 0, 1), TimeRangeStart: "06:00"}, 0},
+		{"holiday", Schedule{Date: newYear, TimeRangeStart: "12:00"}, 30},
+		{"holiday night adds up", Schedule{Date: newYear, TimeRangeStart: "22:00"}, 50},
+		{"holiday in another zone matches by date", Schedule{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.FixedZone("UZT", 5*3600)), TimeRangeStart: "12:00"}, 30},
+		{"unparsable start skips night surcharges", Schedule{Date: newYear, TimeRangeStart: "late"}, 30},
+	}
+	for _, tt := range tests {
+		if got := surchargePercent(surcharges, tt.schedule); got != tt.want {
+			t.Errorf("%s: got %v%%, want %v%%", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInTimeWindow(t *testing.T) {
+	tests := []struct {
+		minute, from, to int
+		want             bool
+	}{
+		{600, 540, 720, true},
+		{540, 540, 720, true},
+		{720, 540, 720, false},
+		{539, 540, 720, false},
+		{1380, 1320, 360, true}, // 23:00 in 22:00–06:00
+		{0, 1320, 360, true},
+		{359, 1320, 360, true},
+		{360, 1320, 360, false},
+		{720, 1320, 360, false},
+		{600, 600, 600, false}, // an empty window matches nothing
+	}
+	for _, tt := range tests {
+		if got := inTimeWindow(tt.minute, tt.from, tt.to); got != tt.want {
+			t.Errorf("inTimeWindow(%d, %d, %d) = %v, want %v", tt.minute, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", 41.3111, 69.2797, 41.3111, 69.2797, 0},
+		{"Tashkent to Samarkand", 41.3111, 69.2797, 39.6542, 66.9597, 270},
+		{"one degree of latitude", 0, 0, 1, 0, 111.2},
+		{"across the antimeridian", 0, 179.5, 0, -179.5, 111.2},
+	}
+	for _, tt := range tests {
+		got := haversineKm(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.want) > 1 {
+			t.Errorf("%s: got %.1f km, want about %.1f km", tt.name, got, tt.want)
+		}
+		if back := haversineKm(tt.lat2, tt.lon2, tt.lat1, tt.lon1); !closeTo(back, got) {
+			t.Errorf("%s: %.3f km there but %.3f km back", tt.name, got, back)
+		}
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
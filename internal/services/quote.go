@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"taxi-service/internal/models"
+	"taxi-service/internal/utils"
+)
+
+// quoteAudience keeps quote tokens and login tokens from standing in for
+// each other when both are signed with the same secret
+const quoteAudience = "order-quote"
+
+// Quote is the price of a trip and a token that books the trip at that price
+// until it expires
+type Quote struct {
+	OrderType  models.OrderType `json:"order_type"`
+	Breakdown  PriceBreakdown   `json:"breakdown"`
+	QuoteToken string           `json:"quote_token"`
+	ExpiresAt  time.Time        `json:"expires_at"`
+}
+
+// quoteClaims are signed into a quote token
+type quoteClaims struct {
+	UserID int64          `json:"user_id"`
+	Trip   string         `json:"trip"`
+	Price  PriceBreakdown `json:"price"`
+	jwt.RegisteredClaims
+}
+
+// Quote prices a taxi or delivery trip the same way order creation does and
+// signs the price into a short-lived token for the user
+func (s *OrderService) Quote(userID int64, req PriceRequest) (*Quote, error) {
+	price, err := s.CalculatePrice(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.quotes.QuoteTTL())
+	token, err := utils.SignClaims(&quoteClaims{
+		UserID: userID,
+		Trip:   tripKey(req),
+		Price:  price,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{quoteAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}, s.quotes.QuoteSecret)
+	if err != nil {
+		return nil, internal("Failed to sign quote", err)
+	}
+
+	return &Quote{OrderType: req.OrderType, Breakdown: price, QuoteToken: token, ExpiresAt: expiresAt}, nil
+}
+
+// priceOrder prices a new order: at the quoted price when it comes with a
+// quote token, otherwise at the current price
+func (s *OrderService) priceOrder(userID int64, req PriceRequest, quoteToken string) (PriceBreakdown, error) {
+	if quoteToken == "" {
+		return s.CalculatePrice(req)
+	}
+
+	claims := &quoteClaims{}
+	err := utils.ParseClaims(quoteToken, s.quotes.QuoteSecret, claims, jwt.WithAudience(quoteAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return PriceBreakdown{}, invalid("Quote is invalid or has expired, please request a new one")
+	}
+	if claims.UserID != userID || claims.Trip != tripKey(req) {
+		return PriceBreakdown{}, invalid("Quote does not match this order")
+	}
+	return claims.Price, nil
+}
+
+// tripKey sums up everything a trip's price depends on, so a quote only
+// books the trip it was made for
+func tripKey(req PriceRequest) string {
+	r := req.Route
+	passengers := req.PassengerCount
+	if req.OrderType != models.OrderTypeTaxi {
+		passengers = 0
+	}
+	return fmt.Sprintf("%s|%d/%d-%d/%d|%s-%s|%d|%s %s",
+		req.OrderType,
+		r.FromRegionID, r.FromDistrictID, r.ToRegionID, r.ToDistrictID,
+		formatPoint(r.FromLatitude, r.FromLongitude), formatPoint(r.ToLatitude, r.ToLongitude),
+		passengers,
+		req.Schedule.Date.Format("2006-01-02"), strings.TrimSpace(req.Schedule.TimeRangeStart))
+}
+
+func formatPoint(lat, lon *float64) string {
+	if lat == nil || lon == nil {
+		return "?"
+	}
+	return fmt.Sprintf("%.6f,%.6f", *lat, *lon)
+}
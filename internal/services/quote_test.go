@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/utils"
+)
+
+const testQuoteSecret = "quote-secret"
+
+func testPriceRequest() PriceRequest {
+	fromLat, fromLon := 41.3111, 69.2797
+	return PriceRequest{
+		OrderType: models.OrderTypeTaxi,
+		Route: Route{
+			FromRegionID: 1, FromDistrictID: 11, FromLatitude: &fromLat, FromLongitude: &fromLon,
+			ToRegionID: 2, ToDistrictID: 21,
+		},
+		PassengerCount: 2,
+		Schedule:       Schedule{Date: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), TimeRangeStart: "09:00", TimeRangeEnd: "10:00"},
+	}
+}
+
+func TestTripKey(t *testing.T) {
+	base := testPriceRequest()
+	key := tripKey(base)
+
+	same := []struct {
+		name   string
+		change func(*PriceRequest)
+	}{
+		{"end of the time range", func(r *PriceRequest) { r.Schedule.TimeRangeEnd = "12:00" }},
+		{"addresses", func(r *PriceRequest) { address := "Chilonzor 5"; r.Route.FromAddress = &address }},
+		{"padding around the start", func(r *PriceRequest) { r.Schedule.TimeRangeStart = " 09:00 " }},
+	}
+	for _, tt := range same {
+		req := testPriceRequest()
+		tt.change(&req)
+		if got := tripKey(req); got != key {
+			t.Errorf("changing the %s changed the key from %q to %q", tt.name, key, got)
+		}
+	}
+
+	differ := []struct {
+		name   string
+		change func(*PriceRequest)
+	}{
+		{"order type", func(r *PriceRequest) { r.OrderType = models.OrderTypeDelivery }},
+		{"pickup region", func(r *PriceRequest) { r.Route.FromRegionID = 3 }},
+		{"drop-off district", func(r *PriceRequest) { r.Route.ToDistrictID = 22 }},
+		{"pickup point", func(r *PriceRequest) { lat := 41.32; r.Route.FromLatitude = &lat }},
+		{"unknown pickup point", func(r *PriceRequest) { r.Route.FromLatitude = nil }},
+		{"passenger count", func(r *PriceRequest) { r.PassengerCount = 3 }},
+		{"date", func(r *PriceRequest) { r.Schedule.Date = r.Schedule.Date.AddDate(0, 0, 1) }},
+		{"start of the time range", func(r *PriceRequest) { r.Schedule.TimeRangeStart = "23:00" }},
+	}
+	for _, tt := range differ {
+		req := testPriceRequest()
+		tt.change(&req)
+		if got := tripKey(req); got == key {
+			t.Errorf("changing the %s left the key at %q", tt.name, key)
+		}
+	}
+
+	// Deliveries are not priced by passengers
+	a, b := testPriceRequest(), testPriceRequest()
+	a.OrderType, b.OrderType = models.OrderTypeDelivery, models.OrderTypeDelivery
+	a.PassengerCount, b.PassengerCount = 1, 4
+	if tripKey(a) != tripKey(b) {
+		t.Errorf("delivery keys differ by passenger count: %q and %q", tripKey(a), tripKey(b))
+	}
+}
+
+// signQuote signs a quote token the way Quote does
+func signQuote(t *testing.T, userID int64, req PriceRequest, price PriceBreakdown, audience string, expiresAt time.Time, secret string) string {
+	t.Helper()
+	token, err := utils.SignClaims(&quoteClaims{
+		UserID: userID,
+		Trip:   tripKey(req),
+		Price:  price,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}, secret)
+	if err != nil {
+		t.Fatalf("sign quote: %v", err)
+	}
+	return token
+}
+
+func TestPriceOrderVerifiesQuote(t *testing.T) {
+	s := &OrderService{quotes: &config.PricingConfig{QuoteTTLMinutes: 10, QuoteSecret: testQuoteSecret}}
+	req := testPriceRequest()
+	quoted := ComputeTaxiPrice(120000, 10, 5)
+	later := time.Now().Add(10 * time.Minute)
+
+	price, err := s.priceOrder(7, req, signQuote(t, 7, req, quoted, quoteAudience, later, testQuoteSecret))
+	if err != nil {
+		t.Fatalf("priceOrder with a valid quote: %v", err)
+	}
+	if price != quoted {
+		t.Errorf("booked at %+v, want the quoted %+v", price, quoted)
+	}
+
+	otherTrip := testPriceRequest()
+	otherTrip.PassengerCount = 4
+	loginToken, err := utils.GenerateToken(7, models.RoleUser, testQuoteSecret, 1)
+	if err != nil {
+		t.Fatalf("generate login token: %v", err)
+	}
+
+	rejected := []struct {
+		name  string
+		token string
+	}{
+		{"another user's quote", signQuote(t, 8, req, quoted, quoteAudience, later, testQuoteSecret)},
+		{"a quote for another trip", signQuote(t, 7, otherTrip, quoted, quoteAudience, later, testQuoteSecret)},
+		{"an expired quote", signQuote(t, 7, req, quoted, quoteAudience, time.Now().Add(-time.Minute), testQuoteSecret)},
+		{"a quote signed with another secret", signQuote(t, 7, req, quoted, quoteAudience, later, "other-secret")},
+		{"a token for another audience", signQuote(t, 7, req, quoted, "login", later, testQuoteSecret)},
+		{"a login token", loginToken},
+		{"garbage", "not-a-token"},
+	}
+	for _, tt := range rejected {
+		if _, err := s.priceOrder(7, req, tt.token); KindOf(err) != KindInvalid {
+			t.Errorf("%s: error = %v, want it rejected as invalid", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// RatingService handles driver ratings
+type RatingService struct {
+	db      *sql.DB
+	orders  *repository.OrderRepository
+	ratings *repository.RatingRepository
+}
+
+// NewRatingService creates a new rating service
+func NewRatingService(db *sql.DB) *RatingService {
+	return &RatingService{
+		db:      db,
+		orders:  repository.NewOrderRepository(db),
+		ratings: repository.NewRatingRepository(db),
+	}
+}
+
+// CreateRatingInput holds a customer's rating of a completed order
+type CreateRatingInput struct {
+	OrderID int64
+	Rating  int
+	Comment string
+}
+
+// CreateRating rates the driver of a completed order and refreshes the driver's average
+func (s *RatingService) CreateRating(userID int64, in CreateRatingInput) (*models.Rating, error) {
+	if in.Rating < 1 || in.Rating > 5 {
+		return nil, invalid("Rating must be between 1 and 5")
+	}
+
+	// Verify order belongs to user and is completed
+	order, err := s.orders.GetForUser(in.OrderID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	if order.Status != models.OrderStatusCompleted {
+		return nil, invalid("Can only rate completed orders")
+	}
+	if order.DriverID == nil {
+		return nil, invalid("Order has no driver assigned")
+	}
+
+	rated, err := s.ratings.ExistsForOrder(in.OrderID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if rated {
+		return nil, conflict("Order already rated")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	var comment *string
+	if in.Comment != "" {
+		comment = &in.Comment
+	}
+
+	rating, err := repository.NewRatingRepository(tx).Create(&models.Rating{
+		OrderID:  in.OrderID,
+		UserID:   userID,
+		DriverID: *order.DriverID,
+		Rating:   in.Rating,
+		Comment:  comment,
+	})
+	if err != nil {
+		return nil, internal("Failed to create rating", err)
+	}
+
+	if err := repository.NewDriverRepository(tx).RefreshRating(*order.DriverID); err != nil {
+		return nil, internal("Failed to update driver rating", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+	return rating, nil
+}
+
+// ListDriverRatings returns all ratings of a driver, newest first
+func (s *RatingService) ListDriverRatings(driverID int64) ([]models.Rating, error) {
+	ratings, err := s.ratings.ListForDriver(driverID)
+	if err != nil {
+		return nil, internal("Failed to fetch ratings", err)
+	}
+	return ratings, nil
+}
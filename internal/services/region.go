@@ -0,0 +1,151 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// RegionService manages regions and their districts
+type RegionService struct {
+	regions *repository.RegionRepository
+}
+
+// NewRegionService creates a new region service
+func NewRegionService(db *sql.DB) *RegionService {
+	return &RegionService{regions: repository.NewRegionRepository(db)}
+}
+
+// RegionNames holds the localized names of a region or district;
+// on update, empty names are left unchanged
+type RegionNames struct {
+	NameUzLat string
+	NameUzCyr string
+	NameRu    string
+}
+
+func (n RegionNames) empty() bool {
+	return n.NameUzLat == "" && n.NameUzCyr == "" && n.NameRu == ""
+}
+
+func (n RegionNames) toRepository() repository.Names {
+	return repository.Names{UzLat: n.NameUzLat, UzCyr: n.NameUzCyr, Ru: n.NameRu}
+}
+
+// ListRegions returns all regions ordered by name
+func (s *RegionService) ListRegions() ([]models.Region, error) {
+	regions, err := s.regions.ListRegions()
+	if err != nil {
+		return nil, internal("Failed to fetch regions", err)
+	}
+	return regions, nil
+}
+
+// GetRegion returns a single region
+func (s *RegionService) GetRegion(regionID int64) (*models.Region, error) {
+	region, err := s.regions.GetRegion(regionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Region not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return region, nil
+}
+
+// CreateRegion adds a new region
+func (s *RegionService) CreateRegion(names RegionNames) (*models.Region, error) {
+	region, err := s.regions.CreateRegion(names.toRepository())
+	if err != nil {
+		return nil, internal("Failed to create region", err)
+	}
+	return region, nil
+}
+
+// UpdateRegion renames a region
+func (s *RegionService) UpdateRegion(regionID int64, names RegionNames) (*models.Region, error) {
+	if names.empty() {
+		return nil, invalid("No fields to update")
+	}
+
+	region, err := s.regions.UpdateRegion(regionID, names.toRepository())
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Region not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to update region", err)
+	}
+	return region, nil
+}
+
+// DeleteRegion removes a region
+func (s *RegionService) DeleteRegion(regionID int64) error {
+	err := s.regions.DeleteRegion(regionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Region not found")
+	}
+	if err != nil {
+		return internal("Failed to delete region", err)
+	}
+	return nil
+}
+
+// ListDistricts returns the districts of a region ordered by name
+func (s *RegionService) ListDistricts(regionID int64) ([]models.District, error) {
+	districts, err := s.regions.ListDistricts(regionID)
+	if err != nil {
+		return nil, internal("Failed to fetch districts", err)
+	}
+	return districts, nil
+}
+
+// GetDistrict returns a single district
+func (s *RegionService) GetDistrict(districtID int64) (*models.District, error) {
+	district, err := s.regions.GetDistrict(districtID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("District not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return district, nil
+}
+
+// CreateDistrict adds a new district to a region
+func (s *RegionService) CreateDistrict(regionID int64, names RegionNames) (*models.District, error) {
+	district, err := s.regions.CreateDistrict(regionID, names.toRepository())
+	if err != nil {
+		return nil, internal("Failed to create district", err)
+	}
+	return district, nil
+}
+
+// UpdateDistrict renames a district
+func (s *RegionService) UpdateDistrict(districtID int64, names RegionNames) (*models.District, error) {
+	if names.empty() {
+		return nil, invalid("No fields to update")
+	}
+
+	district, err := s.regions.UpdateDistrict(districtID, names.toRepository())
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("District not found")
+	}
+	if err != nil {
+		return nil, internal("Failed to update district", err)
+	}
+	return district, nil
+}
+
+// DeleteDistrict removes a district
+func (s *RegionService) DeleteDistrict(districtID int64) error {
+	err := s.regions.DeleteDistrict(districtID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("District not found")
+	}
+	if err != nil {
+		return internal("Failed to delete district", err)
+	}
+	return nil
+}
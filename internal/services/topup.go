@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/payments"
+	"taxi-service/internal/repository"
+)
+
+const topUpExpiryBatchSize = 100
+
+// TopUpService lets drivers pay into their wallet and customers pay order
+// fares by card through a payment provider, and applies the providers'
+// callbacks
+type TopUpService struct {
+	db            *sql.DB
+	cfg           *config.PaymentsConfig
+	providers     *payments.Registry
+	topUps        *repository.TopUpRepository
+	drivers       *repository.DriverRepository
+	orders        *repository.OrderRepository
+	notifications *repository.NotificationRepository
+	events        Events
+}
+
+// NewTopUpService creates a new top-up service
+func NewTopUpService(db *sql.DB, cfg *config.PaymentsConfig, providers *payments.Registry, events Events) *TopUpService {
+	return &TopUpService{
+		db:            db,
+		cfg:           cfg,
+		providers:     providers,
+		topUps:        repository.NewTopUpRepository(db),
+		drivers:       repository.NewDriverRepository(db),
+		orders:        repository.NewOrderRepository(db),
+		notifications: repository.NewNotificationRepository(db),
+		events:        events,
+	}
+}
+
+// StartedTopUp is a new top-up with the link where the payer pays for it
+type StartedTopUp struct {
+	TopUp       *models.TopUp `json:"top_up"`
+	CheckoutURL string        `json:"checkout_url,omitempty"`
+}
+
+// Providers lists the payment providers drivers can top up through
+func (s *TopUpService) Providers() []string {
+	return s.providers.Names()
+}
+
+// Start creates a pending top-up for the driver and asks the provider for
+// its checkout link
+func (s *TopUpService) Start(userID int64, providerName string, amount float64) (*StartedTopUp, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, invalid("Unknown payment provider")
+	}
+	amount = roundAmount(amount)
+	if amount < s.cfg.MinTopUp || amount > s.cfg.MaxTopUp {
+		return nil, invalid(fmt.Sprintf("Amount must be between %.0f and %.0f", s.cfg.MinTopUp, s.cfg.MaxTopUp))
+	}
+
+	driver, err := s.driverForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topUp, err := s.topUps.Create(driver.ID, provider.Name(), amount)
+	if err != nil {
+		return nil, internal("Failed to create top-up", err)
+	}
+	checkoutURL, err := provider.CheckoutURL(topUp)
+	if err != nil {
+		return nil, internal("Failed to create checkout link", err)
+	}
+	return &StartedTopUp{TopUp: topUp, CheckoutURL: checkoutURL}, nil
+}
+
+// PayOrder starts a card payment of the fare of one of the customer's card
+// orders. The order is marked paid once the provider confirms it.
+func (s *TopUpService) PayOrder(userID, orderID int64, providerName string) (*StartedTopUp, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, invalid("Unknown payment provider")
+	}
+
+	order, err := s.orders.GetForUser(orderID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if order.PaymentMethod != models.PaymentCard {
+		return nil, invalid("Order is not paid by card")
+	}
+	if !orderPayable(order) {
+		return nil, invalid("Order is not awaiting payment")
+	}
+
+	topUp, err := s.topUps.CreateForOrder(order.ID, provider.Name(), order.FinalPrice)
+	if err != nil {
+		return nil, internal("Failed to create payment", err)
+	}
+	checkoutURL, err := provider.CheckoutURL(topUp)
+	if err != nil {
+		return nil, internal("Failed to create checkout link", err)
+	}
+	return &StartedTopUp{TopUp: topUp, CheckoutURL: checkoutURL}, nil
+}
+
+// orderPayable reports whether an order's fare can still be paid by card
+func orderPayable(order *models.Order) bool {
+	switch order.Status {
+	case models.OrderStatusCancelled, models.OrderStatusExpired:
+		return false
+	}
+	return order.PaymentStatus == models.PaymentUnpaid
+}
+
+// List returns the driver's top-ups, newest first
+func (s *TopUpService) List(userID int64) ([]models.TopUp, error) {
+	driver, err := s.driverForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	topUps, err := s.topUps.ListForDriver(driver.ID)
+	if err != nil {
+		return nil, internal("Failed to fetch top-ups", err)
+	}
+	return topUps, nil
+}
+
+// Get returns one of the driver's top-ups
+func (s *TopUpService) Get(userID, topUpID int64) (*models.TopUp, error) {
+	driver, err := s.driverForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	topUp, err := s.topUps.Get(topUpID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (topUp.DriverID == nil || *topUp.DriverID != driver.ID)) {
+		return nil, notFound("Top-up not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return topUp, nil
+}
+
+// HandleCallback passes a provider callback to its adapter
+func (s *TopUpService) HandleCallback(providerName string, req payments.Request) (payments.Response, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return payments.Response{}, notFound("Unknown payment provider")
+	}
+	return provider.HandleCallback(req, &topUpBook{service: s, provider: provider.Name()}), nil
+}
+
+// ExpireStale fails top-ups that stayed unpaid longer than the configured
+// TTL. A provider paying one later is refused, so the driver starts a new one.
+// Cancelling ctx rolls the sweep back.
+func (s *TopUpService) ExpireStale(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	topUps := repository.NewTopUpRepository(tx)
+	stale, err := topUps.ListStaleForUpdate(time.Now().Add(-s.cfg.PendingTTL()), topUpExpiryBatchSize)
+	if err != nil {
+		return internal("Failed to fetch stale top-ups", err)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	for _, topUp := range stale {
+		if err := topUps.Fail(topUp.ID, "expired"); err != nil {
+			return internal("Failed to expire top-up", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal("Failed to commit transaction", err)
+	}
+
+	log.Printf("Stale top-ups: %d expired", len(stale))
+	return nil
+}
+
+// confirm credits a pending top-up to the driver's wallet, or records the
+// order it pays as paid. The credit and the status change commit together
+// under the top-up's row lock, so a callback delivered twice credits once.
+func (s *TopUpService) confirm(provider string, id int64) (*models.TopUp, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	topUps := repository.NewTopUpRepository(tx)
+	topUp, err := topUps.GetForUpdate(id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && topUp.Provider != provider) {
+		return nil, payments.ErrTopUpNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch topUp.Status {
+	case models.TopUpConfirmed:
+		return topUp, nil
+	case models.TopUpFailed:
+		return nil, payments.ErrTopUpFailed
+	}
+
+	if topUp.OrderID != nil {
+		return s.confirmOrderPayment(tx, topUp)
+	}
+
+	posted, err := postToWallet(tx, walletPosting{
+		Kind:        models.LedgerTopUp,
+		DriverID:    *topUp.DriverID,
+		Amount:      topUp.Amount,
+		Counterpart: models.AccountDriverFunding,
+		Description: "Top-up via " + provider,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := topUps.Confirm(topUp.ID, posted.ID); err != nil {
+		return nil, err
+	}
+	if topUp, err = topUps.Get(topUp.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.notifyConfirmed(topUp)
+	return topUp, nil
+}
+
+// confirmOrderPayment records a card payment of an order's fare. A payment
+// that arrives after the order was closed or settled otherwise is failed, so
+// the provider reverses it.
+func (s *TopUpService) confirmOrderPayment(tx *sql.Tx, topUp *models.TopUp) (*models.TopUp, error) {
+	topUps := repository.NewTopUpRepository(tx)
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(*topUp.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !orderPayable(order) {
+		if err := topUps.Fail(topUp.ID, "order_not_payable"); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, payments.ErrTopUpFailed
+	}
+
+	ledger := repository.NewLedgerRepository(tx)
+	receipts, err := ledger.PlatformAccount(models.AccountFareReceipts)
+	if err != nil {
+		return nil, err
+	}
+	posted, err := writePosting(ledger, receipts, roundAmount(topUp.Amount), walletPosting{
+		Kind:        models.LedgerFarePayment,
+		Counterpart: models.AccountCustomerFunding,
+		OrderID:     &order.ID,
+		Description: "Fare paid via " + topUp.Provider,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = setOrderPayment(tx, order, models.PaymentPaid, orderChange{
+		Actor:   models.ActorCustomer,
+		ActorID: order.UserID,
+		Details: map[string]interface{}{"top_up_id": topUp.ID, "provider": topUp.Provider},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := topUps.Confirm(topUp.ID, posted.ID); err != nil {
+		return nil, err
+	}
+	if topUp, err = topUps.Get(topUp.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Your payment of %.0f for order #%d has been received.", topUp.Amount, order.ID)
+	if err := pushNotification(s.notifications, s.events, order.UserID, "Payment Received", message, "order_paid", order.ID); err != nil {
+		log.Printf("Failed to notify user %d about payment of order %d: %v", order.UserID, order.ID, err)
+	}
+	return topUp, nil
+}
+
+func (s *TopUpService) notifyConfirmed(topUp *models.TopUp) {
+	driver, err := s.drivers.Get(*topUp.DriverID)
+	if err != nil {
+		log.Printf("Failed to load driver %d for top-up %d: %v", *topUp.DriverID, topUp.ID, err)
+		return
+	}
+	message := fmt.Sprintf("%.0f has been added to your balance.", topUp.Amount)
+	if err := pushNotification(s.notifications, s.events, driver.UserID, "Balance Topped Up", message, "balance_topped_up", topUp.ID); err != nil {
+		log.Printf("Failed to notify user %d about top-up %d: %v", driver.UserID, topUp.ID, err)
+	}
+}
+
+// fail marks a pending top-up failed; failing it again is a no-op
+func (s *TopUpService) fail(provider string, id int64, reason string) (*models.TopUp, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	topUps := repository.NewTopUpRepository(tx)
+	topUp, err := topUps.GetForUpdate(id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && topUp.Provider != provider) {
+		return nil, payments.ErrTopUpNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch topUp.Status {
+	case models.TopUpFailed:
+		return topUp, nil
+	case models.TopUpConfirmed:
+		return nil, payments.ErrTopUpConfirmed
+	}
+
+	if err := topUps.Fail(topUp.ID, reason); err != nil {
+		return nil, err
+	}
+	if topUp, err = topUps.Get(topUp.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return topUp, nil
+}
+
+func (s *TopUpService) driverForUser(userID int64) (*models.Driver, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return driver, nil
+}
+
+// topUpBook is the payments.Book of one provider
+type topUpBook struct {
+	service  *TopUpService
+	provider string
+}
+
+func (b *topUpBook) TopUp(id int64) (*models.TopUp, error) {
+	topUp, err := b.service.topUps.Get(id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && topUp.Provider != b.provider) {
+		return nil, payments.ErrTopUpNotFound
+	}
+	return topUp, err
+}
+
+func (b *topUpBook) TopUpByRef(ref string) (*models.TopUp, error) {
+	topUp, err := b.service.topUps.GetByRef(b.provider, ref)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, payments.ErrTopUpNotFound
+	}
+	return topUp, err
+}
+
+func (b *topUpBook) Attach(id int64, ref string) (*models.TopUp, error) {
+	if _, err := b.TopUp(id); err != nil {
+		return nil, err
+	}
+	err := b.service.topUps.Attach(id, ref)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	topUp, lookupErr := b.service.topUps.Get(id)
+	if lookupErr != nil || err == nil {
+		return topUp, lookupErr
+	}
+
+	// Nothing was updated: the top-up is settled or paid by another transaction
+	switch {
+	case topUp.ProviderRef != nil && *topUp.ProviderRef == ref:
+		return topUp, nil
+	case topUp.Status == models.TopUpConfirmed:
+		return nil, payments.ErrTopUpConfirmed
+	case topUp.Status == models.TopUpFailed:
+		return nil, payments.ErrTopUpFailed
+	}
+	return nil, payments.ErrTopUpBusy
+}
+
+func (b *topUpBook) Confirm(id int64) (*models.TopUp, error) {
+	topUp, err := b.service.confirm(b.provider, id)
+	return topUp, b.logged("confirm", id, err)
+}
+
+func (b *topUpBook) Fail(id int64, reason string) (*models.TopUp, error) {
+	topUp, err := b.service.fail(b.provider, id, reason)
+	return topUp, b.logged("fail", id, err)
+}
+
+// logged logs unexpected errors; providers only see a generic failure
+func (b *topUpBook) logged(action string, id int64, err error) error {
+	switch {
+	case err == nil,
+		errors.Is(err, payments.ErrTopUpNotFound),
+		errors.Is(err, payments.ErrTopUpFailed),
+		errors.Is(err, payments.ErrTopUpConfirmed):
+		return err
+	}
+	log.Printf("Failed to %s %s top-up %d: %v", action, b.provider, id, err)
+	return err
+}
+
+func (b *topUpBook) List(from, to time.Time) ([]models.TopUp, error) {
+	return b.service.topUps.ListAttached(b.provider, from, to)
+}
@@ -0,0 +1,679 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+)
+
+// maxTripSeats caps the seats a driver can offer on one trip
+const maxTripSeats = 8
+
+// TripService lets drivers publish intercity trips and pool pending orders
+// into them seat by seat. Each order on a trip keeps its own fare, payment and
+// lifecycle; cancelling or releasing one frees its seats for another.
+type TripService struct {
+	db            *sql.DB
+	trips         *repository.TripRepository
+	orders        *repository.OrderRepository
+	drivers       *repository.DriverRepository
+	notifications *repository.NotificationRepository
+	events        Events
+	jobs          Jobs
+	dispatch      *config.DispatchConfig
+}
+
+// NewTripService creates a new trip service
+func NewTripService(db *sql.DB, dispatch *config.DispatchConfig, events Events, jobs Jobs) *TripService {
+	return &TripService{
+		db:            db,
+		dispatch:      dispatch,
+		trips:         repository.NewTripRepository(db),
+		orders:        repository.NewOrderRepository(db),
+		drivers:       repository.NewDriverRepository(db),
+		notifications: repository.NewNotificationRepository(db),
+		events:        events,
+		jobs:          jobs,
+	}
+}
+
+// PublishTripInput holds a trip a driver offers
+type PublishTripInput struct {
+	FromRegionID   int64
+	ToRegionID     int64
+	Date           time.Time
+	DepartureStart string // HH:MM
+	DepartureEnd   string // HH:MM
+	Seats          int
+	PricePerSeat   float64
+	Notes          string
+}
+
+// TripDetails is a trip with the orders booked on it
+type TripDetails struct {
+	models.Trip
+	SeatsLeft int            `json:"seats_left"`
+	Orders    []models.Order `json:"orders"`
+}
+
+// TripSearch narrows down the open trips customers can book
+type TripSearch struct {
+	FromRegionID int64
+	ToRegionID   int64
+	Date         time.Time
+	Seats        int // Seats needed; 0 means 1
+}
+
+// TripOffer is an open trip as customers see it
+type TripOffer struct {
+	models.Trip
+	SeatsLeft int        `json:"seats_left"`
+	Driver    TripDriver `json:"driver"`
+}
+
+// TripDriver is what customers see of a trip's driver before booking
+type TripDriver struct {
+	FullName     string  `json:"full_name"`
+	CarModel     string  `json:"car_model"`
+	Rating       float64 `json:"rating"`
+	TotalRatings int     `json:"total_ratings"`
+}
+
+// BookTripInput holds a customer's booking of seats on a trip. The route's
+// regions and the schedule come from the trip.
+type BookTripInput struct {
+	UserID        int64
+	TripID        int64
+	CustomerName  string
+	CustomerPhone string
+	Route         Route // Districts, coordinates and addresses of pickup and drop-off
+	Seats         int
+	Notes         string
+	PaymentMethod string // cash, card or wallet; empty means cash
+}
+
+// TripCancellation is the outcome of a driver cancelling a trip: every order
+// still on it was released on its own terms
+type TripCancellation struct {
+	Trip     *models.Trip `json:"trip"`
+	Released []int64      `json:"released_order_ids"`
+	Refund   float64      `json:"refund"`  // Service fees returned to the driver
+	Penalty  float64      `json:"penalty"` // Service fees kept
+}
+
+// Publish creates an open trip for the driver
+func (s *TripService) Publish(userID int64, in PublishTripInput) (*models.Trip, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if !driver.IsActive {
+		return nil, forbidden("Driver account is not active")
+	}
+
+	if in.FromRegionID == in.ToRegionID {
+		return nil, invalid("From and To regions must be different")
+	}
+	if in.Seats < 1 || in.Seats > maxTripSeats {
+		return nil, invalid("Seats must be between 1 and 8")
+	}
+	if in.Seats > driver.SeatCapacity {
+		return nil, invalid(fmt.Sprintf("Seats cannot exceed your car's seat capacity of %d", driver.SeatCapacity))
+	}
+	if in.PricePerSeat <= 0 {
+		return nil, invalid("Price per seat must be positive")
+	}
+	start, okStart := minuteOfDay(in.DepartureStart)
+	end, okEnd := minuteOfDay(in.DepartureEnd)
+	if !okStart || !okEnd {
+		return nil, invalid("Departure times must be HH:MM")
+	}
+	if end < start {
+		return nil, invalid("Departure window must end after it starts")
+	}
+	if dateOf(in.Date).Before(today()) {
+		return nil, invalid("Trip date is in the past")
+	}
+
+	trip := &models.Trip{
+		DriverID:       driver.ID,
+		FromRegionID:   in.FromRegionID,
+		ToRegionID:     in.ToRegionID,
+		ScheduledDate:  in.Date,
+		DepartureStart: formatMinuteOfDay(start),
+		DepartureEnd:   formatMinuteOfDay(end),
+		SeatsTotal:     in.Seats,
+		PricePerSeat:   in.PricePerSeat,
+	}
+	if notes := strings.TrimSpace(in.Notes); notes != "" {
+		trip.Notes = &notes
+	}
+	if err := s.trips.Create(trip); err != nil {
+		return nil, internal("Failed to publish trip", err)
+	}
+	return trip, nil
+}
+
+// List returns the driver's trips, optionally only those with a status
+func (s *TripService) List(userID int64, status string) ([]models.Trip, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	trips, err := s.trips.ListForDriver(driverID, status)
+	if err != nil {
+		return nil, internal("Failed to fetch trips", err)
+	}
+	return trips, nil
+}
+
+// Get returns one of the driver's trips with the orders booked on it
+func (s *TripService) Get(userID, tripID int64) (*TripDetails, error) {
+	trip, err := s.driverTrip(userID, tripID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.orders.List(repository.OrderFilter{TripID: trip.ID})
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+	return &TripDetails{Trip: *trip, SeatsLeft: trip.SeatsTotal - trip.SeatsBooked, Orders: orders}, nil
+}
+
+// Candidates returns the pending orders that could join one of the driver's
+// open trips: same region pair and date, pickup time in the departure window
+// and no more passengers than seats left
+func (s *TripService) Candidates(userID, tripID int64) ([]models.Order, error) {
+	trip, err := s.driverTrip(userID, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status != models.TripOpen {
+		return []models.Order{}, nil
+	}
+
+	orders, err := s.orders.ListTripCandidates(trip, trip.SeatsTotal-trip.SeatsBooked)
+	if err != nil {
+		return nil, internal("Failed to fetch orders", err)
+	}
+	candidates := []models.Order{}
+	for _, order := range orders {
+		if inDepartureWindow(trip, &order) {
+			candidates = append(candidates, order)
+		}
+	}
+	return candidates, nil
+}
+
+// Attach accepts a pending order onto one of the driver's open trips. The
+// driver pays the order's service fee as with any accepted order, and the
+// order's passengers take seats on the trip.
+func (s *TripService) Attach(userID, tripID, orderID int64) (*models.Order, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	driver, err := repository.NewDriverRepository(tx).GetByUserIDForUpdate(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if !driver.IsActive {
+		return nil, forbidden("Driver account is not active")
+	}
+
+	trip, err := repository.NewTripRepository(tx).GetForUpdate(tripID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trip.DriverID != driver.ID) {
+		return nil, notFound("Trip not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if trip.Status != models.TripOpen {
+		return nil, invalid("Trip is not taking orders")
+	}
+
+	order, err := repository.NewOrderRepository(tx).GetForUpdate(orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Order not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if order.FromRegionID != trip.FromRegionID || order.ToRegionID != trip.ToRegionID ||
+		!sameDay(order.ScheduledDate, trip.ScheduledDate) {
+		return nil, invalid("Order is not on this trip's route and date")
+	}
+	if !inDepartureWindow(trip, order) {
+		return nil, invalid("Order pickup time is outside the trip's departure window")
+	}
+	if tripSeats(order) > trip.SeatsTotal-trip.SeatsBooked {
+		return nil, conflict("Not enough seats left on this trip")
+	}
+
+	by := orderChange{Actor: models.ActorDriver, ActorID: userID}
+	if err := assignOrder(tx, driver, order, by); err != nil {
+		return nil, err
+	}
+	if err := joinTrip(tx, trip, order, by); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	accepted, err := s.orders.Get(order.ID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	publishOrderStatus(s.drivers, s.events, accepted)
+	if err := pushNotification(s.notifications, s.events, accepted.UserID, "Order Accepted", "A driver has booked your order on a shared ride.", "order_accepted", accepted.ID); err != nil {
+		log.Printf("Failed to notify user %d about accepted order %d: %v", accepted.UserID, accepted.ID, err)
+	}
+
+	return accepted, nil
+}
+
+// Close stops one of the driver's trips from taking more orders; the orders
+// already on it are unaffected
+func (s *TripService) Close(userID, tripID int64) (*models.Trip, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	trips := repository.NewTripRepository(tx)
+	trip, err := trips.GetForUpdate(tripID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trip.DriverID != driverID) {
+		return nil, notFound("Trip not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if trip.Status != models.TripOpen {
+		return nil, invalid("Only open trips can be closed")
+	}
+
+	if err := trips.SetStatus(trip.ID, models.TripClosed, nil); err != nil {
+		return nil, internal("Failed to close trip", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+	return s.reload(trip.ID)
+}
+
+// Cancel calls off one of the driver's trips before anyone is picked up.
+// Each order on it is released like a single order: it goes back to pending
+// for other drivers and the driver gets its service fee back, less the
+// release penalty for how close pickup is.
+func (s *TripService) Cancel(userID, tripID int64, reason string) (*TripCancellation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	defer tx.Rollback()
+
+	// The driver is locked first, as postToWallet requires, then the orders
+	// before the trip so bookings and cancellations cannot deadlock with it
+	driverID, err := lockDriver(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	orders := repository.NewOrderRepository(tx)
+	if _, err := orders.ListOnTripForUpdate(tripID); err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	trips := repository.NewTripRepository(tx)
+	trip, err := trips.GetForUpdate(tripID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trip.DriverID != driverID) {
+		return nil, notFound("Trip not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if trip.Status == models.TripCancelled {
+		return nil, invalid("Trip is already cancelled")
+	}
+
+	booked, err := orders.ListOnTripForUpdate(tripID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	result := &TripCancellation{Released: []int64{}}
+	for i := range booked {
+		order := &booked[i]
+		if order.Status == models.OrderStatusInProgress {
+			return nil, invalid("Trip has passengers on board and cannot be cancelled")
+		}
+		refund, penalty, err := releaseOrder(tx, s.dispatch, s.jobs, order, orderChange{Actor: models.ActorDriver, ActorID: userID, DriverID: driverID, Reason: reason})
+		if err != nil {
+			return nil, err
+		}
+		result.Released = append(result.Released, order.ID)
+		result.Refund += refund
+		result.Penalty += penalty
+	}
+
+	var note *string
+	if reason != "" {
+		note = &reason
+	}
+	if err := trips.SetStatus(trip.ID, models.TripCancelled, note); err != nil {
+		return nil, internal("Failed to cancel trip", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, internal("Failed to commit transaction", err)
+	}
+
+	for _, id := range result.Released {
+		released, err := s.orders.Get(id)
+		if err != nil {
+			log.Printf("Failed to load released order %d: %v", id, err)
+			continue
+		}
+		announceRelease(s.notifications, s.events, s.jobs, released)
+	}
+
+	result.Trip, err = s.reload(trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Search returns the open trips on a region pair and date with enough seats
+// left, earliest departure first
+func (s *TripService) Search(q TripSearch) ([]TripOffer, error) {
+	if q.Seats == 0 {
+		q.Seats = 1
+	}
+	if q.Seats < 1 || q.Seats > maxTripSeats {
+		return nil, invalid("Seats must be between 1 and 8")
+	}
+	if q.FromRegionID == 0 || q.ToRegionID == 0 {
+		return nil, invalid("From and To regions are required")
+	}
+
+	trips, err := s.trips.SearchOpen(q.FromRegionID, q.ToRegionID, q.Date, q.Seats)
+	if err != nil {
+		return nil, internal("Failed to fetch trips", err)
+	}
+
+	drivers := map[int64]TripDriver{}
+	offers := make([]TripOffer, 0, len(trips))
+	for _, trip := range trips {
+		driver, ok := drivers[trip.DriverID]
+		if !ok {
+			driver, err = s.tripDriver(trip.DriverID)
+			if err != nil {
+				return nil, err
+			}
+			drivers[trip.DriverID] = driver
+		}
+		offers = append(offers, TripOffer{Trip: trip, SeatsLeft: trip.SeatsTotal - trip.SeatsBooked, Driver: driver})
+	}
+	return offers, nil
+}
+
+// Offer returns an open trip as customers see it
+func (s *TripService) Offer(tripID int64) (*TripOffer, error) {
+	trip, err := s.trips.Get(tripID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trip.Status != models.TripOpen) {
+		return nil, notFound("Trip not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	driver, err := s.tripDriver(trip.DriverID)
+	if err != nil {
+		return nil, err
+	}
+	return &TripOffer{Trip: *trip, SeatsLeft: trip.SeatsTotal - trip.SeatsBooked, Driver: driver}, nil
+}
+
+func (s *TripService) tripDriver(driverID int64) (TripDriver, error) {
+	driver, err := s.drivers.Get(driverID)
+	if err != nil {
+		return TripDriver{}, internal("Database error", err)
+	}
+	return TripDriver{FullName: driver.FullName, CarModel: driver.CarModel, Rating: driver.Rating, TotalRatings: driver.TotalRatings}, nil
+}
+
+// BookTrip books seats on an open trip for a customer. The order is created
+// already accepted by the trip's driver, who pays its service fee as for any
+// accepted order, at the driver's price per seat with no passenger discount.
+func (s *OrderService) BookTrip(in BookTripInput) (*models.Order, error) {
+	paymentMethod, err := parsePaymentMethod(in.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	trip, err := s.trips.Get(in.TripID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Trip not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	if trip.Status != models.TripOpen {
+		return nil, invalid("Trip is not taking bookings")
+	}
+	if in.Seats < 1 {
+		return nil, invalid("Seats must be at least 1")
+	}
+	if in.Seats > trip.SeatsTotal-trip.SeatsBooked {
+		return nil, conflict("Not enough seats left on this trip")
+	}
+
+	price, err := s.seatPrice(trip, in.Seats)
+	if err != nil {
+		return nil, err
+	}
+
+	route := in.Route
+	route.FromRegionID, route.ToRegionID = trip.FromRegionID, trip.ToRegionID
+	schedule := Schedule{Date: trip.ScheduledDate, TimeRangeStart: trip.DepartureStart, TimeRangeEnd: trip.DepartureEnd}
+
+	seats := int64(in.Seats)
+	order := newOrder(in.UserID, models.OrderTypeTaxi, in.CustomerName, in.CustomerPhone, route, schedule, in.Notes, price, s.dispatch.AcceptWindow())
+	order.PassengerCount = &seats
+	order.PaymentMethod = paymentMethod
+
+	var driverUserID int64
+	err = s.insertOrder(order, price, func(tx *sql.Tx, order *models.Order) error {
+		// The driver is locked before the trip, as when a driver books an
+		// order onto it
+		driver, err := repository.NewDriverRepository(tx).GetForUpdate(trip.DriverID)
+		if err != nil {
+			return internal("Database error", err)
+		}
+		if driver.UserID == order.UserID {
+			return invalid("You cannot book your own trip")
+		}
+		if !driver.IsActive || driver.Balance < order.ServiceFee {
+			return invalid("The driver cannot take bookings right now")
+		}
+
+		locked, err := repository.NewTripRepository(tx).GetForUpdate(trip.ID)
+		if err != nil {
+			return internal("Database error", err)
+		}
+		if locked.Status != models.TripOpen {
+			return invalid("Trip is not taking bookings")
+		}
+		if in.Seats > locked.SeatsTotal-locked.SeatsBooked {
+			return conflict("Not enough seats left on this trip")
+		}
+
+		by := orderChange{Actor: models.ActorCustomer, ActorID: order.UserID}
+		if err := assignOrder(tx, driver, order, by); err != nil {
+			return err
+		}
+		driverUserID = driver.UserID
+		return joinTrip(tx, locked, order, by)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := s.orders.Get(order.ID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+
+	publishOrderStatus(s.drivers, s.events, booked)
+	message := fmt.Sprintf("A customer booked %d seat(s) on your trip on %s.", in.Seats, trip.ScheduledDate.Format("02.01.2006"))
+	if err := pushNotification(s.notifications, s.events, driverUserID, "New Booking", message, "trip_booked", booked.ID); err != nil {
+		log.Printf("Failed to notify driver user %d about booking %d: %v", driverUserID, booked.ID, err)
+	}
+	s.alerts.NewOrder(booked, userLanguage(s.users, booked.UserID))
+
+	return booked, nil
+}
+
+// seatPrice prices seats on a shared trip: the driver's price per seat, no
+// passenger discount, and the service fee of the trip's route
+func (s *OrderService) seatPrice(trip *models.Trip, seats int) (PriceBreakdown, error) {
+	pricing, err := s.pricing.GetRoute(trip.FromRegionID, trip.ToRegionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return PriceBreakdown{}, invalid("pricing not configured for this route")
+	}
+	if err != nil {
+		return PriceBreakdown{}, internal("database error", err)
+	}
+
+	price := ComputeTaxiPrice(trip.PricePerSeat*float64(seats), 0, pricing.ServiceFee)
+	price.Strategy = models.PricingPerSeat
+	price.PricePerPerson = trip.PricePerSeat
+	return price, nil
+}
+
+func (s *TripService) driverTrip(userID, tripID int64) (*models.Trip, error) {
+	driverID, err := s.driverIDForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	trip, err := s.trips.Get(tripID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && trip.DriverID != driverID) {
+		return nil, notFound("Trip not found")
+	}
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return trip, nil
+}
+
+func (s *TripService) driverIDForUser(userID int64) (int64, error) {
+	driver, err := s.drivers.GetByUserID(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, notFound("Driver profile not found")
+	}
+	if err != nil {
+		return 0, internal("Database error", err)
+	}
+	return driver.ID, nil
+}
+
+func (s *TripService) reload(tripID int64) (*models.Trip, error) {
+	trip, err := s.trips.Get(tripID)
+	if err != nil {
+		return nil, internal("Database error", err)
+	}
+	return trip, nil
+}
+
+// tripSeats returns the seats an order takes on a shared ride; deliveries
+// ride along without one
+func tripSeats(order *models.Order) int {
+	if order.PassengerCount == nil {
+		return 0
+	}
+	return int(*order.PassengerCount)
+}
+
+// joinTrip books an order's seats on a trip the caller has locked and records
+// it in the order's audit trail
+func joinTrip(tx repository.Querier, trip *models.Trip, order *models.Order, change orderChange) error {
+	seats := tripSeats(order)
+	err := repository.NewTripRepository(tx).ReserveSeats(trip.ID, seats)
+	if errors.Is(err, repository.ErrNotFound) {
+		return conflict("Not enough seats left on this trip")
+	}
+	if err != nil {
+		return internal("Failed to book seats", err)
+	}
+	if err := repository.NewOrderRepository(tx).SetTrip(order.ID, trip.ID); err != nil {
+		return internal("Failed to book seats", err)
+	}
+
+	change.Details = map[string]interface{}{"trip_id": trip.ID, "seats": seats}
+	if err := recordOrderEvent(tx, order.ID, models.OrderEventTripJoined, nil, nil, change); err != nil {
+		return err
+	}
+	trip.SeatsBooked += seats
+	order.TripID = &trip.ID
+	return nil
+}
+
+// leaveTrip frees the seats of an order that is cancelled or released from
+// its trip. A released order is no longer linked to the trip; a cancelled
+// one keeps the link for the record.
+func leaveTrip(tx repository.Querier, order *models.Order, change orderChange) error {
+	tripID, seats := *order.TripID, tripSeats(order)
+	if err := repository.NewTripRepository(tx).FreeSeats(tripID, seats); err != nil {
+		return internal("Failed to free seats", err)
+	}
+
+	change.Details = map[string]interface{}{"trip_id": tripID, "seats": seats}
+	if err := recordOrderEvent(tx, order.ID, models.OrderEventTripLeft, nil, nil, change); err != nil {
+		return err
+	}
+	if change.To == models.OrderStatusPending {
+		order.TripID = nil
+	}
+	return nil
+}
+
+// inDepartureWindow reports whether an order's time range overlaps the trip's
+// departure window. Orders without a readable time range match on date alone.
+func inDepartureWindow(trip *models.Trip, order *models.Order) bool {
+	from, okFrom := minuteOfDay(order.TimeRangeStart)
+	to, okTo := minuteOfDay(order.TimeRangeEnd)
+	if !okFrom {
+		return true
+	}
+	if !okTo || to < from {
+		to = from
+	}
+	start, _ := minuteOfDay(trip.DepartureStart)
+	end, _ := minuteOfDay(trip.DepartureEnd)
+	return from <= end && to >= start
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
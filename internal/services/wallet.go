@@ -0,0 +1,116 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"taxi-service/internal/models"
+	"taxi-service/internal/repository"
+	"taxi-service/internal/statement"
+)
+
+const (
+	defaultWalletPageSize = 20
+	maxWalletPageSize     = 100
+)
+
+// WalletQuery filters and pages a driver's wallet history
+type WalletQuery struct {
+	Kind     string // Ledger kind, e.g. service_fee; empty for all
+	FromDate string // YYYY-MM-DD, inclusive
+	ToDate   string // YYYY-MM-DD, inclusive
+	Cursor   string // NextCursor of the previous page
+	Limit    int
+}
+
+// WalletPage is one page of wallet history, newest first
+type WalletPage struct {
+	Transactions []models.WalletTransaction `json:"transactions"`
+	NextCursor   string                     `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// listWallet returns one page of a driver's wallet history
+func listWallet(ledger *repository.LedgerRepository, driverID int64, q WalletQuery) (*WalletPage, error) {
+	filter := repository.WalletFilter{
+		DriverID: driverID,
+		Kind:     q.Kind,
+		FromDate: q.FromDate,
+		ToDate:   q.ToDate,
+		Limit:    q.Limit,
+	}
+
+	switch models.LedgerKind(q.Kind) {
+	case "", models.LedgerServiceFee, models.LedgerFeeRefund, models.LedgerTopUp, models.LedgerOpeningBalance:
+	default:
+		return nil, invalid("Invalid transaction type")
+	}
+	for _, date := range []string{q.FromDate, q.ToDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, invalid("Invalid date format, use YYYY-MM-DD")
+		}
+	}
+	if q.Cursor != "" {
+		before, err := strconv.ParseInt(q.Cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return nil, invalid("Invalid cursor")
+		}
+		filter.BeforeID = before
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultWalletPageSize
+	}
+	if filter.Limit > maxWalletPageSize {
+		filter.Limit = maxWalletPageSize
+	}
+
+	// One extra row tells whether another page follows
+	pageSize := filter.Limit
+	filter.Limit++
+	transactions, err := ledger.ListWallet(filter)
+	if err != nil {
+		return nil, internal("Failed to fetch wallet transactions", err)
+	}
+
+	page := &WalletPage{Transactions: transactions}
+	if len(transactions) > pageSize {
+		page.Transactions = transactions[:pageSize]
+		page.NextCursor = strconv.FormatInt(transactions[pageSize-1].ID, 10)
+	}
+	return page, nil
+}
+
+// walletStatement collects a driver's postings for the calendar month
+// containing month, oldest first, with the balance the month started with
+func walletStatement(ledger *repository.LedgerRepository, driver *models.Driver, month time.Time) (*statement.Statement, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	end := start.AddDate(0, 1, -1)
+
+	opening, err := ledger.WalletBalanceBefore(driver.ID, start.Format("2006-01-02"))
+	if err != nil {
+		return nil, internal("Failed to compute opening balance", err)
+	}
+
+	transactions, err := ledger.ListWallet(repository.WalletFilter{
+		DriverID: driver.ID,
+		FromDate: start.Format("2006-01-02"),
+		ToDate:   end.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, internal("Failed to fetch wallet transactions", err)
+	}
+	for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+		transactions[i], transactions[j] = transactions[j], transactions[i]
+	}
+
+	return &statement.Statement{
+		DriverID:       driver.ID,
+		DriverName:     driver.FullName,
+		Month:          start,
+		OpeningBalance: opening,
+		Transactions:   transactions,
+		GeneratedAt:    time.Now(),
+	}, nil
+}
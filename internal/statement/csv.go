@@ -0,0 +1,42 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"taxi-service/internal/models"
+)
+
+var csvHeader = []string{"date", "transaction_id", "kind", "order_id", "description", "amount", "balance"}
+
+// WriteCSV writes one row per posting with the running balance after it. The
+// opening balance is the balance of the first row minus its amount.
+func WriteCSV(w io.Writer, s *Statement) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+
+	err := s.rows(func(t models.WalletTransaction, balance float64) error {
+		orderID := ""
+		if t.OrderID != nil {
+			orderID = strconv.FormatInt(*t.OrderID, 10)
+		}
+		return out.Write([]string{
+			t.CreatedAt.Format("2006-01-02 15:04:05"),
+			strconv.FormatInt(t.ID, 10),
+			string(t.Kind),
+			orderID,
+			t.Description,
+			formatAmount(t.Amount),
+			formatAmount(balance),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,213 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"taxi-service/internal/models"
+)
+
+// A4 portrait in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 40.0
+	rowHeight  = 14.0
+)
+
+// Table columns: left edges for text, right edges for amounts
+const (
+	colDate        = margin
+	colKind        = 130.0
+	colOrder       = 205.0
+	colDescription = 250.0
+	colAmountRight = 475.0
+	colBalance     = pageWidth - margin
+)
+
+const descriptionWidth = 40 // characters
+
+// WritePDF renders the statement as a PDF document: a summary on the first
+// page followed by the postings, continued over as many pages as needed. It
+// uses the standard Helvetica fonts, which cover Latin-1; other characters are
+// printed as '?'.
+func WritePDF(w io.Writer, s *Statement) error {
+	var pages []*pdfPage
+	page := &pdfPage{}
+	pages = append(pages, page)
+
+	y := pageHeight - margin - 16
+	page.text(margin, y, true, 16, "Wallet statement")
+	y -= 24
+	page.text(margin, y, false, 10, fmt.Sprintf("Driver: %s (ID %d)", s.DriverName, s.DriverID))
+	y -= rowHeight
+	page.text(margin, y, false, 10, "Period: "+s.Month.Format("January 2006"))
+	y -= rowHeight
+	page.text(margin, y, false, 10, "Generated: "+s.GeneratedAt.Format("2006-01-02 15:04"))
+	y -= 2 * rowHeight
+
+	for _, line := range []struct {
+		label  string
+		amount float64
+		bold   bool
+	}{
+		{"Opening balance", s.OpeningBalance, false},
+		{"Credits", s.Credits(), false},
+		{"Debits", s.Debits(), false},
+		{"Closing balance", s.ClosingBalance(), true},
+	} {
+		page.text(margin, y, line.bold, 10, line.label)
+		page.textRight(margin+250, y, line.bold, 10, formatAmount(line.amount))
+		y -= rowHeight
+	}
+	y -= rowHeight
+
+	header := func() {
+		page.text(colDate, y, true, 9, "Date")
+		page.text(colKind, y, true, 9, "Kind")
+		page.text(colOrder, y, true, 9, "Order")
+		page.text(colDescription, y, true, 9, "Description")
+		page.textRight(colAmountRight, y, true, 9, "Amount")
+		page.textRight(colBalance, y, true, 9, "Balance")
+		page.line(margin, y-4, pageWidth-margin, y-4)
+		y -= rowHeight + 2
+	}
+	header()
+
+	if len(s.Transactions) == 0 {
+		page.text(colDate, y, false, 9, "No wallet activity in this period.")
+	}
+
+	_ = s.rows(func(t models.WalletTransaction, balance float64) error {
+		if y < margin {
+			page = &pdfPage{}
+			pages = append(pages, page)
+			y = pageHeight - margin - 9
+			header()
+		}
+		page.text(colDate, y, false, 9, t.CreatedAt.Format("2006-01-02 15:04"))
+		page.text(colKind, y, false, 9, string(t.Kind))
+		page.text(colOrder, y, false, 9, formatOrder(t.OrderID))
+		page.text(colDescription, y, false, 9, truncate(t.Description, descriptionWidth))
+		page.textRight(colAmountRight, y, false, 9, formatAmount(t.Amount))
+		page.textRight(colBalance, y, false, 9, formatAmount(balance))
+		y -= rowHeight
+		return nil
+	})
+
+	for i, p := range pages {
+		p.textRight(pageWidth-margin, margin/2, false, 8, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	return writePDF(w, pages)
+}
+
+// pdfPage collects the content stream of one page
+type pdfPage struct {
+	content strings.Builder
+}
+
+func (p *pdfPage) text(x, y float64, bold bool, size float64, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// textRight places text so that it ends at x
+func (p *pdfPage) textRight(x, y float64, bold bool, size float64, s string) {
+	p.text(x-textWidth(s, size), y, bold, size, s)
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// writePDF lays out the objects of a minimal PDF 1.4 file: catalog, page tree,
+// the two fonts, then a page and a content stream per page, followed by the
+// cross-reference table.
+func writePDF(w io.Writer, pages []*pdfPage) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for _, page := range pages {
+		content := page.content.String()
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, len(offsets)+2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString escapes text for a PDF string literal in WinAnsi encoding
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// textWidth approximates the width of Helvetica text; it is only used to
+// right-align amounts, which are digits and punctuation
+func textWidth(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			units += 556
+		case r == '.' || r == ',' || r == ' ':
+			units += 278
+		case r == '-':
+			units += 333
+		default:
+			units += 600
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,75 @@
+// Package statement renders monthly driver wallet statements as CSV and PDF.
+// The services package collects the postings; this package only formats them.
+package statement
+
+import (
+	"fmt"
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+// Statement is a driver's wallet activity over one calendar month
+type Statement struct {
+	DriverID       int64
+	DriverName     string
+	Month          time.Time // First day of the month
+	OpeningBalance float64
+	Transactions   []models.WalletTransaction // Oldest first
+	GeneratedAt    time.Time
+}
+
+// Credits sums the money added to the wallet during the month
+func (s *Statement) Credits() float64 {
+	total := 0.0
+	for _, t := range s.Transactions {
+		if t.Amount > 0 {
+			total += t.Amount
+		}
+	}
+	return total
+}
+
+// Debits sums the money taken from the wallet during the month, as a negative amount
+func (s *Statement) Debits() float64 {
+	total := 0.0
+	for _, t := range s.Transactions {
+		if t.Amount < 0 {
+			total += t.Amount
+		}
+	}
+	return total
+}
+
+// ClosingBalance is the balance at the end of the month
+func (s *Statement) ClosingBalance() float64 {
+	return s.OpeningBalance + s.Credits() + s.Debits()
+}
+
+// FileName is the suggested download name for the given extension
+func (s *Statement) FileName(ext string) string {
+	return fmt.Sprintf("statement-%d-%s.%s", s.DriverID, s.Month.Format("2006-01"), ext)
+}
+
+// rows walks the transactions with the balance after each one
+func (s *Statement) rows(visit func(t models.WalletTransaction, balance float64) error) error {
+	balance := s.OpeningBalance
+	for _, t := range s.Transactions {
+		balance += t.Amount
+		if err := visit(t, balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func formatOrder(orderID *int64) string {
+	if orderID == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%d", *orderID)
+}
@@ -0,0 +1,196 @@
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"taxi-service/internal/models"
+)
+
+type phrases struct {
+	newOrder          string
+	orderCancelled    string
+	driverApplication string
+	feedback          string
+	order             string
+	orderType         map[models.OrderType]string
+	customer          string
+	route             string
+	schedule          string
+	passengers        string
+	deliveryType      string
+	price             string
+	reason            string
+	driver            string
+	car               string
+	phone             string
+	from              string
+	notSpecified      string
+}
+
+var translations = map[models.Language]phrases{
+	models.LangUzLatin: {
+		newOrder:          "🆕 Yangi buyurtma",
+		orderCancelled:    "❌ Buyurtma bekor qilindi",
+		driverApplication: "🚗 Yangi haydovchi arizasi",
+		feedback:          "💬 Yangi fikr-mulohaza",
+		order:             "Buyurtma",
+		orderType:         map[models.OrderType]string{models.OrderTypeTaxi: "Taksi", models.OrderTypeDelivery: "Yetkazib berish"},
+		customer:          "Mijoz",
+		route:             "Yo'nalish",
+		schedule:          "Vaqt",
+		passengers:        "Yo'lovchilar",
+		deliveryType:      "Yuk turi",
+		price:             "Narx",
+		reason:            "Sabab",
+		driver:            "Haydovchi",
+		car:               "Avtomobil",
+		phone:             "Telefon",
+		from:              "Kimdan",
+		notSpecified:      "ko'rsatilmagan",
+	},
+	models.LangUzCyrillic: {
+		newOrder:          "🆕 Янги буюртма",
+		orderCancelled:    "❌ Буюртма бекор қилинди",
+		driverApplication: "🚗 Янги ҳайдовчи аризаси",
+		feedback:          "💬 Янги фикр-мулоҳаза",
+		order:             "Буюртма",
+		orderType:         map[models.OrderType]string{models.OrderTypeTaxi: "Такси", models.OrderTypeDelivery: "Етказиб бериш"},
+		customer:          "Мижоз",
+		route:             "Йўналиш",
+		schedule:          "Вақт",
+		passengers:        "Йўловчилар",
+		deliveryType:      "Юк тури",
+		price:             "Нарх",
+		reason:            "Сабаб",
+		driver:            "Ҳайдовчи",
+		car:               "Автомобиль",
+		phone:             "Телефон",
+		from:              "Кимдан",
+		notSpecified:      "кўрсатилмаган",
+	},
+	models.LangRussian: {
+		newOrder:          "🆕 Новый заказ",
+		orderCancelled:    "❌ Заказ отменён",
+		driverApplication: "🚗 Новая заявка водителя",
+		feedback:          "💬 Новый отзыв",
+		order:             "Заказ",
+		orderType:         map[models.OrderType]string{models.OrderTypeTaxi: "Такси", models.OrderTypeDelivery: "Доставка"},
+		customer:          "Клиент",
+		route:             "Маршрут",
+		schedule:          "Время",
+		passengers:        "Пассажиры",
+		deliveryType:      "Тип груза",
+		price:             "Цена",
+		reason:            "Причина",
+		driver:            "Водитель",
+		car:               "Автомобиль",
+		phone:             "Телефон",
+		from:              "От",
+		notSpecified:      "не указана",
+	},
+}
+
+func phrasesFor(lang models.Language) phrases {
+	if p, ok := translations[lang]; ok {
+		return p
+	}
+	return translations[models.LangUzLatin]
+}
+
+func newOrderMessage(order *models.Order, places Places, lang models.Language) string {
+	p := phrasesFor(lang)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s #%d (%s)\n", p.newOrder, order.ID, p.orderType[order.OrderType])
+	writeOrderDetails(&b, p, order, places, lang)
+	return b.String()
+}
+
+func orderCancelledMessage(order *models.Order, reason string, places Places, lang models.Language) string {
+	p := phrasesFor(lang)
+	if reason == "" {
+		reason = p.notSpecified
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s #%d (%s)\n", p.orderCancelled, order.ID, p.orderType[order.OrderType])
+	writeOrderDetails(&b, p, order, places, lang)
+	fmt.Fprintf(&b, "%s: %s\n", p.reason, reason)
+	return b.String()
+}
+
+func writeOrderDetails(b *strings.Builder, p phrases, order *models.Order, places Places, lang models.Language) {
+	from := describePlace(places, lang, order.FromRegionID, order.FromDistrictID, order.FromAddress)
+	to := describePlace(places, lang, order.ToRegionID, order.ToDistrictID, order.ToAddress)
+	fmt.Fprintf(b, "%s: %s, %s\n", p.customer, order.CustomerName, order.CustomerPhone)
+	fmt.Fprintf(b, "%s: %s → %s\n", p.route, from, to)
+	fmt.Fprintf(b, "%s: %s %s–%s\n", p.schedule, order.ScheduledDate.Format("2006-01-02"), order.TimeRangeStart, order.TimeRangeEnd)
+	if order.PassengerCount != nil {
+		fmt.Fprintf(b, "%s: %d\n", p.passengers, *order.PassengerCount)
+	}
+	if order.DeliveryType != nil {
+		fmt.Fprintf(b, "%s: %s\n", p.deliveryType, *order.DeliveryType)
+	}
+	fmt.Fprintf(b, "%s: %.0f\n", p.price, order.FinalPrice)
+}
+
+// describePlace names one end of an order's route: the address when the
+// customer gave one, otherwise its region and district in lang. A region that
+// cannot be looked up is shown by ID.
+func describePlace(places Places, lang models.Language, regionID, districtID int64, address *string) string {
+	if address != nil && *address != "" {
+		return *address
+	}
+	if places == nil {
+		return fmt.Sprintf("#%d", regionID)
+	}
+
+	region, err := places.GetRegion(regionID)
+	if err != nil {
+		log.Printf("Failed to look up region %d for an alert: %v", regionID, err)
+		return fmt.Sprintf("#%d", regionID)
+	}
+	name := localName(lang, region.NameUzLat, region.NameUzCyr, region.NameRu)
+	if districtID == 0 {
+		return name
+	}
+	district, err := places.GetDistrict(districtID)
+	if err != nil {
+		log.Printf("Failed to look up district %d for an alert: %v", districtID, err)
+		return name
+	}
+	return name + ", " + localName(lang, district.NameUzLat, district.NameUzCyr, district.NameRu)
+}
+
+// localName picks the name in lang, falling back to Uzbek Latin when it has
+// none in that language
+func localName(lang models.Language, uzLat, uzCyr, ru string) string {
+	switch {
+	case lang == models.LangUzCyrillic && uzCyr != "":
+		return uzCyr
+	case lang == models.LangRussian && ru != "":
+		return ru
+	}
+	return uzLat
+}
+
+func driverApplicationMessage(app *models.DriverApplication, lang models.Language) string {
+	p := phrasesFor(lang)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s #%d\n", p.driverApplication, app.ID)
+	fmt.Fprintf(&b, "%s: %s\n", p.driver, app.FullName)
+	fmt.Fprintf(&b, "%s: %s\n", p.phone, app.PhoneNumber)
+	fmt.Fprintf(&b, "%s: %s (%s)\n", p.car, app.CarModel, app.CarNumber)
+	return b.String()
+}
+
+func feedbackMessage(feedback *models.Feedback, user *models.User) string {
+	p := phrasesFor(user.Language)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s #%d\n", p.feedback, feedback.ID)
+	fmt.Fprintf(&b, "%s: %s, %s\n", p.from, user.Name, user.PhoneNumber)
+	b.WriteString("\n")
+	b.WriteString(feedback.Message)
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"taxi-service/internal/models"
+)
+
+func testOrder() *models.Order {
+	passengers := int64(2)
+	address := "Amir Temur 1"
+	return &models.Order{
+		ID:             42,
+		OrderType:      models.OrderTypeTaxi,
+		CustomerName:   "Aziz",
+		CustomerPhone:  "+998901234567",
+		FromRegionID:   1,
+		FromAddress:    &address,
+		ToRegionID:     2,
+		ToDistrictID:   21,
+		PassengerCount: &passengers,
+		ScheduledDate:  time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		TimeRangeStart: "09:00",
+		TimeRangeEnd:   "10:00",
+		FinalPrice:     150000,
+	}
+}
+
+// fakePlaces knows the regions and districts of the test orders
+type fakePlaces struct{}
+
+var errNoPlace = errors.New("not found")
+
+func (fakePlaces) GetRegion(id int64) (*models.Region, error) {
+	switch id {
+	case 1:
+		return &models.Region{ID: 1, NameUzLat: "Toshkent", NameUzCyr: "Тошкент", NameRu: "Ташкент"}, nil
+	case 2:
+		return &models.Region{ID: 2, NameUzLat: "Samarqand", NameUzCyr: "Самарқанд", NameRu: "Самарканд"}, nil
+	case 3:
+		return &models.Region{ID: 3, NameUzLat: "Xiva"}, nil
+	}
+	return nil, errNoPlace
+}
+
+func (fakePlaces) GetDistrict(id int64) (*models.District, error) {
+	if id == 21 {
+		return &models.District{ID: 21, RegionID: 2, NameUzLat: "Urgut", NameUzCyr: "Ургут", NameRu: "Ургут"}, nil
+	}
+	return nil, errNoPlace
+}
+
+func TestNewOrderMessageUsesLanguage(t *testing.T) {
+	tests := []struct {
+		lang models.Language
+		want []string
+	}{
+		{models.LangUzLatin, []string{"🆕 Yangi buyurtma #42 (Taksi)", "Mijoz: Aziz, +998901234567", "Yo'lovchilar: 2"}},
+		{models.LangUzCyrillic, []string{"🆕 Янги буюртма #42 (Такси)", "Мижоз: Aziz", "Йўловчилар: 2"}},
+		{models.LangRussian, []string{"🆕 Новый заказ #42 (Такси)", "Клиент: Aziz", "Пассажиры: 2"}},
+		{models.Language("en"), []string{"🆕 Yangi buyurtma #42 (Taksi)"}}, // unknown languages fall back to Uzbek Latin
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.lang), func(t *testing.T) {
+			got := newOrderMessage(testOrder(), fakePlaces{}, tt.lang)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("message %q does not contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewOrderMessageDetails(t *testing.T) {
+	got := newOrderMessage(testOrder(), fakePlaces{}, models.LangRussian)
+	for _, want := range []string{
+		"Маршрут: Amir Temur 1 → Самарканд, Ургут", // an address beats the region and district
+		"Время: 2024-05-01 09:00–10:00",
+		"Цена: 150000",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestOrderCancelledMessageReason(t *testing.T) {
+	tests := []struct {
+		lang   models.Language
+		reason string
+		want   string
+	}{
+		{models.LangUzLatin, "Plans changed", "Sabab: Plans changed"},
+		{models.LangUzLatin, "", "Sabab: ko'rsatilmagan"},
+		{models.LangUzCyrillic, "", "Сабаб: кўрсатилмаган"},
+		{models.LangRussian, "", "Причина: не указана"},
+	}
+	for _, tt := range tests {
+		got := orderCancelledMessage(testOrder(), tt.reason, fakePlaces{}, tt.lang)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s, reason %q: message %q does not contain %q", tt.lang, tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestDescribePlace(t *testing.T) {
+	address := "Amir Temur 1"
+	empty := ""
+	tests := []struct {
+		name       string
+		places     Places
+		lang       models.Language
+		regionID   int64
+		districtID int64
+		address    *string
+		want       string
+	}{
+		{"address", fakePlaces{}, models.LangUzLatin, 2, 21, &address, "Amir Temur 1"},
+		{"region and district", fakePlaces{}, models.LangUzLatin, 2, 21, nil, "Samarqand, Urgut"},
+		{"in Cyrillic", fakePlaces{}, models.LangUzCyrillic, 2, 21, &empty, "Самарқанд, Ургут"},
+		{"in Russian", fakePlaces{}, models.LangRussian, 2, 21, nil, "Самарканд, Ургут"},
+		{"no name in the language", fakePlaces{}, models.LangRussian, 3, 0, nil, "Xiva"},
+		{"unknown district", fakePlaces{}, models.LangUzLatin, 1, 99, nil, "Toshkent"},
+		{"unknown region", fakePlaces{}, models.LangUzLatin, 9, 21, nil, "#9"},
+		{"no lookup", nil, models.LangUzLatin, 2, 21, nil, "#2"},
+	}
+	for _, tt := range tests {
+		if got := describePlace(tt.places, tt.lang, tt.regionID, tt.districtID, tt.address); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDriverApplicationMessage(t *testing.T) {
+	app := &models.DriverApplication{ID: 7, FullName: "Bobur", PhoneNumber: "+998907654321", CarModel: "Cobalt", CarNumber: "01A123BC"}
+	got := driverApplicationMessage(app, models.LangUzCyrillic)
+	for _, want := range []string{"🚗 Янги ҳайдовчи аризаси #7", "Ҳайдовчи: Bobur", "Автомобиль: Cobalt (01A123BC)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestFeedbackMessageUsesAuthorLanguage(t *testing.T) {
+	feedback := &models.Feedback{ID: 3, Message: "Great service"}
+	user := &models.User{Name: "Dilnoza", PhoneNumber: "+998901112233", Language: models.LangRussian}
+	got := feedbackMessage(feedback, user)
+	for _, want := range []string{"💬 Новый отзыв #3", "От: Dilnoza, +998901112233", "Great service"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message %q does not contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Package telegram posts admin alerts to a Telegram group through the Bot API.
+// Messages are queued and sent by a single worker that spaces them out to stay
+// under Telegram's per-group rate limit and retries transient failures.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+)
+
+const queueSize = 100
+
+// retryBackoff is the wait before the first retry of a failed send; it
+// doubles with every further retry
+var retryBackoff = time.Second
+
+// Places looks up the regions and districts that order alerts name; the
+// region service is one
+type Places interface {
+	GetRegion(id int64) (*models.Region, error)
+	GetDistrict(id int64) (*models.District, error)
+}
+
+// Notifier sends admin group alerts. A notifier without a bot token or group
+// ID is disabled and silently drops every alert.
+type Notifier struct {
+	apiURL      string
+	token       string
+	chatID      string
+	minInterval time.Duration
+	maxRetries  int
+	client      *http.Client
+	places      Places // nil shows regions by ID
+
+	queue     chan string
+	done      chan struct{}
+	mu        sync.RWMutex // guards closed against sends on the closed queue
+	closed    bool
+	closeOnce sync.Once
+}
+
+// NewNotifier creates a notifier and starts its send worker. Order alerts name
+// their regions and districts through places.
+func NewNotifier(cfg *config.TelegramConfig, places Places) *Notifier {
+	n := &Notifier{
+		apiURL:      strings.TrimRight(cfg.APIURL, "/"),
+		token:       cfg.BotToken,
+		chatID:      cfg.AdminGroupID,
+		minInterval: time.Duration(cfg.MinInterval) * time.Millisecond,
+		maxRetries:  cfg.MaxRetries,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		places:      places,
+	}
+	if n.maxRetries < 1 {
+		n.maxRetries = 1
+	}
+	if !n.Enabled() {
+		log.Println("Telegram notifier disabled: TELEGRAM_BOT_TOKEN or TELEGRAM_ADMIN_GROUP_ID not set")
+		return n
+	}
+
+	n.queue = make(chan string, queueSize)
+	n.done = make(chan struct{})
+	go n.run()
+	return n
+}
+
+// Enabled reports whether alerts are actually sent
+func (n *Notifier) Enabled() bool {
+	return n.token != "" && n.chatID != ""
+}
+
+// NewOrder alerts the admins about a newly placed order
+func (n *Notifier) NewOrder(order *models.Order, lang models.Language) {
+	if !n.Enabled() {
+		return
+	}
+	n.enqueue(newOrderMessage(order, n.places, lang))
+}
+
+// OrderCancelled alerts the admins about a cancelled order
+func (n *Notifier) OrderCancelled(order *models.Order, reason string, lang models.Language) {
+	if !n.Enabled() {
+		return
+	}
+	n.enqueue(orderCancelledMessage(order, reason, n.places, lang))
+}
+
+// DriverApplication alerts the admins about an application waiting for review
+func (n *Notifier) DriverApplication(app *models.DriverApplication, lang models.Language) {
+	n.enqueue(driverApplicationMessage(app, lang))
+}
+
+// Feedback forwards a user's feedback to the admins
+func (n *Notifier) Feedback(feedback *models.Feedback, user *models.User) {
+	n.enqueue(feedbackMessage(feedback, user))
+}
+
+// Close stops accepting alerts and waits until the queued ones are sent, or
+// until ctx is done. Alerts raised after Close are dropped.
+func (n *Notifier) Close(ctx context.Context) error {
+	if !n.Enabled() {
+		return nil
+	}
+	n.closeOnce.Do(func() {
+		n.mu.Lock()
+		n.closed = true
+		close(n.queue)
+		n.mu.Unlock()
+	})
+
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d alerts left unsent: %w", len(n.queue), ctx.Err())
+	}
+}
+
+func (n *Notifier) enqueue(text string) {
+	if !n.Enabled() {
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		log.Printf("Telegram notifier closed, dropping alert: %.60q", text)
+		return
+	}
+	select {
+	case n.queue <- text:
+	default:
+		log.Printf("Telegram queue full, dropping alert: %.60q", text)
+	}
+}
+
+// run sends queued messages one at a time, at most one per minInterval
+func (n *Notifier) run() {
+	defer close(n.done)
+
+	var last time.Time
+	for text := range n.queue {
+		if wait := n.minInterval - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+		if err := n.sendWithRetry(text); err != nil {
+			log.Printf("Failed to send Telegram alert: %v", err)
+		}
+		last = time.Now()
+	}
+}
+
+func (n *Notifier) sendWithRetry(text string) error {
+	backoff := retryBackoff
+	var err error
+	for attempt := 1; attempt <= n.maxRetries; attempt++ {
+		var retryAfter time.Duration
+		retryAfter, err = n.send(text)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, errPermanent) || attempt == n.maxRetries {
+			break
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		time.Sleep(wait)
+		backoff *= 2
+	}
+	return err
+}
+
+// errPermanent marks Bot API failures that retrying cannot fix, such as a bad
+// token or an unknown chat
+var errPermanent = errors.New("permanent failure")
+
+type sendMessageRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// send posts one message; on a 429 it returns how long Telegram asked us to wait
+func (n *Notifier) send(text string) (time.Duration, error) {
+	body, err := json.Marshal(sendMessageRequest{ChatID: n.chatID, Text: text, DisableWebPagePreview: true})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errPermanent, err)
+	}
+
+	resp, err := n.client.Post(n.apiURL+"/bot"+n.token+"/sendMessage", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("unexpected response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.OK {
+		return 0, nil
+	}
+
+	apiErr := fmt.Errorf("bot API error %d: %s", result.ErrorCode, result.Description)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return time.Duration(result.Parameters.RetryAfter) * time.Second, apiErr
+	case resp.StatusCode >= 500:
+		return 0, apiErr
+	default:
+		return 0, fmt.Errorf("%w: %v", errPermanent, apiErr)
+	}
+}
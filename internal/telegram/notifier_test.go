@@ -0,0 +1,274 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"taxi-service/internal/config"
+	"taxi-service/internal/models"
+)
+
+const (
+	testToken  = "123:test"
+	testChatID = "-1001"
+)
+
+// fakeReply is one response of the fake Bot API
+type fakeReply struct {
+	status int
+	body   string
+}
+
+var replyOK = fakeReply{http.StatusOK, `{"ok":true,"result":{}}`}
+
+// fakeBotAPI serves sendMessage, answering with the scripted replies in turn
+// and then with success, and records every message it receives
+type fakeBotAPI struct {
+	t       *testing.T
+	server  *httptest.Server
+	replies []fakeReply
+	delay   time.Duration
+
+	mu       sync.Mutex
+	received []received
+}
+
+type received struct {
+	at   time.Time
+	text string
+}
+
+func newFakeBotAPI(t *testing.T, replies ...fakeReply) *fakeBotAPI {
+	f := &fakeBotAPI{t: t, replies: replies}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeBotAPI) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/bot"+testToken+"/sendMessage" {
+		f.t.Errorf("request to %s, want the sendMessage method", r.URL.Path)
+	}
+	var req sendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Errorf("decode request: %v", err)
+	}
+	if req.ChatID != testChatID {
+		f.t.Errorf("chat_id = %q, want %q", req.ChatID, testChatID)
+	}
+
+	f.mu.Lock()
+	f.received = append(f.received, received{at: time.Now(), text: req.Text})
+	reply := replyOK
+	if n := len(f.received); n <= len(f.replies) {
+		reply = f.replies[n-1]
+	}
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reply.status)
+	w.Write([]byte(reply.body))
+}
+
+func (f *fakeBotAPI) requests() []received {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]received(nil), f.received...)
+}
+
+func newTestNotifier(t *testing.T, api *fakeBotAPI, minInterval, maxRetries int) *Notifier {
+	n := NewNotifier(&config.TelegramConfig{
+		BotToken:     testToken,
+		AdminGroupID: testChatID,
+		APIURL:       api.server.URL,
+		MinInterval:  minInterval,
+		MaxRetries:   maxRetries,
+	}, fakePlaces{})
+	t.Cleanup(func() { n.Close(context.Background()) })
+	return n
+}
+
+// withRetryBackoff shortens the first retry wait for the duration of a test
+func withRetryBackoff(t *testing.T, backoff time.Duration) {
+	previous := retryBackoff
+	retryBackoff = backoff
+	t.Cleanup(func() { retryBackoff = previous })
+}
+
+func TestSendWithRetryHonoursRetryAfter(t *testing.T) {
+	withRetryBackoff(t, time.Millisecond)
+	api := newFakeBotAPI(t, fakeReply{http.StatusTooManyRequests, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`})
+	n := newTestNotifier(t, api, 0, 3)
+
+	if err := n.sendWithRetry("hello"); err != nil {
+		t.Fatalf("sendWithRetry: %v", err)
+	}
+
+	got := api.requests()
+	if len(got) != 2 {
+		t.Fatalf("%d requests, want 2", len(got))
+	}
+	if wait := got[1].at.Sub(got[0].at); wait < time.Second {
+		t.Errorf("retried after %s, want at least the 1s Telegram asked for", wait)
+	}
+}
+
+func TestSendWithRetryBacksOffOnServerErrors(t *testing.T) {
+	withRetryBackoff(t, 50*time.Millisecond)
+	badGateway := fakeReply{http.StatusBadGateway, `{"ok":false,"error_code":502,"description":"Bad Gateway"}`}
+	api := newFakeBotAPI(t, badGateway, badGateway)
+	n := newTestNotifier(t, api, 0, 3)
+
+	if err := n.sendWithRetry("hello"); err != nil {
+		t.Fatalf("sendWithRetry: %v", err)
+	}
+
+	got := api.requests()
+	if len(got) != 3 {
+		t.Fatalf("%d requests, want 3", len(got))
+	}
+	for i, want := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond} {
+		if wait := got[i+1].at.Sub(got[i].at); wait < want {
+			t.Errorf("retry %d after %s, want at least %s", i+1, wait, want)
+		}
+	}
+}
+
+func TestSendWithRetryGivesUpAfterMaxRetries(t *testing.T) {
+	withRetryBackoff(t, time.Millisecond)
+	unavailable := fakeReply{http.StatusServiceUnavailable, `{"ok":false,"error_code":503,"description":"Service Unavailable"}`}
+	api := newFakeBotAPI(t, unavailable, unavailable, unavailable, unavailable)
+	n := newTestNotifier(t, api, 0, 3)
+
+	if err := n.sendWithRetry("hello"); err == nil {
+		t.Fatal("sendWithRetry succeeded, want the last server error")
+	}
+	if got := len(api.requests()); got != 3 {
+		t.Errorf("%d requests, want 3", got)
+	}
+}
+
+func TestSendWithRetryDoesNotRetryPermanentErrors(t *testing.T) {
+	withRetryBackoff(t, time.Millisecond)
+	tests := []struct {
+		name  string
+		reply fakeReply
+	}{
+		{"bot blocked", fakeReply{http.StatusForbidden, `{"ok":false,"error_code":403,"description":"Forbidden: bot was kicked from the supergroup chat"}`}},
+		{"chat not found", fakeReply{http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`}},
+		{"bad token", fakeReply{http.StatusUnauthorized, `{"ok":false,"error_code":401,"description":"Unauthorized"}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeBotAPI(t, tt.reply)
+			n := newTestNotifier(t, api, 0, 3)
+
+			err := n.sendWithRetry("hello")
+			if !errors.Is(err, errPermanent) {
+				t.Errorf("error = %v, want a permanent failure", err)
+			}
+			if got := len(api.requests()); got != 1 {
+				t.Errorf("%d requests, want 1", got)
+			}
+		})
+	}
+}
+
+func TestNotifierNamesOrderPlaces(t *testing.T) {
+	api := newFakeBotAPI(t)
+	n := newTestNotifier(t, api, 0, 1)
+
+	n.NewOrder(testOrder(), models.LangUzLatin)
+	if err := n.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := api.requests()
+	if len(got) != 1 {
+		t.Fatalf("%d messages sent, want 1", len(got))
+	}
+	if want := "Yo'nalish: Amir Temur 1 → Samarqand, Urgut"; !strings.Contains(got[0].text, want) {
+		t.Errorf("message %q does not contain %q", got[0].text, want)
+	}
+}
+
+func TestNotifierSpacesMessagesByMinInterval(t *testing.T) {
+	api := newFakeBotAPI(t)
+	n := newTestNotifier(t, api, 100, 1)
+
+	order := &models.Order{ID: 1, OrderType: models.OrderTypeTaxi}
+	for i := 0; i < 3; i++ {
+		n.NewOrder(order, models.LangUzLatin)
+	}
+	if err := n.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := api.requests()
+	if len(got) != 3 {
+		t.Fatalf("%d messages sent, want 3", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if gap := got[i].at.Sub(got[i-1].at); gap < 100*time.Millisecond {
+			t.Errorf("message %d sent %s after the previous one, want at least 100ms", i+1, gap)
+		}
+	}
+}
+
+func TestNotifierCloseDrainsQueue(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.delay = 20 * time.Millisecond
+	n := newTestNotifier(t, api, 0, 1)
+
+	for i := int64(1); i <= 5; i++ {
+		n.NewOrder(&models.Order{ID: i, OrderType: models.OrderTypeDelivery}, models.LangRussian)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := len(api.requests()); got != 5 {
+		t.Errorf("%d messages sent before Close returned, want 5", got)
+	}
+
+	// Alerts raised after Close are dropped rather than sent on the closed queue
+	n.NewOrder(&models.Order{ID: 6}, models.LangRussian)
+	if got := len(api.requests()); got != 5 {
+		t.Errorf("%d messages sent after Close, want 5", got)
+	}
+}
+
+func TestNotifierCloseGivesUpAtDeadline(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.delay = 200 * time.Millisecond
+	n := newTestNotifier(t, api, 0, 1)
+
+	for i := int64(1); i <= 3; i++ {
+		n.NewOrder(&models.Order{ID: i}, models.LangUzLatin)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := n.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close error = %v, want the deadline", err)
+	}
+}
+
+func TestDisabledNotifierDropsAlerts(t *testing.T) {
+	n := NewNotifier(&config.TelegramConfig{}, nil)
+	if n.Enabled() {
+		t.Fatal("notifier without a token is enabled")
+	}
+	n.NewOrder(&models.Order{ID: 1}, models.LangUzLatin)
+	if err := n.Close(context.Background()); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
@@ -26,26 +26,44 @@ func GenerateToken(userID int64, role models.UserRole, secret string, expiration
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return SignClaims(claims, secret)
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+	claims := &Claims{}
+	if err := ParseClaims(tokenString, secret, claims); err != nil {
+		return nil, err
+	}
+	// Tokens meant for something else, such as price quotes, carry an audience
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// SignClaims signs claims as an HS256 JWT
+func SignClaims(claims jwt.Claims, secret string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// ParseClaims validates an HMAC-signed JWT and decodes it into claims;
+// options add checks such as the expected audience
+func ParseClaims(tokenString, secret string, claims jwt.Claims, options ...jwt.ParserOption) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
 		}
 		return []byte(secret), nil
-	})
+	}, options...)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	if !token.Valid {
+		return errors.New("invalid token")
 	}
-
-	return nil, errors.New("invalid token")
+	return nil
 }